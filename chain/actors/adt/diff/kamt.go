@@ -0,0 +1,241 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	typegen "github.com/whyrusleeping/cbor-gen"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/go-hamt-ipld/v3"
+
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+)
+
+// KamtChange is a change to an entry of a KAMT, such as the storage of an EVM actor. Before is nil for added entries
+// and After is nil for removed entries.
+type KamtChange struct {
+	Type   hamt.ChangeType
+	Key    []byte
+	Before []byte
+	After  []byte
+}
+
+// Kamt returns a set of changes that transform the KAMT at `preRoot` into the KAMT at `curRoot`. Keys and values are
+// the byte strings they are encoded as, which holds for the U256 keys and values of EVM actor storage. An undefined
+// root is treated as an empty KAMT.
+//
+// There is no go implementation of the KAMT, nodes are walked without following the paths of the keys they hold:
+// subtrees shared by both KAMTs are skipped and the entries of the remaining nodes are compared by key. Changes are
+// ordered by key.
+func Kamt(ctx context.Context, preStore, curStore adt.Store, preRoot, curRoot cid.Cid) ([]*KamtChange, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "Kamt.Diff")
+	defer span.End()
+
+	pre := map[string][]byte{}
+	cur := map[string][]byte{}
+	var preLinks, curLinks []cid.Cid
+	if preRoot.Defined() {
+		preLinks = append(preLinks, preRoot)
+	}
+	if curRoot.Defined() {
+		curLinks = append(curLinks, curRoot)
+	}
+	for len(preLinks) > 0 || len(curLinks) > 0 {
+		preLinks, curLinks = withoutShared(preLinks, curLinks)
+
+		var err error
+		if preLinks, err = loadKamtNodes(ctx, preStore, preLinks, pre); err != nil {
+			return nil, fmt.Errorf("loading pre kamt: %w", err)
+		}
+		if curLinks, err = loadKamtNodes(ctx, curStore, curLinks, cur); err != nil {
+			return nil, fmt.Errorf("loading cur kamt: %w", err)
+		}
+	}
+
+	var changes []*KamtChange
+	for k, before := range pre {
+		after, ok := cur[k]
+		switch {
+		case !ok:
+			changes = append(changes, &KamtChange{Type: hamt.Remove, Key: []byte(k), Before: before})
+		case !bytes.Equal(before, after):
+			changes = append(changes, &KamtChange{Type: hamt.Modify, Key: []byte(k), Before: before, After: after})
+		}
+	}
+	for k, after := range cur {
+		if _, ok := pre[k]; !ok {
+			changes = append(changes, &KamtChange{Type: hamt.Add, Key: []byte(k), After: after})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].Key, changes[j].Key) < 0
+	})
+	return changes, nil
+}
+
+// withoutShared removes the links found in both a and b, the subtrees they point to hold the same entries.
+func withoutShared(a, b []cid.Cid) ([]cid.Cid, []cid.Cid) {
+	inA := make(map[cid.Cid]bool, len(a))
+	for _, c := range a {
+		inA[c] = true
+	}
+	shared := map[cid.Cid]bool{}
+	outB := b[:0]
+	for _, c := range b {
+		if inA[c] {
+			shared[c] = true
+			continue
+		}
+		outB = append(outB, c)
+	}
+	outA := a[:0]
+	for _, c := range a {
+		if !shared[c] {
+			outA = append(outA, c)
+		}
+	}
+	return outA, outB
+}
+
+// loadKamtNodes adds the entries of the nodes at links to entries and returns the links of their children.
+func loadKamtNodes(ctx context.Context, store adt.Store, links []cid.Cid, entries map[string][]byte) ([]cid.Cid, error) {
+	var next []cid.Cid
+	for _, c := range links {
+		var node kamtNode
+		if err := store.Get(ctx, c, &node); err != nil {
+			return nil, fmt.Errorf("loading node %s: %w", c, err)
+		}
+		for _, e := range node.entries {
+			entries[string(e.key)] = e.value
+		}
+		next = append(next, node.links...)
+	}
+	return next, nil
+}
+
+type kamtEntry struct {
+	key   []byte
+	value []byte
+}
+
+// kamtNode is a node of a KAMT, encoded as a tuple of a bitfield and pointers. A pointer is either a link to a child
+// node, optionally encoded in an array alongside the key path extension of the child, or an array of key value pairs.
+type kamtNode struct {
+	links   []cid.Cid
+	entries []kamtEntry
+}
+
+func (n *kamtNode) UnmarshalCBOR(r io.Reader) error {
+	cr := typegen.NewCborReader(r)
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != typegen.MajArray || extra != 2 {
+		return fmt.Errorf("kamt node: expected tuple of 2 fields, got major type %d of length %d", maj, extra)
+	}
+
+	// the bitfield is only needed to follow the path of a key.
+	var bitfield typegen.Deferred
+	if err := bitfield.UnmarshalCBOR(cr); err != nil {
+		return fmt.Errorf("kamt node bitfield: %w", err)
+	}
+
+	maj, extra, err = cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != typegen.MajArray {
+		return fmt.Errorf("kamt node pointers: expected array, got major type %d", maj)
+	}
+	for i := uint64(0); i < extra; i++ {
+		var ptr typegen.Deferred
+		if err := ptr.UnmarshalCBOR(cr); err != nil {
+			return fmt.Errorf("kamt pointer: %w", err)
+		}
+		if err := n.addPointer(ptr.Raw); err != nil {
+			return fmt.Errorf("kamt pointer %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (n *kamtNode) addPointer(raw []byte) error {
+	if isCid(raw) {
+		c, err := typegen.ReadCid(bytes.NewReader(raw))
+		if err != nil {
+			return err
+		}
+		n.links = append(n.links, c)
+		return nil
+	}
+
+	items, err := readArray(raw)
+	if err != nil {
+		return err
+	}
+	// a link with an extension.
+	for _, item := range items {
+		if isCid(item) {
+			c, err := typegen.ReadCid(bytes.NewReader(item))
+			if err != nil {
+				return err
+			}
+			n.links = append(n.links, c)
+			return nil
+		}
+	}
+	for _, item := range items {
+		kv, err := readArray(item)
+		if err != nil {
+			return err
+		}
+		if len(kv) != 2 {
+			return fmt.Errorf("expected key value pair, got %d items", len(kv))
+		}
+		key, err := readBytes(kv[0])
+		if err != nil {
+			return fmt.Errorf("key: %w", err)
+		}
+		value, err := readBytes(kv[1])
+		if err != nil {
+			return fmt.Errorf("value: %w", err)
+		}
+		n.entries = append(n.entries, kamtEntry{key: key, value: value})
+	}
+	return nil
+}
+
+// isCid reports whether raw is a CBOR tag 42, which links are encoded as.
+func isCid(raw []byte) bool {
+	return len(raw) >= 2 && raw[0] == 0xd8 && raw[1] == 42
+}
+
+func readArray(raw []byte) ([][]byte, error) {
+	cr := typegen.NewCborReader(bytes.NewReader(raw))
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if maj != typegen.MajArray {
+		return nil, fmt.Errorf("expected array, got major type %d", maj)
+	}
+	items := make([][]byte, 0, extra)
+	for i := uint64(0); i < extra; i++ {
+		var item typegen.Deferred
+		if err := item.UnmarshalCBOR(cr); err != nil {
+			return nil, err
+		}
+		items = append(items, item.Raw)
+	}
+	return items, nil
+}
+
+func readBytes(raw []byte) ([]byte, error) {
+	return typegen.ReadByteArray(bytes.NewReader(raw), uint64(len(raw)))
+}
@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	typegen "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-hamt-ipld/v3"
+	"github.com/filecoin-project/lily/chain/actors/adt"
+)
+
+// putKamtNode stores a KAMT node holding the pointers, each either a cid.Cid, a cid.Cid and an extension or a map
+// of key value pairs.
+func putKamtNode(t *testing.T, store adt.Store, pointers ...interface{}) cid.Cid {
+	var buf bytes.Buffer
+	cw := typegen.NewCborWriter(&buf)
+	require.NoError(t, cw.WriteMajorTypeHeader(typegen.MajArray, 2))
+	require.NoError(t, typegen.WriteByteArray(cw, []byte{0xff}))
+	require.NoError(t, cw.WriteMajorTypeHeader(typegen.MajArray, uint64(len(pointers))))
+	for _, p := range pointers {
+		switch p := p.(type) {
+		case cid.Cid:
+			require.NoError(t, typegen.WriteCid(cw, p))
+		case []interface{}:
+			require.NoError(t, cw.WriteMajorTypeHeader(typegen.MajArray, 2))
+			require.NoError(t, typegen.WriteCid(cw, p[0].(cid.Cid)))
+			require.NoError(t, typegen.WriteByteArray(cw, p[1].([]byte)))
+		case [][2]string:
+			require.NoError(t, cw.WriteMajorTypeHeader(typegen.MajArray, uint64(len(p))))
+			for _, kv := range p {
+				require.NoError(t, cw.WriteMajorTypeHeader(typegen.MajArray, 2))
+				require.NoError(t, typegen.WriteByteArray(cw, []byte(kv[0])))
+				require.NoError(t, typegen.WriteByteArray(cw, []byte(kv[1])))
+			}
+		}
+	}
+
+	c, err := store.Put(context.Background(), &typegen.Deferred{Raw: buf.Bytes()})
+	require.NoError(t, err)
+	return c
+}
+
+func TestDiffKamt(t *testing.T) {
+	ctx := context.Background()
+	store := newContextStore()
+
+	shared := putKamtNode(t, store, [][2]string{{"s1", "v"}, {"s2", "v"}})
+	pre := putKamtNode(t, store,
+		[][2]string{{"a", "1"}, {"b", "1"}},
+		shared,
+		[]interface{}{putKamtNode(t, store, [][2]string{{"c", "1"}, {"d", "1"}}), []byte{1}},
+	)
+	cur := putKamtNode(t, store,
+		[][2]string{{"a", "2"}},
+		shared,
+		putKamtNode(t, store, [][2]string{{"c", "1"}}, putKamtNode(t, store, [][2]string{{"e", "1"}})),
+	)
+
+	changes, err := Kamt(ctx, store, store, pre, cur)
+	require.NoError(t, err)
+	require.Equal(t, []*KamtChange{
+		{Type: hamt.Modify, Key: []byte("a"), Before: []byte("1"), After: []byte("2")},
+		{Type: hamt.Remove, Key: []byte("b"), Before: []byte("1")},
+		{Type: hamt.Remove, Key: []byte("d"), Before: []byte("1")},
+		{Type: hamt.Add, Key: []byte("e"), After: []byte("1")},
+	}, changes)
+
+	changes, err = Kamt(ctx, store, store, cur, cur)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = Kamt(ctx, store, store, cid.Undef, shared)
+	require.NoError(t, err)
+	require.Equal(t, []*KamtChange{
+		{Type: hamt.Add, Key: []byte("s1"), After: []byte("v")},
+		{Type: hamt.Add, Key: []byte("s2"), After: []byte("v")},
+	}, changes)
+}
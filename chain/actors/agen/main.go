@@ -21,8 +21,8 @@ var actors = map[string][]int{
 	"market":   lotusactors.Versions,
 	"miner":    lotusactors.Versions,
 	"multisig": lotusactors.Versions,
-	// "paych":    lotusactors.Versions,
-	"power": lotusactors.Versions,
+	"paych":    lotusactors.Versions,
+	"power":    lotusactors.Versions,
 	// "system":   lotusactors.Versions,
 	"reward":   lotusactors.Versions,
 	"verifreg": lotusactors.Versions,
@@ -190,6 +190,10 @@ func (t *DataSource) StateCompute(ctx context.Context, height abi.ChainEpoch, ms
 	return t.node.StateCompute(ctx, height, msgs, tsk)
 }
 
+func (t *DataSource) StateCall(ctx context.Context, msg *types.Message, tsk types.TipSetKey) (*api.InvocResult, error) {
+	return t.node.StateCall(ctx, msg, tsk)
+}
+
 // TipSetMessageReceipts returns the blocks and messages in `pts` and their corresponding receipts from `ts` matching block order in tipset (`pts`).
 // TODO replace with lotus chainstore method when https://github.com/filecoin-project/lotus/pull/9186 lands
 func (t *DataSource) TipSetMessageReceipts(ctx context.Context, ts, pts *types.TipSet) ([]*lens.BlockMessageReceipts, error) {
@@ -10,8 +10,10 @@ import (
 	"github.com/filecoin-project/lily/chain/datasource"
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/integrated"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
 	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/visor"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
 
@@ -28,10 +30,31 @@ type Filler struct {
 	tasks                []string
 	done                 chan struct{}
 	report               *schedule.Reporter
+	resume               bool // when true, skip heights at or below the last checkpoint
+	retry                processor.RetryPolicies
+	taskConfig           processor.TaskConfig
 }
 
-func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxHeight int64, tasks []string, r *schedule.Reporter) *Filler {
-	return &Filler{
+type FillerOpt func(g *Filler)
+
+// WithRetryPolicies sets the policies used to retry tasks that fail while filling a gap.
+func WithRetryPolicies(r processor.RetryPolicies) FillerOpt {
+	return func(g *Filler) {
+		g.retry = r
+	}
+}
+
+// WithTaskConfig sets the configuration of the tasks run while filling a gap.
+func WithTaskConfig(c processor.TaskConfig) FillerOpt {
+	return func(g *Filler) {
+		g.taskConfig = c
+	}
+}
+
+// NewFiller creates a job that fills gaps found between minHeight and maxHeight. When resume is true heights at or
+// below the job's last checkpoint are skipped.
+func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxHeight int64, tasks []string, r *schedule.Reporter, resume bool, opts ...FillerOpt) *Filler {
+	g := &Filler{
 		DB:        db,
 		node:      node,
 		name:      name,
@@ -39,7 +62,12 @@ func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxH
 		minHeight: minHeight,
 		tasks:     tasks,
 		report:    r,
+		resume:    resume,
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 func (g *Filler) Run(ctx context.Context) error {
@@ -47,7 +75,19 @@ func (g *Filler) Run(ctx context.Context) error {
 	g.done = make(chan struct{})
 	defer close(g.done)
 
-	gaps, heights, err := g.DB.ConsolidateGaps(ctx, g.minHeight, g.maxHeight, g.tasks...)
+	minHeight := g.minHeight
+	if g.resume {
+		cp, err := g.DB.LoadCheckpoint(ctx, g.name)
+		if err != nil {
+			return err
+		}
+		if cp != nil && cp.Height >= minHeight {
+			log.Infow("resuming gap fill from checkpoint", "checkpoint_height", cp.Height, "reporter", g.name)
+			minHeight = cp.Height + 1
+		}
+	}
+
+	gaps, heights, err := g.DB.ConsolidateGaps(ctx, minHeight, g.maxHeight, g.tasks...)
 	if err != nil {
 		return err
 	}
@@ -59,11 +99,29 @@ func (g *Filler) Run(ctx context.Context) error {
 		return err
 	}
 
-	index, err := integrated.NewManager(g.DB, tipset.NewBuilder(taskAPI, g.name))
+	index, err := integrated.NewManager(g.DB, tipset.NewBuilder(taskAPI, g.name, tipset.WithRetryPolicies(g.retry), tipset.WithTaskConfig(g.taskConfig)))
 	if err != nil {
 		return err
 	}
 
+	if err := g.fill(ctx, index, g.DB, gaps, heights); err != nil {
+		return err
+	}
+	log.Infow("gap fill complete", "duration", time.Since(fillStart), "total_epoch_gaps", len(gaps), "from", g.minHeight, "to", g.maxHeight, "task", g.tasks, "reporter", g.name)
+
+	return nil
+}
+
+// fillStore records the gaps filled and the progress of a fill.
+type fillStore interface {
+	SetGapsFilled(ctx context.Context, height int64, tasks ...string) error
+	SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error
+}
+
+// fill indexes the tasks of gaps at each of heights, in ascending order. The checkpoint only advances past heights that
+// were filled, once a height fails to fill a resumed fill must start again from it.
+func (g *Filler) fill(ctx context.Context, index indexer.Indexer, store fillStore, gaps map[int64][]string, heights []int64) error {
+	checkpoint := true
 	for _, height := range heights {
 		select {
 		case <-ctx.Done():
@@ -85,16 +143,27 @@ func (g *Filler) Run(ctx context.Context) error {
 			return err
 		} else if !success {
 			log.Errorw("fill indexing failed to successfully index tipset, skipping fill for tipset, gap remains", "height", height, "tipset", ts.Key().String(), "tasks", gaps[height], "reporter", g.name)
+			checkpoint = false
 			continue
 		}
 		log.Infow("fill success", "epoch", ts.Height(), "tasks_filled", gaps[height], "duration", time.Since(runStart), "reporter", g.name)
 
-		if err := g.DB.SetGapsFilled(ctx, height, gaps[height]...); err != nil {
+		if err := store.SetGapsFilled(ctx, height, gaps[height]...); err != nil {
 			return err
 		}
-	}
-	log.Infow("gap fill complete", "duration", time.Since(fillStart), "total_epoch_gaps", len(gaps), "from", g.minHeight, "to", g.maxHeight, "task", g.tasks, "reporter", g.name)
 
+		if !checkpoint {
+			continue
+		}
+		if err := store.SaveCheckpoint(ctx, &visor.JobCheckpoint{
+			JobName:   g.name,
+			JobType:   "fill",
+			Height:    height,
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			log.Errorw("failed to save gap fill checkpoint", "error", err, "height", height, "reporter", g.name)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,65 @@
+package gap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/testutil"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// tipsetSource serves a fake tipset at any height.
+type tipsetSource struct {
+	lens.API
+	t *testing.T
+}
+
+func (s *tipsetSource) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	return testutil.MustFakeTipSet(s.t, int64(h)), nil
+}
+
+// fillIndexer only partially indexes the heights in incomplete.
+type fillIndexer struct {
+	incomplete map[int64]bool
+}
+
+func (f *fillIndexer) TipSet(_ context.Context, ts *types.TipSet, _ ...indexer.Option) (bool, error) {
+	return !f.incomplete[int64(ts.Height())], nil
+}
+
+// memFillStore records the gaps filled and the checkpoints saved in memory.
+type memFillStore struct {
+	filled      []int64
+	checkpoints []int64
+}
+
+func (m *memFillStore) SetGapsFilled(_ context.Context, height int64, _ ...string) error {
+	m.filled = append(m.filled, height)
+	return nil
+}
+
+func (m *memFillStore) SaveCheckpoint(_ context.Context, cp *visor.JobCheckpoint) error {
+	m.checkpoints = append(m.checkpoints, cp.Height)
+	return nil
+}
+
+func TestFillCheckpoint(t *testing.T) {
+	g := NewFiller(&tipsetSource{t: t}, nil, t.Name(), 0, 10, []string{"blocks"}, &schedule.Reporter{}, true)
+	store := &memFillStore{}
+	gaps := map[int64][]string{2: {"blocks"}, 4: {"blocks"}, 6: {"blocks"}, 8: {"blocks"}}
+
+	require.NoError(t, g.fill(context.Background(), &fillIndexer{incomplete: map[int64]bool{6: true}}, store, gaps, []int64{2, 4, 6, 8}))
+
+	// every height that was indexed is marked filled
+	require.Equal(t, []int64{2, 4, 8}, store.filled)
+	// the checkpoint stops below the height that failed so a resumed fill retries it
+	require.Equal(t, []int64{2, 4}, store.checkpoints)
+}
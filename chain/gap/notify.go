@@ -6,7 +6,6 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
-	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/lens"
 	"github.com/filecoin-project/lily/storage"
 
@@ -15,7 +14,7 @@ import (
 
 type Notifier struct {
 	DB                   *storage.Database
-	queue                *queue.AsynQ
+	queue                distributed.Queue
 	node                 lens.API
 	name                 string
 	minHeight, maxHeight int64
@@ -23,7 +22,7 @@ type Notifier struct {
 	done                 chan struct{}
 }
 
-func NewNotifier(node lens.API, db *storage.Database, queue *queue.AsynQ, name string, minHeight, maxHeight int64, tasks []string) *Notifier {
+func NewNotifier(node lens.API, db *storage.Database, queue distributed.Queue, name string, minHeight, maxHeight int64, tasks []string) *Notifier {
 	return &Notifier{
 		DB:        db,
 		queue:     queue,
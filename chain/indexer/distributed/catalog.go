@@ -16,8 +16,11 @@ var log = logging.Logger("lily/distributed")
 // config.QueueConfig contains a duplicate queue name.
 func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 	c := &Catalog{
-		servers: map[string]*TipSetWorker{},
-		clients: map[string]*asynq.Client{},
+		servers:  map[string]*TipSetWorker{},
+		clients:  map[string]*asynq.Client{},
+		redis:    map[string]asynq.RedisClientOpt{},
+		postgres: map[string]config.PgQueueConfig{},
+		maxRetry: cfg.MaxRetry,
 	}
 
 	for name, sc := range cfg.Workers {
@@ -47,15 +50,17 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 			redisPassword = sc.RedisConfig.Password
 		}
 
+		redisOpt := asynq.RedisClientOpt{
+			Network:  sc.RedisConfig.Network,
+			Addr:     redisAddr,
+			Username: redisUser,
+			Password: redisPassword,
+			DB:       sc.RedisConfig.DB,
+			PoolSize: sc.RedisConfig.PoolSize,
+		}
+		c.redis[name] = redisOpt
 		c.servers[name] = &TipSetWorker{
-			RedisConfig: asynq.RedisClientOpt{
-				Network:  sc.RedisConfig.Network,
-				Addr:     redisAddr,
-				Username: redisUser,
-				Password: redisPassword,
-				DB:       sc.RedisConfig.DB,
-				PoolSize: sc.RedisConfig.PoolSize,
-			},
+			RedisConfig: redisOpt,
 			ServerConfig: asynq.Config{
 				LogLevel:        sc.WorkerConfig.LogLevel(),
 				Queues:          sc.WorkerConfig.Queues(),
@@ -93,16 +98,27 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 			redisPassword = cc.Password
 		}
 
-		c.clients[name] = asynq.NewClient(
-			asynq.RedisClientOpt{
-				Network:  cc.Network,
-				Addr:     redisAddr,
-				Username: redisUser,
-				Password: redisPassword,
-				DB:       cc.DB,
-				PoolSize: cc.PoolSize,
-			},
-		)
+		redisOpt := asynq.RedisClientOpt{
+			Network:  cc.Network,
+			Addr:     redisAddr,
+			Username: redisUser,
+			Password: redisPassword,
+			DB:       cc.DB,
+			PoolSize: cc.PoolSize,
+		}
+		c.redis[name] = redisOpt
+		c.clients[name] = asynq.NewClient(redisOpt)
+	}
+
+	for name, pc := range cfg.Postgresql {
+		if _, exists := c.redis[name]; exists {
+			return nil, fmt.Errorf("duplicate queue name: %q", name)
+		}
+		if pc.Storage == "" {
+			return nil, fmt.Errorf("postgresql queue %q: storage name required", name)
+		}
+		log.Infow("registering queue config", "name", name, "type", "postgresql", "storage", pc.Storage)
+		c.postgres[name] = pc
 	}
 	return c, nil
 }
@@ -115,8 +131,11 @@ type TipSetWorker struct {
 // Catalog contains a map of workers and clients
 // Catalog is used to configure the distributed indexer.
 type Catalog struct {
-	servers map[string]*TipSetWorker
-	clients map[string]*asynq.Client
+	servers  map[string]*TipSetWorker
+	clients  map[string]*asynq.Client
+	redis    map[string]asynq.RedisClientOpt
+	postgres map[string]config.PgQueueConfig
+	maxRetry config.MaxRetryConfig
 }
 
 // Worker returns a runnable *asynq.Server by `name`. An error is returned if name is empty or if a
@@ -146,3 +165,43 @@ func (c *Catalog) Notifier(name string) (*asynq.Client, error) {
 	}
 	return client, nil
 }
+
+// Inspector returns an *asynq.Inspector connected to the redis server of the worker or notifier `name`. An error is
+// returned if name is empty or if no worker or notifier exists for `name`. The caller is responsible for closing the
+// returned inspector.
+func (c *Catalog) Inspector(name string) (*asynq.Inspector, error) {
+	if name == "" {
+		return nil, fmt.Errorf("queue config name required")
+	}
+
+	opt, exists := c.redis[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown queue: %q", name)
+	}
+	return asynq.NewInspector(opt), nil
+}
+
+// Postgres returns the config of the postgres backed queue `name` and true, or false if `name` is not a postgres queue.
+// A postgres queue is used both to enqueue tipsets and to process them.
+func (c *Catalog) Postgres(name string) (config.PgQueueConfig, bool) {
+	pc, exists := c.postgres[name]
+	return pc, exists
+}
+
+// WorkerQueues returns the priority of each queue a worker configured by `name` consumes from. An error is returned if
+// name is empty or if no worker or postgres queue exists for `name`.
+func (c *Catalog) WorkerQueues(name string) (map[string]int, error) {
+	if pc, exists := c.Postgres(name); exists {
+		return pc.WorkerConfig.Queues(), nil
+	}
+	server, err := c.Worker(name)
+	if err != nil {
+		return nil, err
+	}
+	return server.ServerConfig.Queues, nil
+}
+
+// MaxRetry returns the number of times a task enqueued on `queue` is retried before it is archived.
+func (c *Catalog) MaxRetry(queue string) int {
+	return c.maxRetry.For(queue)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
 	"github.com/filecoin-project/lily/chain/indexer/distributed/queue/tasks"
+	"github.com/filecoin-project/lily/config"
 
 	"github.com/filecoin-project/lotus/chain/types"
 )
@@ -17,36 +18,34 @@ import (
 var _ distributed.Queue = (*AsynQ)(nil)
 
 type AsynQ struct {
-	c *asynq.Client
+	c        *asynq.Client
+	maxRetry func(queue string) int
 }
 
-func NewAsynq(client *asynq.Client) *AsynQ {
-	return &AsynQ{c: client}
+// NewAsynq returns a queue that enqueues tasks with client. maxRetry returns the number of times a task enqueued on a
+// queue is retried before it is archived, config.DefaultQueueMaxRetry is used when maxRetry is nil.
+func NewAsynq(client *asynq.Client, maxRetry func(queue string) int) *AsynQ {
+	if maxRetry == nil {
+		maxRetry = func(string) int { return config.DefaultQueueMaxRetry }
+	}
+	return &AsynQ{c: client, maxRetry: maxRetry}
 }
 
 func (r *AsynQ) EnqueueTipSet(ctx context.Context, ts *types.TipSet, indexType indexer.IndexerType, taskNames ...string) error {
 	ctx, span := otel.Tracer("").Start(ctx, "AsnyQ.EnqueueTipSet")
 	defer span.End()
 
-	var task *asynq.Task
-	var err error
-	if indexType == indexer.Fill {
-		task, err = tasks.NewGapFillTipSetTask(ctx, ts, taskNames)
-		if err != nil {
-			return err
-		}
-	} else {
-		task, err = tasks.NewIndexTipSetTask(ctx, ts, taskNames)
-		if err != nil {
-			return err
-		}
+	task, err := newTipSetTask(ctx, ts, indexType, taskNames)
+	if err != nil {
+		return err
 	}
 
 	if span.IsRecording() {
 		span.SetAttributes(attribute.String("task_type", task.Type()), attribute.StringSlice("tasks", taskNames), attribute.String("index_type", indexType.String()))
 	}
 
-	_, err = r.c.EnqueueContext(ctx, task, asynq.Queue(indexType.String()), asynq.MaxRetry(3))
+	queue := indexType.String()
+	_, err = r.c.EnqueueContext(ctx, task, asynq.Queue(queue), asynq.MaxRetry(r.maxRetry(queue)))
 	if err != nil {
 		return err
 	}
@@ -54,3 +53,11 @@ func (r *AsynQ) EnqueueTipSet(ctx context.Context, ts *types.TipSet, indexType i
 	return nil
 
 }
+
+// newTipSetTask returns a gap fill task for tipsets enqueued by a fill job and an index task otherwise.
+func newTipSetTask(ctx context.Context, ts *types.TipSet, indexType indexer.IndexerType, taskNames []string) (*asynq.Task, error) {
+	if indexType == indexer.Fill {
+		return tasks.NewGapFillTipSetTask(ctx, ts, taskNames)
+	}
+	return tasks.NewIndexTipSetTask(ctx, ts, taskNames)
+}
@@ -0,0 +1,200 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// Queues are the names of the queues tipsets are enqueued on, one per indexer type.
+var Queues = []string{
+	indexer.Watch.String(),
+	indexer.Walk.String(),
+	indexer.Index.String(),
+	indexer.Fill.String(),
+}
+
+// listPageSize is the number of archived tasks fetched from redis per request.
+const listPageSize = 100
+
+// FailedTask is an index or gap fill task that exhausted its retries and was archived by the queue.
+type FailedTask struct {
+	ID           string
+	Queue        string
+	Type         string
+	Height       int64
+	TipSet       string
+	Tasks        []string
+	Retried      int
+	MaxRetry     int
+	LastError    string
+	LastFailedAt time.Time
+}
+
+// FailedTasks lists, retries and purges tipset tasks that exhausted their retries and were archived by a queue.
+type FailedTasks interface {
+	// List returns the archived index and gap fill tasks of the given queues, or of all queues if none are given.
+	List(ctx context.Context, queues ...string) ([]*FailedTask, error)
+	// Retry returns archived tasks to their queue so they are run again by a worker. If ids is empty all archived tasks
+	// of the given queues are retried. The number of tasks retried is returned.
+	Retry(ctx context.Context, queues []string, ids []string) (int, error)
+	// Purge deletes archived tasks. If ids is empty all archived tasks of the given queues are deleted. The number of
+	// tasks deleted is returned.
+	Purge(ctx context.Context, queues []string, ids []string) (int, error)
+}
+
+var _ FailedTasks = (*FailedTaskInspector)(nil)
+
+// FailedTaskInspector lists, retries and purges archived tasks of an asynq queue.
+type FailedTaskInspector struct {
+	i *asynq.Inspector
+}
+
+func NewFailedTaskInspector(i *asynq.Inspector) *FailedTaskInspector {
+	return &FailedTaskInspector{i: i}
+}
+
+func (f *FailedTaskInspector) List(_ context.Context, queues ...string) ([]*FailedTask, error) {
+	queues, err := f.existingQueues(queues)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*FailedTask
+	for _, q := range queues {
+		for page := 1; ; page++ {
+			infos, err := f.i.ListArchivedTasks(q, asynq.Page(page), asynq.PageSize(listPageSize))
+			if err != nil {
+				return nil, fmt.Errorf("list archived tasks of queue %q: %w", q, err)
+			}
+			for _, info := range infos {
+				ft, err := decodeFailedTask(info.ID, info.Queue, info.Type, info.Payload)
+				if err != nil {
+					log.Warnw("skipping archived task", "queue", q, "id", info.ID, "error", err)
+					continue
+				}
+				if ft == nil {
+					continue
+				}
+				ft.Retried = info.Retried
+				ft.MaxRetry = info.MaxRetry
+				ft.LastError = info.LastErr
+				ft.LastFailedAt = info.LastFailedAt
+				out = append(out, ft)
+			}
+			if len(infos) < listPageSize {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *FailedTaskInspector) Retry(ctx context.Context, queues []string, ids []string) (int, error) {
+	return f.apply(ctx, queues, ids, f.i.RunTask)
+}
+
+func (f *FailedTaskInspector) Purge(ctx context.Context, queues []string, ids []string) (int, error) {
+	return f.apply(ctx, queues, ids, f.i.DeleteTask)
+}
+
+func (f *FailedTaskInspector) apply(ctx context.Context, queues []string, ids []string, fn func(queue, id string) error) (int, error) {
+	failed, err := f.List(ctx, queues...)
+	if err != nil {
+		return 0, err
+	}
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	count := 0
+	for _, ft := range failed {
+		if len(want) > 0 {
+			if !want[ft.ID] {
+				continue
+			}
+			delete(want, ft.ID)
+		}
+		if err := fn(ft.Queue, ft.ID); err != nil {
+			return count, fmt.Errorf("task %s in queue %q: %w", ft.ID, ft.Queue, err)
+		}
+		count++
+	}
+
+	if len(want) > 0 {
+		missing := make([]string, 0, len(want))
+		for id := range want {
+			missing = append(missing, id)
+		}
+		sort.Strings(missing)
+		return count, fmt.Errorf("archived tasks not found: %s", strings.Join(missing, ", "))
+	}
+	return count, nil
+}
+
+// existingQueues returns the queues that exist in redis, asynq returns an error when listing the tasks of a queue that
+// has never had a task enqueued.
+func (f *FailedTaskInspector) existingQueues(queues []string) ([]string, error) {
+	if len(queues) == 0 {
+		queues = Queues
+	}
+	all, err := f.i.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("list queues: %w", err)
+	}
+	exists := make(map[string]bool, len(all))
+	for _, q := range all {
+		exists[q] = true
+	}
+
+	var out []string
+	for _, q := range queues {
+		if exists[q] {
+			out = append(out, q)
+		}
+	}
+	return out, nil
+}
+
+// tipSetPayload holds the fields common to tasks.IndexTipSetPayload and tasks.GapFillTipSetPayload.
+type tipSetPayload struct {
+	TipSet *types.TipSet
+	Tasks  []string
+}
+
+// decodeFailedTask returns a FailedTask for an archived task, or nil if the task is not a tipset task.
+func decodeFailedTask(id, queue, taskType string, payload []byte) (*FailedTask, error) {
+	if taskType != tasks.TypeIndexTipSet && taskType != tasks.TypeGapFillTipSet {
+		return nil, nil
+	}
+
+	var p tipSetPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("decode %s payload: %w", taskType, err)
+	}
+	if p.TipSet == nil {
+		return nil, errors.New("payload missing tipset")
+	}
+
+	return &FailedTask{
+		ID:     id,
+		Queue:  queue,
+		Type:   taskType,
+		Height: int64(p.TipSet.Height()),
+		TipSet: p.TipSet.Key().String(),
+		Tasks:  p.Tasks,
+	}, nil
+}
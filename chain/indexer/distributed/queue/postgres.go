@@ -0,0 +1,433 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/storage"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const (
+	// DefaultPollInterval is how long an idle PgWorker waits before checking the queue for new tasks.
+	DefaultPollInterval = time.Second
+	// DefaultLeaseTimeout is how long a task may be held by a PgWorker that has stopped renewing its lease.
+	DefaultLeaseTimeout = 5 * time.Minute
+	// maxRetryDelay limits the delay before a failed task is retried.
+	maxRetryDelay = 10 * time.Minute
+)
+
+var _ distributed.Queue = (*PgQueue)(nil)
+
+// PgQueue enqueues tipsets in the visor_queue_tasks table of a postgres database.
+type PgQueue struct {
+	db       *storage.Database
+	maxRetry func(queue string) int
+}
+
+// NewPgQueue returns a queue that enqueues tasks in db. maxRetry returns the number of times a task enqueued on a queue
+// is retried before it is archived, config.DefaultQueueMaxRetry is used when maxRetry is nil.
+func NewPgQueue(db *storage.Database, maxRetry func(queue string) int) *PgQueue {
+	if maxRetry == nil {
+		maxRetry = func(string) int { return config.DefaultQueueMaxRetry }
+	}
+	return &PgQueue{db: db, maxRetry: maxRetry}
+}
+
+func (p *PgQueue) EnqueueTipSet(ctx context.Context, ts *types.TipSet, indexType indexer.IndexerType, taskNames ...string) error {
+	ctx, span := otel.Tracer("").Start(ctx, "PgQueue.EnqueueTipSet")
+	defer span.End()
+
+	task, err := newTipSetTask(ctx, ts, indexType, taskNames)
+	if err != nil {
+		return err
+	}
+
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("task_type", task.Type()), attribute.StringSlice("tasks", taskNames), attribute.String("index_type", indexType.String()))
+	}
+
+	now := time.Now()
+	queue := indexType.String()
+	if _, err := p.db.AsORM().ModelContext(ctx, &visor.QueueTask{
+		Queue:     queue,
+		Type:      task.Type(),
+		Payload:   task.Payload(),
+		State:     visor.QueueTaskPending,
+		MaxRetry:  p.maxRetry(queue),
+		CreatedAt: now,
+		RunAt:     now,
+	}).Insert(); err != nil {
+		return fmt.Errorf("enqueue tipset: %w", err)
+	}
+	return nil
+}
+
+// PgWorker processes tasks enqueued by a PgQueue. Workers claim tasks using SELECT ... FOR UPDATE SKIP LOCKED so any
+// number of workers may consume from the same table.
+type PgWorker struct {
+	done chan struct{}
+
+	name     string
+	db       *storage.Database
+	cfg      config.PgQueueConfig
+	handlers map[string]TaskHandler
+}
+
+func NewPgWorker(name string, db *storage.Database, cfg config.PgQueueConfig, handlers ...TaskHandler) *PgWorker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.LeaseTimeout <= 0 {
+		cfg.LeaseTimeout = DefaultLeaseTimeout
+	}
+	if cfg.WorkerConfig.Concurrency <= 0 {
+		cfg.WorkerConfig.Concurrency = 1
+	}
+	hs := make(map[string]TaskHandler, len(handlers))
+	for _, h := range handlers {
+		hs[h.Type()] = h
+	}
+	return &PgWorker{
+		name:     name,
+		db:       db,
+		cfg:      cfg,
+		handlers: hs,
+	}
+}
+
+func (w *PgWorker) Run(ctx context.Context) error {
+	w.done = make(chan struct{})
+	defer close(w.done)
+
+	taskTypes := make([]string, 0, len(w.handlers))
+	for t := range w.handlers {
+		log.Infow("registered task handler", "type", t)
+		taskTypes = append(taskTypes, t)
+	}
+	sort.Strings(taskTypes)
+
+	if err := recordWorkerConfig(ctx, w.cfg.WorkerConfig.Concurrency, w.cfg.WorkerConfig.Queues()); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.WorkerConfig.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.consume(ctx, taskTypes)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *PgWorker) Done() <-chan struct{} {
+	return w.done
+}
+
+// consume claims and processes tasks until ctx is canceled.
+func (w *PgWorker) consume(ctx context.Context, taskTypes []string) {
+	for {
+		task, err := w.claim(ctx, taskTypes)
+		if err != nil && ctx.Err() == nil {
+			log.Errorw("failed to claim task", "name", w.name, "error", err)
+		}
+		if task == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+				continue
+			}
+		}
+		w.process(ctx, task)
+	}
+}
+
+// claim returns the next task that is ready to run from the worker's queues, visiting queues in priority order. Tasks
+// whose lease has expired are claimed as well, these were held by a worker that stopped before completing them. A nil
+// task is returned if there is no task ready.
+func (w *PgWorker) claim(ctx context.Context, taskTypes []string) (*visor.QueueTask, error) {
+	for _, queue := range w.queueOrder() {
+		task := new(visor.QueueTask)
+		_, err := w.db.AsORM().QueryOneContext(ctx, task, `
+			UPDATE visor_queue_tasks SET state = ?, locked_at = now()
+			WHERE id = (
+				SELECT id FROM visor_queue_tasks
+				WHERE queue = ? AND type = ANY(?)
+					AND ((state = ? AND run_at <= now()) OR (state = ? AND locked_at < now() - make_interval(secs => ?)))
+				ORDER BY run_at, id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			visor.QueueTaskActive,
+			queue, pg.Array(taskTypes),
+			visor.QueueTaskPending, visor.QueueTaskActive, w.cfg.LeaseTimeout.Seconds(),
+		)
+		if errors.Is(err, pg.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+	return nil, nil
+}
+
+// queueOrder returns the queues with a priority greater than zero in the order they should be visited. With strict
+// priority queues are always visited from highest to lowest priority, otherwise the order is randomized and weighted by
+// priority so low priority queues are not starved.
+func (w *PgWorker) queueOrder() []string {
+	type weighted struct {
+		queue    string
+		priority int
+	}
+	var qs []weighted
+	total := 0
+	for q, p := range w.cfg.WorkerConfig.Queues() {
+		if p > 0 {
+			qs = append(qs, weighted{queue: q, priority: p})
+			total += p
+		}
+	}
+	sort.Slice(qs, func(i, j int) bool {
+		if qs[i].priority != qs[j].priority {
+			return qs[i].priority > qs[j].priority
+		}
+		return qs[i].queue < qs[j].queue
+	})
+
+	out := make([]string, 0, len(qs))
+	if w.cfg.WorkerConfig.StrictPriority {
+		for _, q := range qs {
+			out = append(out, q.queue)
+		}
+		return out
+	}
+
+	for len(qs) > 0 {
+		n := rand.Intn(total) // nolint: gosec
+		for i, q := range qs {
+			if n < q.priority {
+				out = append(out, q.queue)
+				total -= q.priority
+				qs = append(qs[:i], qs[i+1:]...)
+				break
+			}
+			n -= q.priority
+		}
+	}
+	return out
+}
+
+// process runs the handler of a claimed task, renewing the task's lease until the handler returns, then deletes the
+// task if it succeeded or schedules a retry if it failed.
+//
+// The claim of the task is identified by the locked_at value last written by the worker. Every write made when the task
+// completes matches it, so a worker whose lease expired and whose task was claimed by another worker does not modify
+// the other worker's claim.
+func (w *PgWorker) process(ctx context.Context, task *visor.QueueTask) {
+	taskID := strconv.FormatInt(task.ID, 10)
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	leaseDone := make(chan struct{})
+	go func() {
+		defer close(leaseDone)
+		w.renewLease(leaseCtx, task)
+	}()
+
+	var err error
+	if h, ok := w.handlers[task.Type]; ok {
+		err = h.Process(ctx, taskID, task.Payload)
+	} else {
+		err = fmt.Errorf("no handler for task type %q", task.Type)
+	}
+	cancel()
+	<-leaseDone
+
+	// results are recorded even if the worker is stopping
+	rctx := context.Background()
+	if err == nil {
+		res, err := w.claimed(rctx, task).Delete()
+		if err != nil {
+			log.Errorw("failed to delete completed task", "name", w.name, "taskID", taskID, "error", err)
+		} else if res.RowsAffected() == 0 {
+			log.Warnw("completed task was claimed by another worker", "name", w.name, "taskID", taskID)
+		}
+		return
+	}
+
+	if ctx.Err() != nil {
+		// the worker was stopped while processing the task, release it without consuming a retry.
+		if _, err := w.claimed(rctx, task).
+			Set("state = ?, locked_at = NULL", visor.QueueTaskPending).
+			Update(); err != nil {
+			log.Errorw("failed to release task", "name", w.name, "taskID", taskID, "error", err)
+		}
+		return
+	}
+
+	logTaskError(ctx, task.Type, task.Payload, err)
+	q := w.claimed(rctx, task).
+		Set("locked_at = NULL, last_error = ?, last_failed_at = now()", err.Error())
+	if task.Retried >= task.MaxRetry {
+		q = q.Set("state = ?", visor.QueueTaskArchived)
+	} else {
+		q = q.Set("state = ?, retried = retried + 1, run_at = now() + make_interval(secs => ?)", visor.QueueTaskPending, retryDelay(task.Retried).Seconds())
+	}
+	res, err := q.Update()
+	if err != nil {
+		log.Errorw("failed to record task failure", "name", w.name, "taskID", taskID, "error", err)
+	} else if res.RowsAffected() == 0 {
+		log.Warnw("failed task was claimed by another worker", "name", w.name, "taskID", taskID)
+	}
+}
+
+// claimed returns a query matching task only while it is still claimed by the worker.
+func (w *PgWorker) claimed(ctx context.Context, task *visor.QueueTask) *pg.Query {
+	return w.db.AsORM().ModelContext(ctx, task).
+		WherePK().
+		Where("state = ? AND locked_at = ?", visor.QueueTaskActive, task.LockedAt)
+}
+
+// renewLease updates the lease of a task until ctx is canceled, recording the renewed locked_at value in task. It stops
+// renewing if the task has been claimed by another worker.
+func (w *PgWorker) renewLease(ctx context.Context, task *visor.QueueTask) {
+	ticker := time.NewTicker(w.cfg.LeaseTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var lockedAt time.Time
+			res, err := w.claimed(ctx, task).
+				Set("locked_at = now()").
+				Returning("locked_at").
+				Update(pg.Scan(&lockedAt))
+			if err != nil && !errors.Is(err, pg.ErrNoRows) {
+				if ctx.Err() == nil {
+					log.Errorw("failed to renew task lease", "name", w.name, "taskID", task.ID, "error", err)
+				}
+				continue
+			}
+			if err != nil || res.RowsAffected() == 0 {
+				log.Warnw("task was claimed by another worker", "name", w.name, "taskID", task.ID)
+				return
+			}
+			task.LockedAt = lockedAt
+		}
+	}
+}
+
+// retryDelay returns how long to wait before a task that has been retried n times is run again.
+func retryDelay(n int) time.Duration {
+	d := time.Second
+	for i := 0; i < n; i++ {
+		d *= 2
+		if d >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return d
+}
+
+var _ FailedTasks = (*PgFailedTasks)(nil)
+
+// PgFailedTasks lists, retries and purges archived tasks of a postgres backed queue.
+type PgFailedTasks struct {
+	db *storage.Database
+}
+
+func NewPgFailedTasks(db *storage.Database) *PgFailedTasks {
+	return &PgFailedTasks{db: db}
+}
+
+func (p *PgFailedTasks) List(ctx context.Context, queues ...string) ([]*FailedTask, error) {
+	var archived []*visor.QueueTask
+	if err := p.archived(ctx, &archived, queues, nil).Order("id").Select(); err != nil {
+		return nil, fmt.Errorf("list archived tasks: %w", err)
+	}
+
+	out := make([]*FailedTask, 0, len(archived))
+	for _, t := range archived {
+		ft, err := decodeFailedTask(strconv.FormatInt(t.ID, 10), t.Queue, t.Type, t.Payload)
+		if err != nil {
+			log.Warnw("skipping archived task", "queue", t.Queue, "id", t.ID, "error", err)
+			continue
+		}
+		if ft == nil {
+			continue
+		}
+		ft.Retried = t.Retried
+		ft.MaxRetry = t.MaxRetry
+		ft.LastError = t.LastError
+		ft.LastFailedAt = t.LastFailedAt
+		out = append(out, ft)
+	}
+	return out, nil
+}
+
+func (p *PgFailedTasks) Retry(ctx context.Context, queues []string, ids []string) (int, error) {
+	return p.apply(ctx, queues, ids, func(q *pg.Query) (pg.Result, error) {
+		return q.Set("state = ?, retried = 0, run_at = now(), locked_at = NULL", visor.QueueTaskPending).Update()
+	})
+}
+
+func (p *PgFailedTasks) Purge(ctx context.Context, queues []string, ids []string) (int, error) {
+	return p.apply(ctx, queues, ids, func(q *pg.Query) (pg.Result, error) {
+		return q.Delete()
+	})
+}
+
+func (p *PgFailedTasks) apply(ctx context.Context, queues []string, ids []string, fn func(q *pg.Query) (pg.Result, error)) (int, error) {
+	var taskIDs []int64
+	for _, id := range ids {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid task id %q", id)
+		}
+		taskIDs = append(taskIDs, n)
+	}
+
+	res, err := fn(p.archived(ctx, &visor.QueueTask{}, queues, taskIDs))
+	if err != nil {
+		return 0, err
+	}
+	if len(taskIDs) > 0 && res.RowsAffected() != len(taskIDs) {
+		return res.RowsAffected(), fmt.Errorf("archived tasks not found: found %d of %d", res.RowsAffected(), len(taskIDs))
+	}
+	return res.RowsAffected(), nil
+}
+
+// archived returns a query selecting the archived tasks of queues, all queues if empty, restricted to ids if any are
+// given.
+func (p *PgFailedTasks) archived(ctx context.Context, model interface{}, queues []string, ids []int64) *pg.Query {
+	q := p.db.AsORM().ModelContext(ctx, model).Where("state = ?", visor.QueueTaskArchived)
+	if len(queues) > 0 {
+		q = q.Where("queue = ANY(?)", pg.Array(queues))
+	}
+	if len(ids) > 0 {
+		q = q.Where("id = ANY(?)", pg.Array(ids))
+	}
+	return q
+}
@@ -0,0 +1,178 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue/tasks"
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+func TestPgWorkerQueueOrder(t *testing.T) {
+	cfg := config.PgQueueConfig{
+		WorkerConfig: config.WorkerConfig{
+			WatchQueuePriority: 5,
+			FillQueuePriority:  3,
+			IndexQueuePriority: 1,
+			WalkQueuePriority:  0,
+		},
+	}
+
+	// strict priority always visits queues from highest to lowest priority, skipping disabled queues
+	cfg.WorkerConfig.StrictPriority = true
+	w := NewPgWorker("test", nil, cfg)
+	require.Equal(t, []string{"watch", "fill", "index"}, w.queueOrder())
+
+	// weighted order visits every enabled queue once
+	cfg.WorkerConfig.StrictPriority = false
+	w = NewPgWorker("test", nil, cfg)
+	for i := 0; i < 20; i++ {
+		require.ElementsMatch(t, []string{"watch", "fill", "index"}, w.queueOrder())
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, time.Second, retryDelay(0))
+	require.Equal(t, 2*time.Second, retryDelay(1))
+	require.Equal(t, 8*time.Second, retryDelay(3))
+	require.Equal(t, maxRetryDelay, retryDelay(20))
+}
+
+// testTaskHandler processes index tasks, returning err.
+type testTaskHandler struct {
+	err error
+}
+
+func (h *testTaskHandler) Type() string { return tasks.TypeIndexTipSet }
+
+func (h *testTaskHandler) Process(context.Context, string, []byte) error { return h.err }
+
+func TestPgWorker(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	strg, err := storage.NewDatabaseFromDB(ctx, db, "public")
+	require.NoError(t, err, "NewDatabaseFromDB")
+
+	taskTypes := []string{tasks.TypeIndexTipSet}
+	cfg := config.PgQueueConfig{WorkerConfig: config.WorkerConfig{WatchQueuePriority: 1}}
+
+	// enqueue truncates the queue table then enqueues a single tipset that may be retried maxRetry times.
+	enqueue := func(t *testing.T, maxRetry int) {
+		_, err := db.Exec(`TRUNCATE TABLE visor_queue_tasks`)
+		require.NoError(t, err)
+		q := NewPgQueue(strg, func(string) int { return maxRetry })
+		require.NoError(t, q.EnqueueTipSet(ctx, testutil.MustFakeTipSet(t, 10), indexer.Watch, "blocks"))
+	}
+
+	load := func(t *testing.T) []*visor.QueueTask {
+		var out []*visor.QueueTask
+		require.NoError(t, db.Model(&out).Order("id").Select())
+		return out
+	}
+
+	t.Run("claim", func(t *testing.T) {
+		enqueue(t, 0)
+		w := NewPgWorker("test", strg, cfg, &testTaskHandler{})
+
+		task, err := w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		require.NotNil(t, task)
+		assert.Equal(t, visor.QueueTaskActive, task.State)
+		assert.False(t, task.LockedAt.IsZero())
+
+		// an active task is not claimed again while its lease is held
+		other, err := w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		assert.Nil(t, other)
+
+		w.process(ctx, task)
+		assert.Empty(t, load(t))
+	})
+
+	t.Run("lease expiry", func(t *testing.T) {
+		enqueue(t, 0)
+		stale := NewPgWorker("stale", strg, cfg, &testTaskHandler{})
+		stalled, err := stale.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		require.NotNil(t, stalled)
+
+		// a worker with a short lease timeout claims the task once the stale worker's lease has expired
+		shortCfg := cfg
+		shortCfg.LeaseTimeout = 10 * time.Millisecond
+		time.Sleep(2 * shortCfg.LeaseTimeout)
+		w := NewPgWorker("test", strg, shortCfg, &testTaskHandler{})
+		claimed, err := w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		require.Equal(t, stalled.ID, claimed.ID)
+
+		// the stale worker completing the task does not remove the other worker's claim
+		stale.process(ctx, stalled)
+		rows := load(t)
+		require.Len(t, rows, 1)
+		assert.Equal(t, visor.QueueTaskActive, rows[0].State)
+		assert.True(t, claimed.LockedAt.Equal(rows[0].LockedAt))
+
+		w.process(ctx, claimed)
+		assert.Empty(t, load(t))
+	})
+
+	t.Run("retry and archive", func(t *testing.T) {
+		enqueue(t, 1)
+		w := NewPgWorker("test", strg, cfg, &testTaskHandler{err: errors.New("boom")})
+
+		task, err := w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		require.NotNil(t, task)
+		w.process(ctx, task)
+
+		// the failed task is rescheduled after a delay
+		rows := load(t)
+		require.Len(t, rows, 1)
+		assert.Equal(t, visor.QueueTaskPending, rows[0].State)
+		assert.Equal(t, 1, rows[0].Retried)
+		assert.Equal(t, "boom", rows[0].LastError)
+		assert.True(t, rows[0].LockedAt.IsZero())
+		assert.True(t, rows[0].RunAt.After(task.LockedAt))
+
+		retry, err := w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		assert.Nil(t, retry, "task claimed before its retry delay")
+
+		_, err = db.Exec(`UPDATE visor_queue_tasks SET run_at = now()`)
+		require.NoError(t, err)
+		retry, err = w.claim(ctx, taskTypes)
+		require.NoError(t, err)
+		require.NotNil(t, retry)
+		w.process(ctx, retry)
+
+		// the task is archived once its retries are exhausted
+		rows = load(t)
+		require.Len(t, rows, 1)
+		assert.Equal(t, visor.QueueTaskArchived, rows[0].State)
+		assert.Equal(t, 1, rows[0].Retried)
+
+		failed, err := NewPgFailedTasks(strg).List(ctx)
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		assert.EqualValues(t, 10, failed[0].Height)
+	})
+}
@@ -78,12 +78,16 @@ func (gh *GapFillTipSetHandler) Type() string {
 }
 
 func (gh *GapFillTipSetHandler) HandleGapFillTipSetTask(ctx context.Context, t *asynq.Task) error {
+	return gh.Process(ctx, t.ResultWriter().TaskID(), t.Payload())
+}
+
+// Process indexes the tipset of a gap fill task with the given id and payload and marks its gaps as filled.
+func (gh *GapFillTipSetHandler) Process(ctx context.Context, taskID string, payload []byte) error {
 	var p GapFillTipSetPayload
-	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+	if err := json.Unmarshal(payload, &p); err != nil {
 		return err
 	}
 
-	taskID := t.ResultWriter().TaskID()
 	log.Infow("gap fill tipset", "taskID", taskID, zap.Inline(p))
 
 	if p.HasTraceCarrier() {
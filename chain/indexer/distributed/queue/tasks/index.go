@@ -79,12 +79,16 @@ func (ih *TipSetTaskHandler) Handler() asynq.HandlerFunc {
 }
 
 func (ih *TipSetTaskHandler) HandleIndexTipSetTask(ctx context.Context, t *asynq.Task) error {
+	return ih.Process(ctx, t.ResultWriter().TaskID(), t.Payload())
+}
+
+// Process indexes the tipset of an index task with the given id and payload.
+func (ih *TipSetTaskHandler) Process(ctx context.Context, taskID string, payload []byte) error {
 	var p IndexTipSetPayload
-	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+	if err := json.Unmarshal(payload, &p); err != nil {
 		return err
 	}
 
-	taskID := t.ResultWriter().TaskID()
 	log.Infow("indexing tipset", "taskID", taskID, zap.Inline(p))
 
 	if p.HasTraceCarrier() {
@@ -18,6 +18,24 @@ import (
 
 var log = logging.Logger("lily/distributed/worker")
 
+// Worker consumes tipset tasks from a queue backend and processes them with the TaskHandler registered for their
+// type. A Worker is run as a job by the scheduler.
+type Worker interface {
+	Run(ctx context.Context) error
+	Done() <-chan struct{}
+}
+
+var (
+	_ Worker = (*AsynqWorker)(nil)
+	_ Worker = (*PgWorker)(nil)
+)
+
+// TaskHandler processes tasks of a single type, independent of the queue backend that delivered them.
+type TaskHandler interface {
+	Type() string
+	Process(ctx context.Context, taskID string, payload []byte) error
+}
+
 type AsynqWorker struct {
 	done chan struct{}
 
@@ -25,10 +43,6 @@ type AsynqWorker struct {
 	server   *distributed.TipSetWorker
 	handlers []TaskHandler
 }
-type TaskHandler interface {
-	Type() string
-	Handler() asynq.HandlerFunc
-}
 
 func NewAsynqWorker(name string, server *distributed.TipSetWorker, handlers ...TaskHandler) *AsynqWorker {
 	return &AsynqWorker{
@@ -45,19 +59,17 @@ func (t *AsynqWorker) Run(ctx context.Context) error {
 	mux := asynq.NewServeMux()
 	for _, handler := range t.handlers {
 		log.Infow("registered task handler", "type", handler.Type())
-		mux.HandleFunc(handler.Type(), handler.Handler())
+		h := handler
+		mux.HandleFunc(h.Type(), func(ctx context.Context, task *asynq.Task) error {
+			return h.Process(ctx, task.ResultWriter().TaskID(), task.Payload())
+		})
 	}
 
 	t.server.ServerConfig.Logger = log.With("name", t.name)
 	t.server.ServerConfig.ErrorHandler = &WorkerErrorHandler{}
 
-	stats.Record(ctx, metrics.TipSetWorkerConcurrency.M(int64(t.server.ServerConfig.Concurrency)))
-	for queueName, priority := range t.server.ServerConfig.Queues {
-		if err := stats.RecordWithTags(ctx,
-			[]tag.Mutator{tag.Upsert(metrics.QueueName, queueName)},
-			metrics.TipSetWorkerQueuePriority.M(int64(priority))); err != nil {
-			return err
-		}
+	if err := recordWorkerConfig(ctx, t.server.ServerConfig.Concurrency, t.server.ServerConfig.Queues); err != nil {
+		return err
 	}
 
 	server := asynq.NewServer(t.server.RedisConfig, t.server.ServerConfig)
@@ -73,13 +85,30 @@ func (t *AsynqWorker) Done() <-chan struct{} {
 	return t.done
 }
 
+func recordWorkerConfig(ctx context.Context, concurrency int, queues map[string]int) error {
+	stats.Record(ctx, metrics.TipSetWorkerConcurrency.M(int64(concurrency)))
+	for queueName, priority := range queues {
+		if err := stats.RecordWithTags(ctx,
+			[]tag.Mutator{tag.Upsert(metrics.QueueName, queueName)},
+			metrics.TipSetWorkerQueuePriority.M(int64(priority))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type WorkerErrorHandler struct{}
 
 func (w *WorkerErrorHandler) HandleError(ctx context.Context, task *asynq.Task, err error) {
-	switch task.Type() {
+	logTaskError(ctx, task.Type(), task.Payload(), err)
+}
+
+// logTaskError logs a failed task and records the error on the span the task was enqueued with.
+func logTaskError(ctx context.Context, taskType string, payload []byte, err error) {
+	switch taskType {
 	case tasks.TypeIndexTipSet:
 		var p tasks.IndexTipSetPayload
-		if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		if err := json.Unmarshal(payload, &p); err != nil {
 			log.Errorw("failed to decode task type (developer error?)", "error", err)
 			return
 		}
@@ -89,10 +118,10 @@ func (w *WorkerErrorHandler) HandleError(ctx context.Context, task *asynq.Task,
 				trace.SpanFromContext(ctx).RecordError(err)
 			}
 		}
-		log.Errorw("task failed", zap.Inline(p), "type", task.Type(), "error", err)
+		log.Errorw("task failed", zap.Inline(p), "type", taskType, "error", err)
 	case tasks.TypeGapFillTipSet:
 		var p tasks.GapFillTipSetPayload
-		if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		if err := json.Unmarshal(payload, &p); err != nil {
 			log.Errorw("failed to decode task type (developer error?)", "error", err)
 			return
 		}
@@ -102,6 +131,6 @@ func (w *WorkerErrorHandler) HandleError(ctx context.Context, task *asynq.Task,
 				trace.SpanFromContext(ctx).RecordError(err)
 			}
 		}
-		log.Errorw("task failed", zap.Inline(p), "type", task.Type(), "error", err)
+		log.Errorw("task failed", zap.Inline(p), "type", taskType, "error", err)
 	}
 }
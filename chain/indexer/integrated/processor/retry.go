@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+)
+
+// RetryPolicy configures how a task that fails while processing a tipset is retried. A task fails when it returns an
+// error or when it reports errors in its processing report.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the task is run for a tipset, values less than 2 disable retries.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, the delay doubles after each subsequent attempt.
+	Backoff time.Duration
+	// MaxBackoff limits the delay between attempts, zero means no limit.
+	MaxBackoff time.Duration
+}
+
+// delay returns how long to wait after the given attempt failed.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// Do runs fn until it succeeds, the policy's attempts are exhausted or ctx is canceled, returning the number of
+// attempts made. The result of the last attempt is left to fn to record.
+func (p RetryPolicy) Do(ctx context.Context, task string, fn func() (visormodel.ProcessingReportList, error)) int {
+	attempt := 1
+	for {
+		reports, err := fn()
+		if !failed(reports, err) || attempt >= p.MaxAttempts {
+			return attempt
+		}
+
+		delay := p.delay(attempt)
+		log.Warnw("task failed, retrying", "task", task, "attempt", attempt, "max_attempts", p.MaxAttempts, "backoff", delay, "error", reportError(reports, err))
+		select {
+		case <-ctx.Done():
+			return attempt
+		case <-time.After(delay):
+		}
+		attempt++
+	}
+}
+
+func failed(reports visormodel.ProcessingReportList, err error) bool {
+	return reportError(reports, err) != nil
+}
+
+func reportError(reports visormodel.ProcessingReportList, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		if r != nil && r.ErrorsDetected != nil {
+			return r.ErrorsDetected
+		}
+	}
+	return nil
+}
+
+// RetryPolicies holds the retry policy used for each task.
+type RetryPolicies struct {
+	// Default is used by tasks that do not have a policy of their own.
+	Default RetryPolicy
+	// Tasks holds policies keyed by task name. A key may also be a task alias, such as actorstatesminer, in which
+	// case the policy applies to all tasks of the alias that do not have a policy of their own. A task belonging to
+	// several aliases with a policy uses the policy of the first of them in lexical order.
+	Tasks map[string]RetryPolicy
+}
+
+// For returns the retry policy for the named task.
+func (r RetryPolicies) For(task string) RetryPolicy {
+	if p, ok := r.Tasks[task]; ok {
+		return p
+	}
+	aliases := make([]string, 0, len(r.Tasks))
+	for alias := range r.Tasks {
+		if _, ok := tasktype.TaskLookup[alias]; ok {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		for _, t := range tasktype.TaskLookup[alias] {
+			if t == task {
+				return r.Tasks[alias]
+			}
+		}
+	}
+	return r.Default
+}
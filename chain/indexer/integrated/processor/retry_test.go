@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+	// succeeds first time
+	calls := 0
+	attempts := policy.Do(ctx, "task", func() (visormodel.ProcessingReportList, error) {
+		calls++
+		return visormodel.ProcessingReportList{{}}, nil
+	})
+	require.Equal(t, 1, attempts)
+	require.Equal(t, 1, calls)
+
+	// an error is retried until it succeeds
+	calls = 0
+	attempts = policy.Do(ctx, "task", func() (visormodel.ProcessingReportList, error) {
+		calls++
+		if calls < 2 {
+			return nil, errors.New("blockstore miss")
+		}
+		return visormodel.ProcessingReportList{{}}, nil
+	})
+	require.Equal(t, 2, attempts)
+
+	// errors in a report are retried until attempts are exhausted
+	calls = 0
+	attempts = policy.Do(ctx, "task", func() (visormodel.ProcessingReportList, error) {
+		calls++
+		return visormodel.ProcessingReportList{{ErrorsDetected: errors.New("timeout")}}, nil
+	})
+	require.Equal(t, 3, attempts)
+	require.Equal(t, 3, calls)
+
+	// no retries without a policy
+	calls = 0
+	attempts = RetryPolicy{}.Do(ctx, "task", func() (visormodel.ProcessingReportList, error) {
+		calls++
+		return nil, errors.New("fail")
+	})
+	require.Equal(t, 1, attempts)
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, policy.delay(1))
+	require.Equal(t, 2*time.Second, policy.delay(2))
+	require.Equal(t, 4*time.Second, policy.delay(3))
+	require.Equal(t, 5*time.Second, policy.delay(4))
+	require.Equal(t, 5*time.Second, policy.delay(100))
+}
+
+func TestRetryPoliciesFor(t *testing.T) {
+	policies := RetryPolicies{
+		Default: RetryPolicy{MaxAttempts: 1},
+		Tasks: map[string]RetryPolicy{
+			tasktype.ActorStatesMinerTask: {MaxAttempts: 3},
+			tasktype.MinerInfo:            {MaxAttempts: 5},
+		},
+	}
+	require.Equal(t, 5, policies.For(tasktype.MinerInfo).MaxAttempts)
+	require.Equal(t, 3, policies.For(tasktype.MinerSectorDeal).MaxAttempts)
+	require.Equal(t, 1, policies.For(tasktype.BlockHeader).MaxAttempts)
+
+	t.Run("task of several aliases", func(t *testing.T) {
+		// a second alias holding the miner tasks, ordered after actorstatesminer
+		tasktype.TaskLookup["zzminers"] = tasktype.TaskLookup[tasktype.ActorStatesMinerTask]
+		defer delete(tasktype.TaskLookup, "zzminers")
+
+		policies := RetryPolicies{
+			Default: RetryPolicy{MaxAttempts: 1},
+			Tasks: map[string]RetryPolicy{
+				"zzminers":                    {MaxAttempts: 4},
+				tasktype.ActorStatesMinerTask: {MaxAttempts: 3},
+			},
+		}
+		for i := 0; i < 20; i++ {
+			require.Equal(t, 3, policies.For(tasktype.MinerSectorDeal).MaxAttempts)
+		}
+	})
+}
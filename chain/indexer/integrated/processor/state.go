@@ -25,6 +25,7 @@ import (
 	marketactors "github.com/filecoin-project/lily/chain/actors/builtin/market"
 	mineractors "github.com/filecoin-project/lily/chain/actors/builtin/miner"
 	multisigactors "github.com/filecoin-project/lily/chain/actors/builtin/multisig"
+	paychactors "github.com/filecoin-project/lily/chain/actors/builtin/paych"
 	poweractors "github.com/filecoin-project/lily/chain/actors/builtin/power"
 	rewardactors "github.com/filecoin-project/lily/chain/actors/builtin/reward"
 	verifregactors "github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
@@ -36,16 +37,19 @@ import (
 	"github.com/filecoin-project/lily/tasks/messages/receiptreturn"
 
 	// actor tasks
+	actorbalancetask "github.com/filecoin-project/lily/tasks/actorbalance"
 	"github.com/filecoin-project/lily/tasks/actorstate"
 	datacaptask "github.com/filecoin-project/lily/tasks/actorstate/datacap"
 	inittask "github.com/filecoin-project/lily/tasks/actorstate/init_"
 	markettask "github.com/filecoin-project/lily/tasks/actorstate/market"
 	minertask "github.com/filecoin-project/lily/tasks/actorstate/miner"
 	multisigtask "github.com/filecoin-project/lily/tasks/actorstate/multisig"
+	paychtask "github.com/filecoin-project/lily/tasks/actorstate/paych"
 	powertask "github.com/filecoin-project/lily/tasks/actorstate/power"
 	rawtask "github.com/filecoin-project/lily/tasks/actorstate/raw"
 	rewardtask "github.com/filecoin-project/lily/tasks/actorstate/reward"
 	verifregtask "github.com/filecoin-project/lily/tasks/actorstate/verifreg"
+	minerpenaltytask "github.com/filecoin-project/lily/tasks/minerpenalty"
 
 	// chain state tasks
 	drandtask "github.com/filecoin-project/lily/tasks/blocks/drand"
@@ -60,6 +64,7 @@ import (
 	gasecontask "github.com/filecoin-project/lily/tasks/messages/gaseconomy"
 	gasouttask "github.com/filecoin-project/lily/tasks/messages/gasoutput"
 	messagetask "github.com/filecoin-project/lily/tasks/messages/message"
+	methodgasstatstask "github.com/filecoin-project/lily/tasks/messages/methodgasstats"
 	parentmessagetask "github.com/filecoin-project/lily/tasks/messages/parsedmessage"
 	receipttask "github.com/filecoin-project/lily/tasks/messages/receipt"
 	msapprovaltask "github.com/filecoin-project/lily/tasks/msapprovals"
@@ -67,7 +72,10 @@ import (
 	// fevm task
 	fevmblockheadertask "github.com/filecoin-project/lily/tasks/fevm/blockheader"
 	fevmcontracttask "github.com/filecoin-project/lily/tasks/fevm/contract"
+	contracteventtask "github.com/filecoin-project/lily/tasks/fevm/contractevent"
 	fevmreceipttask "github.com/filecoin-project/lily/tasks/fevm/receipt"
+	fevmstoragechangetask "github.com/filecoin-project/lily/tasks/fevm/storagechange"
+	fevmtokentask "github.com/filecoin-project/lily/tasks/fevm/token"
 	fevmtracetask "github.com/filecoin-project/lily/tasks/fevm/trace"
 	fevmtransactiontask "github.com/filecoin-project/lily/tasks/fevm/transaction"
 	fevmactorstatstask "github.com/filecoin-project/lily/tasks/fevmactorstats"
@@ -121,22 +129,53 @@ var log = logging.Logger("lily/index/processor")
 
 const BuiltinTaskName = "builtin"
 
-func New(api tasks.DataSource, name string, taskNames []string) (*StateProcessor, error) {
+type StateProcessorOpt func(sp *StateProcessor)
+
+// WithRetryPolicies sets the policies used to retry tasks that fail. By default failed tasks are not retried.
+func WithRetryPolicies(r RetryPolicies) StateProcessorOpt {
+	return func(sp *StateProcessor) {
+		sp.retry = r
+	}
+}
+
+// WithTaskConfig sets the configuration of the tasks of the processor.
+func WithTaskConfig(c TaskConfig) StateProcessorOpt {
+	return func(sp *StateProcessor) {
+		sp.taskConfig = c
+	}
+}
+
+// TaskConfig holds the configuration of tasks that is read from the daemon config rather than given by their names.
+type TaskConfig struct {
+	// BuiltinActorEvents are the types of the built-in actor events written by the builtin_actor_event and event_*
+	// tasks, all the types emitted by the built-in actors when empty.
+	BuiltinActorEvents []string
+	// ContractEventFilters select the FEVM contract events written by the contract_events task, which writes none
+	// when empty.
+	ContractEventFilters []*contracteventtask.Filter
+}
+
+func New(api tasks.DataSource, name string, taskNames []string, opts ...StateProcessorOpt) (*StateProcessor, error) {
 	taskNames = append(taskNames, BuiltinTaskName)
 
-	processors, err := MakeProcessors(api, taskNames)
+	sp := &StateProcessor{
+		api:  api,
+		name: name,
+	}
+	for _, opt := range opts {
+		opt(sp)
+	}
+
+	processors, err := MakeProcessors(api, taskNames, sp.taskConfig)
 	if err != nil {
 		return nil, err
 	}
-	return &StateProcessor{
-		builtinProcessors:           processors.ReportProcessors,
-		tipsetProcessors:            processors.TipsetProcessors,
-		tipsetsProcessors:           processors.TipsetsProcessors,
-		actorProcessors:             processors.ActorProcessors,
-		periodicActorDumpProcessors: processors.PeriodicActorDumpProcessors,
-		api:                         api,
-		name:                        name,
-	}, nil
+	sp.builtinProcessors = processors.ReportProcessors
+	sp.tipsetProcessors = processors.TipsetProcessors
+	sp.tipsetsProcessors = processors.TipsetsProcessors
+	sp.actorProcessors = processors.ActorProcessors
+	sp.periodicActorDumpProcessors = processors.PeriodicActorDumpProcessors
+	return sp, nil
 }
 
 type StateProcessor struct {
@@ -154,6 +193,12 @@ type StateProcessor struct {
 
 	// name of the processor
 	name string
+
+	// retry holds the policies used to retry failed tasks
+	retry RetryPolicies
+
+	// taskConfig holds the configuration of the tasks
+	taskConfig TaskConfig
 }
 
 // A Result is either some data to persist or an error which indicates that the task did not complete. Partial
@@ -165,6 +210,8 @@ type Result struct {
 	Data        model.Persistable
 	StartedAt   time.Time
 	CompletedAt time.Time
+	// Attempts is the number of times the task was run to produce the result.
+	Attempts int
 }
 
 // State executes its configured processors in parallel, processing the state in `current` and `executed. The return channel
@@ -216,7 +263,14 @@ func (sp *StateProcessor) startReport(ctx context.Context, current *types.TipSet
 				sp.pwg.Done()
 			}()
 
-			report, err := p.ProcessTipSet(ctx, current)
+			var (
+				report visormodel.ProcessingReportList
+				err    error
+			)
+			attempts := sp.retry.For(name).Do(ctx, name, func() (visormodel.ProcessingReportList, error) {
+				report, err = p.ProcessTipSet(ctx, current)
+				return report, err
+			})
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -224,6 +278,7 @@ func (sp *StateProcessor) startReport(ctx context.Context, current *types.TipSet
 					Error:       err,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    attempts,
 				}
 				pl.Errorw("processor error", "error", err)
 				return
@@ -233,6 +288,7 @@ func (sp *StateProcessor) startReport(ctx context.Context, current *types.TipSet
 				Report:      report,
 				StartedAt:   start,
 				CompletedAt: time.Now(),
+				Attempts:    attempts,
 			}
 		}()
 	}
@@ -263,7 +319,15 @@ func (sp *StateProcessor) startTipSet(ctx context.Context, current *types.TipSet
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessTipSet(ctx, current)
+			var (
+				data   model.Persistable
+				report *visormodel.ProcessingReport
+				err    error
+			)
+			attempts := sp.retry.For(name).Do(ctx, name, func() (visormodel.ProcessingReportList, error) {
+				data, report, err = p.ProcessTipSet(ctx, current)
+				return visormodel.ProcessingReportList{report}, err
+			})
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -271,6 +335,7 @@ func (sp *StateProcessor) startTipSet(ctx context.Context, current *types.TipSet
 					Error:       err,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    attempts,
 				}
 				pl.Errorw("processor error", "error", err)
 				return
@@ -281,6 +346,7 @@ func (sp *StateProcessor) startTipSet(ctx context.Context, current *types.TipSet
 				Data:        data,
 				StartedAt:   start,
 				CompletedAt: time.Now(),
+				Attempts:    attempts,
 			}
 		}()
 	}
@@ -311,7 +377,15 @@ func (sp *StateProcessor) startTipSets(ctx context.Context, current, executed *t
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessTipSets(ctx, current, executed)
+			var (
+				data   model.Persistable
+				report *visormodel.ProcessingReport
+				err    error
+			)
+			attempts := sp.retry.For(name).Do(ctx, name, func() (visormodel.ProcessingReportList, error) {
+				data, report, err = p.ProcessTipSets(ctx, current, executed)
+				return visormodel.ProcessingReportList{report}, err
+			})
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -319,6 +393,7 @@ func (sp *StateProcessor) startTipSets(ctx context.Context, current, executed *t
 					Error:       err,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    attempts,
 				}
 				pl.Errorw("processor error", "error", err)
 				return
@@ -329,6 +404,7 @@ func (sp *StateProcessor) startTipSets(ctx context.Context, current, executed *t
 				Data:        data,
 				StartedAt:   start,
 				CompletedAt: time.Now(),
+				Attempts:    attempts,
 			}
 		}()
 	}
@@ -371,6 +447,7 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 					Data:        nil,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    1,
 				}
 				sp.pwg.Done()
 			}
@@ -394,7 +471,15 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 					sp.pwg.Done()
 				}()
 
-				data, report, err := p.ProcessActors(ctx, current, executed, changes)
+				var (
+					data   model.Persistable
+					report *visormodel.ProcessingReport
+					err    error
+				)
+				attempts := sp.retry.For(name).Do(ctx, name, func() (visormodel.ProcessingReportList, error) {
+					data, report, err = p.ProcessActors(ctx, current, executed, changes)
+					return visormodel.ProcessingReportList{report}, err
+				})
 				if err != nil {
 					stats.Record(ctx, metrics.ProcessingFailure.M(1))
 					results <- &Result{
@@ -402,6 +487,7 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 						Error:       err,
 						StartedAt:   start,
 						CompletedAt: time.Now(),
+						Attempts:    attempts,
 					}
 					pl.Warnw("processor error", "error", err)
 					return
@@ -412,6 +498,7 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 					Data:        data,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    attempts,
 				}
 			}()
 		}
@@ -491,7 +578,15 @@ func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *t
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessPeriodicActorDump(ctx, current, actors)
+			var (
+				data   model.Persistable
+				report *visormodel.ProcessingReport
+				err    error
+			)
+			attempts := sp.retry.For(name).Do(ctx, name, func() (visormodel.ProcessingReportList, error) {
+				data, report, err = p.ProcessPeriodicActorDump(ctx, current, actors)
+				return visormodel.ProcessingReportList{report}, err
+			})
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -499,6 +594,7 @@ func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *t
 					Error:       err,
 					StartedAt:   start,
 					CompletedAt: time.Now(),
+					Attempts:    attempts,
 				}
 				pl.Errorw("processor error", "error", err)
 				return
@@ -509,6 +605,7 @@ func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *t
 				Data:        data,
 				StartedAt:   start,
 				CompletedAt: time.Now(),
+				Attempts:    attempts,
 			}
 		}()
 	}
@@ -523,7 +620,7 @@ type IndexerProcessors struct {
 	PeriodicActorDumpProcessors map[string]PeriodicActorDumpProcessor
 }
 
-func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProcessors, error) {
+func MakeProcessors(api tasks.DataSource, indexerTasks []string, cfg TaskConfig) (*IndexerProcessors, error) {
 	out := &IndexerProcessors{
 		TipsetProcessors:            make(map[string]TipSetProcessor),
 		TipsetsProcessors:           make(map[string]TipSetsProcessor),
@@ -531,6 +628,27 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 		ReportProcessors:            make(map[string]ReportProcessor),
 		PeriodicActorDumpProcessors: make(map[string]PeriodicActorDumpProcessor),
 	}
+	// the built-in actor event tasks share the events they load and decode for each tipset.
+	var builtinEvents *builtinactorevent.Events
+	// the token tasks share the token logs they load for each tipset.
+	var tokenLogs *fevmtokentask.Logs
+	for _, t := range indexerTasks {
+		switch t {
+		case tasktype.BuiltInActorEvent, tasktype.EventDealLifecycle, tasktype.EventVerifierBalance,
+			tasktype.EventVerifregAllocation, tasktype.EventVerifregClaim, tasktype.EventSectorLifecycle:
+			if builtinEvents != nil {
+				continue
+			}
+			var err error
+			if builtinEvents, err = builtinactorevent.NewEvents(api, cfg.BuiltinActorEvents); err != nil {
+				return nil, err
+			}
+		case tasktype.FEVMTokenTransfer, tasktype.FEVMToken:
+			if tokenLogs == nil {
+				tokenLogs = fevmtokentask.NewLogs(api)
+			}
+		}
+	}
 	for _, t := range indexerTasks {
 		switch t {
 		case tasktype.DataCapBalance:
@@ -623,6 +741,14 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 				),
 				minertask.SectorEventsExtractor{},
 			)
+		case tasktype.MinerSectorLifecycle:
+			out.ActorProcessors[t] = actorstate.NewTaskWithTransformer(
+				api,
+				actorstate.NewTypedActorExtractorMap(
+					mineractors.AllCodes(), minertask.SectorLifecycleExtractor{},
+				),
+				minertask.SectorLifecycleExtractor{},
+			)
 		case tasktype.MinerSectorPost:
 			out.ActorProcessors[t] = actorstate.NewTask(api, actorstate.NewTypedActorExtractorMap(
 				mineractors.AllCodes(), minertask.PoStExtractor{},
@@ -720,6 +846,20 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 				multisigtask.MultiSigActorExtractor{},
 			))
 
+			//
+			// Payment Channel
+			//
+		case tasktype.PaychState:
+			out.ActorProcessors[t] = actorstate.NewTask(api, actorstate.NewTypedActorExtractorMap(
+				paychactors.AllCodes(),
+				paychtask.PaychStateExtractor{},
+			))
+		case tasktype.PaychLaneState:
+			out.ActorProcessors[t] = actorstate.NewTask(api, actorstate.NewTypedActorExtractorMap(
+				paychactors.AllCodes(),
+				paychtask.PaychLaneStateExtractor{},
+			))
+
 			//
 			// Verified Registry
 			//
@@ -771,6 +911,8 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 			rae.Register(&rawtask.RawActorStateExtractor{})
 			rat := &rawtask.RawActorStateExtractor{}
 			out.ActorProcessors[t] = actorstate.NewTaskWithTransformer(api, rae, rat)
+		case tasktype.ActorBalanceChange:
+			out.TipsetsProcessors[t] = actorbalancetask.NewTask(api)
 
 			//
 			// Messages
@@ -786,6 +928,8 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 
 		case tasktype.GasOutputs:
 			out.TipsetsProcessors[t] = gasouttask.NewTask(api)
+		case tasktype.MethodGasStats:
+			out.TipsetsProcessors[t] = methodgasstatstask.NewTask(api)
 		case tasktype.ParsedMessage:
 			out.TipsetsProcessors[t] = parentmessagetask.NewTask(api)
 		case tasktype.Receipt:
@@ -801,9 +945,21 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 		case tasktype.ActorEvent:
 			out.TipsetsProcessors[t] = actorevent.NewTask(api)
 		case tasktype.BuiltInActorEvent:
-			out.TipsetsProcessors[t] = builtinactorevent.NewTask(api)
+			out.TipsetsProcessors[t] = builtinactorevent.NewTask(builtinEvents)
+		case tasktype.EventDealLifecycle:
+			out.TipsetsProcessors[t] = builtinactorevent.NewDealLifecycleTask(builtinEvents)
+		case tasktype.EventVerifierBalance:
+			out.TipsetsProcessors[t] = builtinactorevent.NewVerifierBalanceTask(builtinEvents)
+		case tasktype.EventVerifregAllocation:
+			out.TipsetsProcessors[t] = builtinactorevent.NewVerifregAllocationTask(builtinEvents)
+		case tasktype.EventVerifregClaim:
+			out.TipsetsProcessors[t] = builtinactorevent.NewVerifregClaimTask(builtinEvents)
+		case tasktype.EventSectorLifecycle:
+			out.TipsetsProcessors[t] = builtinactorevent.NewSectorLifecycleTask(builtinEvents)
 		case tasktype.MinerCronFee:
 			out.TipsetsProcessors[t] = minertask.NewTask(api)
+		case tasktype.MinerPenalty:
+			out.TipsetsProcessors[t] = minerpenaltytask.NewTask(api)
 		case tasktype.ReceiptReturn:
 			out.TipsetsProcessors[t] = receiptreturn.NewTask(api)
 
@@ -839,6 +995,14 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 			out.TipsetsProcessors[t] = fevmcontracttask.NewTask(api)
 		case tasktype.FEVMTrace:
 			out.TipsetsProcessors[t] = fevmtracetask.NewTask(api)
+		case tasktype.ContractEvent:
+			out.TipsetsProcessors[t] = contracteventtask.NewTask(api, cfg.ContractEventFilters)
+		case tasktype.FEVMTokenTransfer:
+			out.TipsetsProcessors[t] = fevmtokentask.NewTransferTask(tokenLogs)
+		case tasktype.FEVMToken:
+			out.TipsetsProcessors[t] = fevmtokentask.NewTokenTask(api, tokenLogs)
+		case tasktype.FEVMStorageChange:
+			out.TipsetsProcessors[t] = fevmstoragechangetask.NewTask(api)
 
 			//
 			// Dump
@@ -16,6 +16,8 @@ import (
 	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
 	"github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/tasks/actorbalance"
 	"github.com/filecoin-project/lily/tasks/actorstate"
 	datacaptask "github.com/filecoin-project/lily/tasks/actorstate/datacap"
 	inittask "github.com/filecoin-project/lily/tasks/actorstate/init_"
@@ -31,18 +33,24 @@ import (
 	"github.com/filecoin-project/lily/tasks/blocks/parents"
 	"github.com/filecoin-project/lily/tasks/chaineconomics"
 	"github.com/filecoin-project/lily/tasks/consensus"
+	"github.com/filecoin-project/lily/tasks/fevm/contractevent"
+	fevmstoragechange "github.com/filecoin-project/lily/tasks/fevm/storagechange"
+	fevmtoken "github.com/filecoin-project/lily/tasks/fevm/token"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalmessage"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalparsedmessage"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/vm"
 	"github.com/filecoin-project/lily/tasks/messages/actorevent"
 	"github.com/filecoin-project/lily/tasks/messages/blockmessage"
+	"github.com/filecoin-project/lily/tasks/messages/builtinactorevent"
 	"github.com/filecoin-project/lily/tasks/messages/gaseconomy"
 	"github.com/filecoin-project/lily/tasks/messages/gasoutput"
 	"github.com/filecoin-project/lily/tasks/messages/message"
 	"github.com/filecoin-project/lily/tasks/messages/messageparam"
+	"github.com/filecoin-project/lily/tasks/messages/methodgasstats"
 	"github.com/filecoin-project/lily/tasks/messages/parsedmessage"
 	"github.com/filecoin-project/lily/tasks/messages/receipt"
 	"github.com/filecoin-project/lily/tasks/messages/receiptreturn"
+	"github.com/filecoin-project/lily/tasks/minerpenalty"
 	"github.com/filecoin-project/lily/tasks/msapprovals"
 )
 
@@ -50,9 +58,9 @@ func TestNewProcessor(t *testing.T) {
 	proc, err := New(nil, t.Name(), tasktype.AllTableTasks)
 	require.NoError(t, err)
 	require.Equal(t, t.Name(), proc.name)
-	require.Len(t, proc.actorProcessors, 26)
+	require.Len(t, proc.actorProcessors, 29)
 	require.Len(t, proc.tipsetProcessors, 11)
-	require.Len(t, proc.tipsetsProcessors, 16)
+	require.Len(t, proc.tipsetsProcessors, 28)
 	require.Len(t, proc.builtinProcessors, 1)
 
 	require.Equal(t, gasoutput.NewTask(nil), proc.tipsetsProcessors[tasktype.GasOutputs])
@@ -64,6 +72,18 @@ func TestNewProcessor(t *testing.T) {
 	require.Equal(t, vm.NewTask(nil), proc.tipsetsProcessors[tasktype.VMMessage])
 	require.Equal(t, actorevent.NewTask(nil), proc.tipsetsProcessors[tasktype.ActorEvent])
 	require.Equal(t, receiptreturn.NewTask(nil), proc.tipsetsProcessors[tasktype.ReceiptReturn])
+	require.Equal(t, actorbalance.NewTask(nil), proc.tipsetsProcessors[tasktype.ActorBalanceChange])
+	require.Equal(t, minerpenalty.NewTask(nil), proc.tipsetsProcessors[tasktype.MinerPenalty])
+	require.Equal(t, methodgasstats.NewTask(nil), proc.tipsetsProcessors[tasktype.MethodGasStats])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventDealLifecycle])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventVerifierBalance])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventVerifregAllocation])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventVerifregClaim])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventSectorLifecycle])
+	require.Equal(t, contractevent.NewTask(nil, nil), proc.tipsetsProcessors[tasktype.ContractEvent])
+	require.IsType(t, &fevmtoken.TransferTask{}, proc.tipsetsProcessors[tasktype.FEVMTokenTransfer])
+	require.IsType(t, &fevmtoken.TokenTask{}, proc.tipsetsProcessors[tasktype.FEVMToken])
+	require.Equal(t, fevmstoragechange.NewTask(nil), proc.tipsetsProcessors[tasktype.FEVMStorageChange])
 
 	require.Equal(t, message.NewTask(nil), proc.tipsetProcessors[tasktype.Message])
 	require.Equal(t, blockmessage.NewTask(nil), proc.tipsetProcessors[tasktype.BlockMessage])
@@ -81,6 +101,7 @@ func TestNewProcessor(t *testing.T) {
 	require.Equal(t, actorstate.NewTaskWithTransformer(nil, actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.LockedFundsExtractor{}), minertask.LockedFundsExtractor{}), proc.actorProcessors[tasktype.MinerLockedFund])
 	require.Equal(t, actorstate.NewTaskWithTransformer(nil, actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.SectorDealsExtractor{}), minertask.SectorDealsExtractor{}), proc.actorProcessors[tasktype.MinerSectorDeal])
 	require.Equal(t, actorstate.NewTaskWithTransformer(nil, actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.SectorEventsExtractor{}), minertask.SectorEventsExtractor{}), proc.actorProcessors[tasktype.MinerSectorEvent])
+	require.Equal(t, actorstate.NewTaskWithTransformer(nil, actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.SectorLifecycleExtractor{}), minertask.SectorLifecycleExtractor{}), proc.actorProcessors[tasktype.MinerSectorLifecycle])
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.PoStExtractor{})), proc.actorProcessors[tasktype.MinerSectorPost])
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewCustomTypedActorExtractorMap(
 		map[cid.Cid][]actorstate.ActorStateExtractor{
@@ -181,3 +202,27 @@ func TestNewProcessor(t *testing.T) {
 	rat1 := &rawtask.RawActorStateExtractor{}
 	require.Equal(t, actorstate.NewTaskWithTransformer(nil, rae1, rat1), proc.actorProcessors[tasktype.ActorState])
 }
+
+func TestNewProcessorTaskConfig(t *testing.T) {
+	filters, err := contractevent.NewFilters(map[string]config.ContractEventFilterConf{"all": {}})
+	require.NoError(t, err)
+
+	proc, err := New(nil, t.Name(), []string{tasktype.ContractEvent, tasktype.EventVerifregClaim}, WithTaskConfig(TaskConfig{
+		BuiltinActorEvents:   []string{"claim"},
+		ContractEventFilters: filters,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, contractevent.NewTask(nil, filters), proc.tipsetsProcessors[tasktype.ContractEvent])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.tipsetsProcessors[tasktype.EventVerifregClaim])
+
+	_, err = New(nil, t.Name(), []string{tasktype.EventVerifregClaim}, WithTaskConfig(TaskConfig{
+		BuiltinActorEvents: []string{"not-an-event"},
+	}))
+	require.Error(t, err)
+
+	// the built-in actor events are only loaded for the tasks that use them
+	_, err = New(nil, t.Name(), []string{tasktype.BlockHeader}, WithTaskConfig(TaskConfig{
+		BuiltinActorEvents: []string{"not-an-event"},
+	}))
+	require.NoError(t, err)
+}
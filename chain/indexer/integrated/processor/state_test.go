@@ -12,17 +12,20 @@ import (
 	"github.com/filecoin-project/lily/chain/actors/builtin/market"
 	"github.com/filecoin-project/lily/chain/actors/builtin/miner"
 	"github.com/filecoin-project/lily/chain/actors/builtin/multisig"
+	"github.com/filecoin-project/lily/chain/actors/builtin/paych"
 	"github.com/filecoin-project/lily/chain/actors/builtin/power"
 	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
 	"github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/tasks/actorbalance"
 	"github.com/filecoin-project/lily/tasks/actorstate"
 	datacaptask "github.com/filecoin-project/lily/tasks/actorstate/datacap"
 	inittask "github.com/filecoin-project/lily/tasks/actorstate/init_"
 	markettask "github.com/filecoin-project/lily/tasks/actorstate/market"
 	minertask "github.com/filecoin-project/lily/tasks/actorstate/miner"
 	multisigtask "github.com/filecoin-project/lily/tasks/actorstate/multisig"
+	paychtask "github.com/filecoin-project/lily/tasks/actorstate/paych"
 	powertask "github.com/filecoin-project/lily/tasks/actorstate/power"
 	rawtask "github.com/filecoin-project/lily/tasks/actorstate/raw"
 	rewardtask "github.com/filecoin-project/lily/tasks/actorstate/reward"
@@ -32,15 +35,21 @@ import (
 	"github.com/filecoin-project/lily/tasks/blocks/parents"
 	"github.com/filecoin-project/lily/tasks/chaineconomics"
 	"github.com/filecoin-project/lily/tasks/consensus"
+	"github.com/filecoin-project/lily/tasks/fevm/contractevent"
+	fevmstoragechange "github.com/filecoin-project/lily/tasks/fevm/storagechange"
+	fevmtoken "github.com/filecoin-project/lily/tasks/fevm/token"
 	"github.com/filecoin-project/lily/tasks/indexer"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalmessage"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalparsedmessage"
 	"github.com/filecoin-project/lily/tasks/messages/blockmessage"
+	"github.com/filecoin-project/lily/tasks/messages/builtinactorevent"
 	"github.com/filecoin-project/lily/tasks/messages/gaseconomy"
 	"github.com/filecoin-project/lily/tasks/messages/gasoutput"
 	"github.com/filecoin-project/lily/tasks/messages/message"
+	"github.com/filecoin-project/lily/tasks/messages/methodgasstats"
 	"github.com/filecoin-project/lily/tasks/messages/parsedmessage"
 	"github.com/filecoin-project/lily/tasks/messages/receipt"
+	"github.com/filecoin-project/lily/tasks/minerpenalty"
 	"github.com/filecoin-project/lily/tasks/msapprovals"
 )
 
@@ -82,7 +91,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -103,6 +112,11 @@ func TestMakeProcessorsActors(t *testing.T) {
 				extractor:   actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.SectorEventsExtractor{}),
 				transformer: minertask.SectorEventsExtractor{},
 			},
+			{
+				taskName:    tasktype.MinerSectorLifecycle,
+				extractor:   actorstate.NewTypedActorExtractorMap(miner.AllCodes(), minertask.SectorLifecycleExtractor{}),
+				transformer: minertask.SectorLifecycleExtractor{},
+			},
 			{
 				taskName: tasktype.MinerSectorInfoV7,
 				extractor: actorstate.NewCustomTypedActorExtractorMap(
@@ -163,7 +177,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases2 {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTaskWithTransformer(nil, tc.extractor, tc.transformer), proc.ActorProcessors[tc.taskName])
@@ -187,7 +201,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -207,7 +221,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -227,7 +241,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -251,7 +265,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -271,7 +285,31 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
+				require.NoError(t, err)
+				require.Len(t, proc.ActorProcessors, 1)
+				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
+			})
+		}
+	})
+
+	t.Run("payment channel extractors", func(t *testing.T) {
+		testCases := []struct {
+			taskName  string
+			extractor actorstate.ActorExtractorMap
+		}{
+			{
+				taskName:  tasktype.PaychState,
+				extractor: actorstate.NewTypedActorExtractorMap(paych.AllCodes(), paychtask.PaychStateExtractor{}),
+			},
+			{
+				taskName:  tasktype.PaychLaneState,
+				extractor: actorstate.NewTypedActorExtractorMap(paych.AllCodes(), paychtask.PaychLaneStateExtractor{}),
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.taskName, func(t *testing.T) {
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -321,7 +359,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -341,7 +379,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		}
 		for _, tc := range testCases {
 			t.Run(tc.taskName, func(t *testing.T) {
-				proc, err := processor.MakeProcessors(nil, []string{tc.taskName})
+				proc, err := processor.MakeProcessors(nil, []string{tc.taskName}, processor.TaskConfig{})
 				require.NoError(t, err)
 				require.Len(t, proc.ActorProcessors, 1)
 				require.Equal(t, actorstate.NewTask(nil, tc.extractor), proc.ActorProcessors[tc.taskName])
@@ -351,7 +389,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 
 	t.Run("raw actors", func(t *testing.T) {
 		t.Run("actor", func(t *testing.T) {
-			proc, err := processor.MakeProcessors(nil, []string{tasktype.Actor})
+			proc, err := processor.MakeProcessors(nil, []string{tasktype.Actor}, processor.TaskConfig{})
 			require.NoError(t, err)
 			rae := &actorstate.RawActorExtractorMap{}
 			rae.Register(&rawtask.RawActorExtractor{})
@@ -362,7 +400,7 @@ func TestMakeProcessorsActors(t *testing.T) {
 		})
 
 		t.Run("actor state", func(t *testing.T) {
-			proc, err := processor.MakeProcessors(nil, []string{tasktype.ActorState})
+			proc, err := processor.MakeProcessors(nil, []string{tasktype.ActorState}, processor.TaskConfig{})
 			require.NoError(t, err)
 			rae := &actorstate.RawActorExtractorMap{}
 			rae.Register(&rawtask.RawActorStateExtractor{})
@@ -384,7 +422,7 @@ func TestMakeProcessorsTipSet(t *testing.T) {
 		tasktype.ChainConsensus,
 		tasktype.MessageGasEconomy,
 	}
-	proc, err := processor.MakeProcessors(nil, tasks)
+	proc, err := processor.MakeProcessors(nil, tasks, processor.TaskConfig{})
 	require.NoError(t, err)
 	require.Len(t, proc.TipsetProcessors, len(tasks))
 
@@ -406,8 +444,20 @@ func TestMakeProcessorsTipSets(t *testing.T) {
 		tasktype.InternalMessage,
 		tasktype.InternalParsedMessage,
 		tasktype.MultisigApproval,
+		tasktype.ActorBalanceChange,
+		tasktype.MinerPenalty,
+		tasktype.MethodGasStats,
+		tasktype.EventDealLifecycle,
+		tasktype.EventVerifierBalance,
+		tasktype.EventVerifregAllocation,
+		tasktype.EventVerifregClaim,
+		tasktype.EventSectorLifecycle,
+		tasktype.ContractEvent,
+		tasktype.FEVMTokenTransfer,
+		tasktype.FEVMToken,
+		tasktype.FEVMStorageChange,
 	}
-	proc, err := processor.MakeProcessors(nil, tasks)
+	proc, err := processor.MakeProcessors(nil, tasks, processor.TaskConfig{})
 	require.NoError(t, err)
 	require.Len(t, proc.TipsetsProcessors, len(tasks))
 
@@ -417,10 +467,22 @@ func TestMakeProcessorsTipSets(t *testing.T) {
 	require.Equal(t, internalmessage.NewTask(nil), proc.TipsetsProcessors[tasktype.InternalMessage])
 	require.Equal(t, internalparsedmessage.NewTask(nil), proc.TipsetsProcessors[tasktype.InternalParsedMessage])
 	require.Equal(t, msapprovals.NewTask(nil), proc.TipsetsProcessors[tasktype.MultisigApproval])
+	require.Equal(t, actorbalance.NewTask(nil), proc.TipsetsProcessors[tasktype.ActorBalanceChange])
+	require.Equal(t, minerpenalty.NewTask(nil), proc.TipsetsProcessors[tasktype.MinerPenalty])
+	require.Equal(t, methodgasstats.NewTask(nil), proc.TipsetsProcessors[tasktype.MethodGasStats])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.TipsetsProcessors[tasktype.EventDealLifecycle])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.TipsetsProcessors[tasktype.EventVerifierBalance])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.TipsetsProcessors[tasktype.EventVerifregAllocation])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.TipsetsProcessors[tasktype.EventVerifregClaim])
+	require.IsType(t, &builtinactorevent.TypedTask{}, proc.TipsetsProcessors[tasktype.EventSectorLifecycle])
+	require.Equal(t, contractevent.NewTask(nil, nil), proc.TipsetsProcessors[tasktype.ContractEvent])
+	require.IsType(t, &fevmtoken.TransferTask{}, proc.TipsetsProcessors[tasktype.FEVMTokenTransfer])
+	require.IsType(t, &fevmtoken.TokenTask{}, proc.TipsetsProcessors[tasktype.FEVMToken])
+	require.Equal(t, fevmstoragechange.NewTask(nil), proc.TipsetsProcessors[tasktype.FEVMStorageChange])
 }
 
 func TestMakeProcessorsReport(t *testing.T) {
-	proc, err := processor.MakeProcessors(nil, []string{processor.BuiltinTaskName})
+	proc, err := processor.MakeProcessors(nil, []string{processor.BuiltinTaskName}, processor.TaskConfig{})
 	require.NoError(t, err)
 	require.Len(t, proc.ReportProcessors, 1)
 	require.Equal(t, indexer.NewTask(nil), proc.ReportProcessors[processor.BuiltinTaskName])
@@ -429,7 +491,7 @@ func TestMakeProcessorsReport(t *testing.T) {
 func TestMakeProcessorsInvalidTaskName(t *testing.T) {
 	t.Run("single invalid name", func(t *testing.T) {
 		invalidTask := "invalid_task_name"
-		proc, err := processor.MakeProcessors(nil, []string{invalidTask})
+		proc, err := processor.MakeProcessors(nil, []string{invalidTask}, processor.TaskConfig{})
 		require.Error(t, err)
 		require.Nil(t, proc)
 	})
@@ -437,11 +499,11 @@ func TestMakeProcessorsInvalidTaskName(t *testing.T) {
 	t.Run("mix of invalid and valid", func(t *testing.T) {
 		validName := tasktype.Message
 		invalidName := "invalid_task_name"
-		proc, err := processor.MakeProcessors(nil, []string{validName, invalidName})
+		proc, err := processor.MakeProcessors(nil, []string{validName, invalidName}, processor.TaskConfig{})
 		require.Error(t, err)
 		require.Nil(t, proc)
 
-		proc, err = processor.MakeProcessors(nil, []string{invalidName, validName})
+		proc, err = processor.MakeProcessors(nil, []string{invalidName, validName}, processor.TaskConfig{})
 		require.Error(t, err)
 		require.Nil(t, proc)
 	})
@@ -449,10 +511,10 @@ func TestMakeProcessorsInvalidTaskName(t *testing.T) {
 
 func TestMakeProcessorsAllTasks(t *testing.T) {
 	// If this test fails it indicates a new processor and/or task name was added and test should be created for it in one of the above test cases.
-	proc, err := processor.MakeProcessors(nil, append(tasktype.AllTableTasks, processor.BuiltinTaskName))
+	proc, err := processor.MakeProcessors(nil, append(tasktype.AllTableTasks, processor.BuiltinTaskName), processor.TaskConfig{})
 	require.NoError(t, err)
-	require.Len(t, proc.ActorProcessors, 26)
+	require.Len(t, proc.ActorProcessors, 29)
 	require.Len(t, proc.TipsetProcessors, 11)
-	require.Len(t, proc.TipsetsProcessors, 16)
+	require.Len(t, proc.TipsetsProcessors, 28)
 	require.Len(t, proc.ReportProcessors, 1)
 }
@@ -2,7 +2,9 @@ package tipset
 
 import (
 	"context"
+	"sync"
 
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/tasks"
 
 	"github.com/filecoin-project/lotus/chain/types"
@@ -21,11 +23,34 @@ type Indexer interface {
 
 var _ IndexerBuilder = (*Builder)(nil)
 
-func NewBuilder(node tasks.DataSource, name string) IndexerBuilder {
-	return &Builder{api: node, name: name}
+// BuilderOpt configures the TipSetIndexer's built by a Builder.
+type BuilderOpt func(ti *TipSetIndexer)
+
+// WithRetryPolicies sets the policies used to retry tasks that fail while indexing a tipset.
+func WithRetryPolicies(r processor.RetryPolicies) BuilderOpt {
+	return func(ti *TipSetIndexer) {
+		ti.retry = r
+	}
+}
+
+// WithTaskConfig sets the configuration of the tasks run while indexing a tipset.
+func WithTaskConfig(c processor.TaskConfig) BuilderOpt {
+	return func(ti *TipSetIndexer) {
+		ti.tasks = c
+	}
+}
+
+func NewBuilder(node tasks.DataSource, name string, opts ...BuilderOpt) IndexerBuilder {
+	b := &Builder{api: node, name: name}
+	for _, opt := range opts {
+		b.add(opt)
+	}
+	return b
 }
 
+// Builder is safe for concurrent use, allowing a single Manager to index tipsets in parallel.
 type Builder struct {
+	mu      sync.Mutex
 	options []func(ti *TipSetIndexer)
 	api     tasks.DataSource
 	name    string
@@ -36,6 +61,8 @@ func (b *Builder) Name() string {
 }
 
 func (b *Builder) add(cb func(ti *TipSetIndexer)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	b.options = append(b.options, cb)
 }
 
@@ -59,9 +86,11 @@ func (b *Builder) Build() (Indexer, error) {
 		node: b.api,
 	}
 
+	b.mu.Lock()
 	for _, opt := range b.options {
 		opt(ti)
 	}
+	b.mu.Unlock()
 
 	if err := ti.init(); err != nil {
 		return nil, err
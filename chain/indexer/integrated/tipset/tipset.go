@@ -31,6 +31,8 @@ type TipSetIndexer struct {
 	node      taskapi.DataSource
 	taskNames []string
 	Interval  int
+	retry     processor.RetryPolicies
+	tasks     processor.TaskConfig
 
 	processor *processor.StateProcessor
 }
@@ -41,7 +43,7 @@ func (ti *TipSetIndexer) init() error {
 		return err
 	}
 
-	ti.processor, err = processor.New(ti.node, ti.name, indexerTasks)
+	ti.processor, err = processor.New(ti.node, ti.name, indexerTasks, processor.WithRetryPolicies(ti.retry), processor.WithTaskConfig(ti.tasks))
 	if err != nil {
 		return err
 	}
@@ -162,6 +164,7 @@ func (ti *TipSetIndexer) TipSet(ctx context.Context, ts *types.TipSet) (chan *Re
 					res.Report[idx].Task = res.Task
 					res.Report[idx].StartedAt = res.StartedAt
 					res.Report[idx].CompletedAt = res.CompletedAt
+					res.Report[idx].Attempts = res.Attempts
 
 					if err := res.Report[idx].ErrorsDetected; err != nil {
 						// because error is just an interface it may hold a value of any concrete type that implements it, and if
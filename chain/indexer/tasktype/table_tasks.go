@@ -57,6 +57,21 @@ const (
 	MinerActorDump                 = "miner_actor_dumps"
 	BuiltInActorEvent              = "builtin_actor_event"
 	MinerCronFee                   = "miner_cron_fee"
+	PaychState                     = "paych_state"
+	PaychLaneState                 = "paych_lane_state"
+	ActorBalanceChange             = "actor_balance_changes"
+	MinerSectorLifecycle           = "miner_sector_lifecycle"
+	MinerPenalty                   = "miner_penalties"
+	MethodGasStats                 = "method_gas_stats"
+	EventDealLifecycle             = "event_deal_lifecycle"
+	EventVerifierBalance           = "event_verifier_balance"
+	EventVerifregAllocation        = "event_verifreg_allocation"
+	EventVerifregClaim             = "event_verifreg_claim"
+	EventSectorLifecycle           = "event_sector_lifecycle"
+	ContractEvent                  = "contract_events"
+	FEVMTokenTransfer              = "fevm_token_transfers"
+	FEVMToken                      = "fevm_tokens"
+	FEVMStorageChange              = "fevm_storage_changes"
 )
 
 var AllTableTasks = []string{
@@ -115,6 +130,21 @@ var AllTableTasks = []string{
 	BuiltInActorEvent,
 	MinerSectorDealV2,
 	MinerCronFee,
+	PaychState,
+	PaychLaneState,
+	ActorBalanceChange,
+	MinerSectorLifecycle,
+	MinerPenalty,
+	MethodGasStats,
+	EventDealLifecycle,
+	EventVerifierBalance,
+	EventVerifregAllocation,
+	EventVerifregClaim,
+	EventSectorLifecycle,
+	ContractEvent,
+	FEVMTokenTransfer,
+	FEVMToken,
+	FEVMStorageChange,
 }
 
 var TableLookup = map[string]struct{}{
@@ -173,6 +203,21 @@ var TableLookup = map[string]struct{}{
 	BuiltInActorEvent:              {},
 	MinerSectorDealV2:              {},
 	MinerCronFee:                   {},
+	PaychState:                     {},
+	PaychLaneState:                 {},
+	ActorBalanceChange:             {},
+	MinerSectorLifecycle:           {},
+	MinerPenalty:                   {},
+	MethodGasStats:                 {},
+	EventDealLifecycle:             {},
+	EventVerifierBalance:           {},
+	EventVerifregAllocation:        {},
+	EventVerifregClaim:             {},
+	EventSectorLifecycle:           {},
+	ContractEvent:                  {},
+	FEVMTokenTransfer:              {},
+	FEVMToken:                      {},
+	FEVMStorageChange:              {},
 }
 
 var TableComment = map[string]string{
@@ -231,6 +276,21 @@ var TableComment = map[string]string{
 	BuiltInActorEvent:              ``,
 	MinerSectorDealV2:              ``,
 	MinerCronFee:                   ``,
+	PaychState:                     `PaychState contains the state of payment channel actors, recorded each time the state of a channel changes.`,
+	PaychLaneState:                 `PaychLaneState contains the state of payment channel lanes, recorded when a lane is added or changed.`,
+	ActorBalanceChange:             `ActorBalanceChange contains the balance of each actor before and after every epoch in which its balance changed.`,
+	MinerSectorLifecycle:           `MinerSectorLifecycle contains the lifecycle state transitions of sectors, one row for each state a sector entered. A sector exits a state at the entered_epoch of its next transition, which miner_sector_lifecycle_view derives in the database.`,
+	MinerPenalty:                   `MinerPenalty contains the penalties paid by miners at each epoch, by type of penalty.`,
+	MethodGasStats:                 `MethodGasStats contains the gas used by the messages executed at each epoch, by actor family and method.`,
+	EventDealLifecycle:             `EventDealLifecycle contains the deal-published, deal-activated, deal-terminated and deal-completed events emitted by the market actor.`,
+	EventVerifierBalance:           `EventVerifierBalance contains the verifier-balance events emitted by the verified registry actor.`,
+	EventVerifregAllocation:        `EventVerifregAllocation contains the allocation and allocation-removed events emitted by the verified registry actor.`,
+	EventVerifregClaim:             `EventVerifregClaim contains the claim, claim-updated and claim-removed events emitted by the verified registry actor.`,
+	EventSectorLifecycle:           `EventSectorLifecycle contains the sector-precommitted, sector-activated, sector-updated and sector-terminated events emitted by miner actors.`,
+	ContractEvent:                  `ContractEvent contains the events emitted by FEVM contracts that matched a contract event filter of the lily config.`,
+	FEVMTokenTransfer:              `FEVMTokenTransfer contains the Transfer and Approval logs of ERC-20 and ERC-721 token contracts.`,
+	FEVMToken:                      `FEVMToken contains the contracts identified as tokens when they were first seen emitting a Transfer or Approval log.`,
+	FEVMStorageChange:              `FEVMStorageChange contains the changes to the storage slots of EVM actors.`,
 }
 
 var TableFieldComments = map[string]map[string]string{
@@ -462,4 +522,149 @@ var TableFieldComments = map[string]map[string]string{
 	BuiltInActorEvent: {},
 	MinerSectorDealV2: {},
 	MinerCronFee:      {},
+	PaychState: {
+		"From":       "Address of the channel owner, who has funded the channel.",
+		"SettlingAt": "Epoch at which the channel can be collected, zero if the channel is not settling.",
+		"To":         "Address of the recipient of payouts from the channel.",
+		"ToSend":     "Amount of attoFIL successfully redeemed through the channel, paid out on Collect.",
+	},
+	PaychLaneState: {
+		"Nonce":    "Nonce of the last voucher redeemed in the lane.",
+		"Redeemed": "Total amount of attoFIL redeemed by vouchers in the lane.",
+	},
+	ActorBalanceChange: {
+		"Address":      "ID address of the actor.",
+		"BalanceDelta": "Difference between NewBalance and OldBalance in attoFIL.",
+		"Causes":       "Flows the balance changed through: message, gas, reward, burn or vesting.",
+		"MessageCids":  "CIDs of the messages, including implicit messages, that transferred value to or from the actor, or whose gas fees it paid or received.",
+		"NewBalance":   "Balance of the actor in attoFIL after the change.",
+		"OldBalance":   "Balance of the actor in attoFIL before the change.",
+	},
+	MinerSectorLifecycle: {
+		"Cause":         "Event that caused the transition, as recorded in miner_sector_events.",
+		"EnteredEpoch":  "Epoch at which the sector entered State.",
+		"PreviousState": "Lifecycle state the sector exited, empty if the sector was not known before it entered State.",
+		"State":         "Lifecycle state the sector entered: PRECOMMITTED, PRECOMMIT_EXPIRED, ACTIVE, FAULTY, RECOVERING or TERMINATED.",
+	},
+	MinerPenalty: {
+		"Amount":      "Amount of the penalty in attoFIL.",
+		"MessageCids": "CIDs of the messages, including implicit messages, in whose execution the penalty was paid.",
+		"PenaltyType": "Type of the penalty: FAULT_FEE, PRECOMMIT_EXPIRY, TERMINATION_FEE, POST_DISPUTE, CONSENSUS_FAULT, FEE_DEBT_REPAYMENT, FEE_DEBT_INCURRED, DAILY_FEE, NETWORK_FEE or OTHER.",
+	},
+	MethodGasStats: {
+		"ActorFamily":        "Family of the actor the messages were sent to, for example storageminer.",
+		"BaseFeeBurn":        "Total base fee burnt by the messages in attoFIL.",
+		"GasUsedP50":         "Median of the gas used by the messages.",
+		"GasUsedP90":         "90th percentile of the gas used by the messages.",
+		"GasUsedP99":         "99th percentile of the gas used by the messages.",
+		"MessageCount":       "Number of messages.",
+		"Method":             "Method number called by the messages.",
+		"MethodName":         "Name of the method, empty if it could not be determined.",
+		"OverEstimationBurn": "Total overestimation burn of the messages in attoFIL.",
+	},
+	EventDealLifecycle: {
+		"Client":     "Address of the client of the deal.",
+		"DealID":     "Identifier of the deal.",
+		"Emitter":    "Address of the actor that emitted the event.",
+		"EventIdx":   "Index of the event among the events emitted in the tipset.",
+		"EventType":  "Type of the event, one of deal-published, deal-activated, deal-terminated or deal-completed.",
+		"Height":     "Epoch at which the event was emitted.",
+		"MessageCid": "CID of the message that emitted the event.",
+		"Provider":   "Address of the provider of the deal.",
+	},
+	EventVerifierBalance: {
+		"Balance":    "Datacap the verifier may allocate after the change, in bytes.",
+		"Emitter":    "Address of the actor that emitted the event.",
+		"EventIdx":   "Index of the event among the events emitted in the tipset.",
+		"Height":     "Epoch at which the event was emitted.",
+		"MessageCid": "CID of the message that emitted the event.",
+		"Verifier":   "Address of the verifier.",
+	},
+	EventVerifregAllocation: {
+		"AllocationID": "Identifier of the allocation.",
+		"Client":       "Address of the client of the allocation.",
+		"Emitter":      "Address of the actor that emitted the event.",
+		"EventIdx":     "Index of the event among the events emitted in the tipset.",
+		"EventType":    "Type of the event, allocation or allocation-removed.",
+		"Expiration":   "Epoch by which the piece must be committed to a sector.",
+		"Height":       "Epoch at which the event was emitted.",
+		"MessageCid":   "CID of the message that emitted the event.",
+		"PieceCid":     "CID of the piece.",
+		"PieceSize":    "Size of the piece in bytes.",
+		"Provider":     "Address of the provider the allocation is made to.",
+		"TermMax":      "Maximum number of epochs the piece may be stored for.",
+		"TermMin":      "Minimum number of epochs the piece must be stored for.",
+	},
+	EventVerifregClaim: {
+		"ClaimID":    "Identifier of the claim.",
+		"Client":     "Address of the client of the claim.",
+		"Emitter":    "Address of the actor that emitted the event.",
+		"EventIdx":   "Index of the event among the events emitted in the tipset.",
+		"EventType":  "Type of the event, claim, claim-updated or claim-removed.",
+		"Height":     "Epoch at which the event was emitted.",
+		"MessageCid": "CID of the message that emitted the event.",
+		"PieceCid":   "CID of the piece.",
+		"PieceSize":  "Size of the piece in bytes.",
+		"Provider":   "Address of the provider storing the piece.",
+		"Sector":     "Number of the sector the piece is stored in.",
+		"TermMax":    "Maximum number of epochs the piece may be stored for.",
+		"TermMin":    "Minimum number of epochs the piece must be stored for.",
+		"TermStart":  "Epoch at which the piece was committed.",
+	},
+	EventSectorLifecycle: {
+		"EventIdx":     "Index of the event among the events emitted in the tipset.",
+		"EventType":    "Type of the event, one of sector-precommitted, sector-activated, sector-updated or sector-terminated.",
+		"Height":       "Epoch at which the event was emitted.",
+		"MessageCid":   "CID of the message that emitted the event.",
+		"MinerID":      "Address of the miner that emitted the event.",
+		"PieceCids":    "CIDs of the pieces in the sector, in the order they were emitted.",
+		"PieceSizes":   "Sizes of the pieces in the sector in bytes, in the same order as PieceCids.",
+		"SectorNumber": "Number of the sector.",
+		"UnsealedCid":  "CID of the unsealed data of the sector, empty for sectors without data and for events other than sector-activated and sector-updated.",
+	},
+	ContractEvent: {
+		"Data":              "Data of the event in hex.",
+		"DecodedArgs":       "Arguments of the event by name, empty if it could not be decoded with the ABI of the filter.",
+		"Emitter":           "Address of the contract that emitted the event.",
+		"EmitterEthAddress": "Address of the contract in ETH.",
+		"EventIdx":          "Index of the event among the events emitted in the tipset.",
+		"EventName":         "Name of the event, empty if it could not be decoded with the ABI of the filter.",
+		"Filter":            "Name of the filter the event matched.",
+		"Height":            "Epoch at which the event was emitted.",
+		"MessageCid":        "CID of the message that emitted the event.",
+		"Topic0":            "First topic of the event, the keccak256 hash of the event signature for non-anonymous events.",
+		"Topic1":            "Second topic of the event.",
+		"Topic2":            "Third topic of the event.",
+		"Topic3":            "Fourth topic of the event.",
+	},
+	FEVMTokenTransfer: {
+		"Amount":          "Amount of tokens transferred or approved, ERC-20 only.",
+		"EventType":       "Type of the log, TRANSFER or APPROVAL.",
+		"From":            "ETH address tokens are transferred from, or of the owner for approvals.",
+		"Height":          "Height message was executed at.",
+		"LogIndex":        "Index of the log among the logs emitted by the transaction.",
+		"Message":         "Message CID of the transaction.",
+		"To":              "ETH address tokens are transferred to, or of the approved spender for approvals.",
+		"TokenAddress":    "ETH address of the token contract.",
+		"TokenID":         "Identifier of the token transferred or approved, ERC-721 only.",
+		"TokenStandard":   "Standard of the token, ERC20 or ERC721, as determined by the number of topics of the log.",
+		"TransactionHash": "Hash of the transaction that emitted the log.",
+	},
+	FEVMToken: {
+		"ActorID":       "Filecoin address of the token contract.",
+		"Decimals":      "Decimals returned by the decimals() method of the contract, nil if it has none.",
+		"Height":        "Height at which the token was identified.",
+		"Name":          "Name returned by the name() method of the contract, empty if it has none.",
+		"Symbol":        "Symbol returned by the symbol() method of the contract, empty if it has none.",
+		"TokenAddress":  "ETH address of the token contract.",
+		"TokenStandard": "Standard of the token, ERC20 or ERC721.",
+	},
+	FEVMStorageChange: {
+		"ActorID":    "Actor address.",
+		"EthAddress": "Actor Address in ETH.",
+		"Height":     "Height of the state the change is found in.",
+		"NewValue":   "Value of the slot after the change, as a 32 byte hex string. Slots that are not set hold zero.",
+		"OldValue":   "Value of the slot before the change, as a 32 byte hex string. Slots that are not set hold zero.",
+		"Slot":       "Storage slot, as a 32 byte hex string.",
+	},
 }
@@ -11,6 +11,8 @@ const (
 	ActorStatesMarketTask   = "actorstatesmarket"   // task that only extracts market actor states (but not the raw state)
 	ActorStatesMultisigTask = "actorstatesmultisig" // task that only extracts multisig actor states (but not the raw state)
 	ActorStatesVerifreg     = "actorstatesverifreg" // task that only extracts verified registry actor states (but not the raw state)
+	ActorStatesPaychTask    = "actorstatespaych"    // task that only extracts payment channel actor states (but not the raw state)
+	ActorBalancesTask       = "actorbalances"       // task that extracts the balance changes of actors and the messages that caused them
 	BlocksTask              = "blocks"              // task that extracts block data
 	MessagesTask            = "messages"            // task that extracts message data
 	ChainEconomicsTask      = "chaineconomics"      // task that extracts chain economics data
@@ -41,6 +43,8 @@ var TaskLookup = map[string][]string{
 		MinerPreCommitInfo,
 		MinerPreCommitInfoV9,
 		MinerSectorEvent,
+		MinerSectorLifecycle,
+		MinerPenalty,
 		MinerCurrentDeadlineInfo,
 		MinerFeeDebt,
 		MinerLockedFund,
@@ -65,6 +69,13 @@ var TaskLookup = map[string][]string{
 		VerifiedRegistryClaim,
 		DataCapBalance,
 	},
+	ActorStatesPaychTask: {
+		PaychState,
+		PaychLaneState,
+	},
+	ActorBalancesTask: {
+		ActorBalanceChange,
+	},
 	BlocksTask: {
 		BlockHeader,
 		BlockParent,
@@ -76,11 +87,17 @@ var TaskLookup = map[string][]string{
 		Receipt,
 		GasOutputs,
 		MessageGasEconomy,
+		MethodGasStats,
 		BlockMessage,
 		ActorEvent,
 		MessageParam,
 		ReceiptReturn,
 		BuiltInActorEvent,
+		EventDealLifecycle,
+		EventVerifierBalance,
+		EventVerifregAllocation,
+		EventVerifregClaim,
+		EventSectorLifecycle,
 	},
 	ChainEconomicsTask: {
 		ChainEconomics,
@@ -104,6 +121,10 @@ var TaskLookup = map[string][]string{
 		FEVMTransaction,
 		FEVMContract,
 		FEVMTrace,
+		ContractEvent,
+		FEVMTokenTransfer,
+		FEVMToken,
+		FEVMStorageChange,
 	},
 	ActorDump: {
 		FEVMActorDump,
@@ -30,8 +30,8 @@ func TestMakeTaskNamesAlias(t *testing.T) {
 			taskAlias: tasktype.ActorStatesMinerTask,
 			tasks: []string{tasktype.MinerSectorDeal, tasktype.MinerSectorInfoV7, tasktype.MinerSectorInfoV1_6,
 				tasktype.MinerSectorPost, tasktype.MinerPreCommitInfo, tasktype.MinerPreCommitInfoV9, tasktype.MinerSectorEvent,
-				tasktype.MinerCurrentDeadlineInfo, tasktype.MinerFeeDebt, tasktype.MinerLockedFund, tasktype.MinerInfo,
-				tasktype.MinerBeneficiary, tasktype.MinerCronFee},
+				tasktype.MinerSectorLifecycle, tasktype.MinerCurrentDeadlineInfo, tasktype.MinerFeeDebt, tasktype.MinerLockedFund, tasktype.MinerInfo,
+				tasktype.MinerBeneficiary, tasktype.MinerCronFee, tasktype.MinerPenalty},
 		},
 		{
 			taskAlias: tasktype.ActorStatesInitTask,
@@ -49,14 +49,23 @@ func TestMakeTaskNamesAlias(t *testing.T) {
 			taskAlias: tasktype.ActorStatesVerifreg,
 			tasks:     []string{tasktype.VerifiedRegistryVerifier, tasktype.VerifiedRegistryVerifiedClient, tasktype.DataCapBalance, tasktype.VerifiedRegistryClaim},
 		},
+		{
+			taskAlias: tasktype.ActorStatesPaychTask,
+			tasks:     []string{tasktype.PaychState, tasktype.PaychLaneState},
+		},
+		{
+			taskAlias: tasktype.ActorBalancesTask,
+			tasks:     []string{tasktype.ActorBalanceChange},
+		},
 		{
 			taskAlias: tasktype.BlocksTask,
 			tasks:     []string{tasktype.BlockHeader, tasktype.BlockParent, tasktype.DrandBlockEntrie},
 		},
 		{
 			taskAlias: tasktype.MessagesTask,
-			tasks: []string{tasktype.Message, tasktype.ParsedMessage, tasktype.Receipt, tasktype.GasOutputs, tasktype.MessageGasEconomy, tasktype.BlockMessage, tasktype.ActorEvent, tasktype.MessageParam, tasktype.ReceiptReturn,
-				tasktype.BuiltInActorEvent},
+			tasks: []string{tasktype.Message, tasktype.ParsedMessage, tasktype.Receipt, tasktype.GasOutputs, tasktype.MessageGasEconomy, tasktype.MethodGasStats, tasktype.BlockMessage, tasktype.ActorEvent, tasktype.MessageParam, tasktype.ReceiptReturn,
+				tasktype.BuiltInActorEvent, tasktype.EventDealLifecycle, tasktype.EventVerifierBalance, tasktype.EventVerifregAllocation,
+				tasktype.EventVerifregClaim, tasktype.EventSectorLifecycle},
 		},
 		{
 			taskAlias: tasktype.ChainEconomicsTask,
@@ -102,7 +111,7 @@ func TestMakeAllTaskAliasNames(t *testing.T) {
 }
 
 func TestMakeAllTaskNames(t *testing.T) {
-	const TotalTableTasks = 55
+	const TotalTableTasks = 70
 	actual, err := tasktype.MakeTaskNames(tasktype.AllTableTasks)
 	require.NoError(t, err)
 	// if this test fails it means a new task name was added, update the above test
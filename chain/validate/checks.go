@@ -0,0 +1,285 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lily/chain/actors/builtin/power"
+	"github.com/filecoin-project/lily/lens"
+	powermodel "github.com/filecoin-project/lily/model/actors/power"
+	chainmodel "github.com/filecoin-project/lily/model/chain"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const (
+	// BurntFilCheck compares chain_economics.burnt_fil with the balance of the burnt funds actor and checks that its
+	// increase covers the gas burnt by the messages in derived_gas_outputs.
+	BurntFilCheck = "burnt_fil"
+	// MessagesCheck compares the number of messages in the messages and block_messages tables with each other and
+	// with the messages of the tipset.
+	MessagesCheck = "messages"
+	// ChainPowerCheck compares chain_powers and power_actor_claims with the state of the power actor and checks that
+	// the committed power totals equal the sum of the miner claims.
+	ChainPowerCheck = "chain_powers"
+)
+
+// AllChecks are the names of the checks a validator runs when none are specified.
+var AllChecks = []string{
+	BurntFilCheck,
+	MessagesCheck,
+	ChainPowerCheck,
+}
+
+type checkFunc func(ctx context.Context, c *checkContext) (visor.ValidationReportList, error)
+
+var checkFuncs = map[string]checkFunc{
+	BurntFilCheck:   checkBurntFil,
+	MessagesCheck:   checkMessages,
+	ChainPowerCheck: checkChainPower,
+}
+
+// checkContext holds the tipset being validated and the sources it is validated against.
+type checkContext struct {
+	node        lens.API
+	db          *storage.Database
+	ts          *types.TipSet // tipset being validated
+	pts         *types.TipSet // parent of ts, whose messages were executed to produce the parent state of ts
+	check       string
+	reporter    string
+	validatedAt time.Time
+}
+
+func (c *checkContext) newReport(status, subject, expected, actual, msg string) *visor.ValidationReport {
+	return &visor.ValidationReport{
+		Height:      int64(c.ts.Height()),
+		CheckName:   c.check,
+		Subject:     subject,
+		StateRoot:   c.ts.ParentState().String(),
+		Status:      status,
+		Expected:    expected,
+		Actual:      actual,
+		Message:     msg,
+		Reporter:    c.reporter,
+		ValidatedAt: c.validatedAt,
+	}
+}
+
+func (c *checkContext) discrepancy(subject, expected, actual, msg string) *visor.ValidationReport {
+	return c.newReport(visor.ValidationStatusDiscrepancy, subject, expected, actual, msg)
+}
+
+func (c *checkContext) failure(err error) *visor.ValidationReport {
+	return c.newReport(visor.ValidationStatusError, "", "", "", err.Error())
+}
+
+// compare returns a discrepancy for subject if expected and actual differ.
+func (c *checkContext) compare(subject string, expected, actual interface{}, msg string) visor.ValidationReportList {
+	e, a := fmt.Sprint(expected), fmt.Sprint(actual)
+	if e == a {
+		return nil
+	}
+	return visor.ValidationReportList{c.discrepancy(subject, e, a, msg)}
+}
+
+func checkBurntFil(ctx context.Context, c *checkContext) (visor.ValidationReportList, error) {
+	burnt, found, err := burntFil(ctx, c.db, c.ts)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return visor.ValidationReportList{c.discrepancy("chain_economics", "", "", "no chain_economics row for tipset")}, nil
+	}
+
+	act, err := c.node.StateGetActor(ctx, builtin.BurntFundsActorAddr, c.ts.Key())
+	if err != nil {
+		return nil, fmt.Errorf("load burnt funds actor: %w", err)
+	}
+	out := c.compare("burnt_fil", act.Balance, burnt, "burnt_fil does not match the balance of the burnt funds actor")
+
+	prev, found, err := burntFil(ctx, c.db, c.pts)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		// the parent is reported when it is validated, if it is within the range.
+		return out, nil
+	}
+	gas, err := gasBurnt(ctx, c.db, c.pts)
+	if err != nil {
+		return nil, err
+	}
+	if d := BurnDiscrepancy(prev, burnt, gas); d != nil {
+		out = append(out, c.discrepancy("gas_burnt", gas.String(), d.String(),
+			fmt.Sprintf("increase in burnt_fil since height %d is less than the gas burnt by the messages included at that height", c.pts.Height())))
+	}
+	return out, nil
+}
+
+// BurnDiscrepancy returns the increase in burnt funds from prev to curr if it is less than the gas burnt by the
+// messages executed between them, otherwise nil. Penalties and value sent to the burnt funds actor also increase
+// burnt funds so the increase may exceed the gas burnt.
+func BurnDiscrepancy(prev, curr, gasBurnt abi.TokenAmount) *abi.TokenAmount {
+	delta := big.Sub(curr, prev)
+	if delta.LessThan(gasBurnt) {
+		return &delta
+	}
+	return nil
+}
+
+// burntFil returns chain_economics.burnt_fil for ts.
+func burntFil(ctx context.Context, db *storage.Database, ts *types.TipSet) (abi.TokenAmount, bool, error) {
+	var econ chainmodel.ChainEconomics
+	err := db.AsORM().ModelContext(ctx, &econ).
+		Where("height = ?", int64(ts.Height())).
+		Where("parent_state_root = ?", ts.ParentState().String()).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return abi.TokenAmount{}, false, nil
+		}
+		return abi.TokenAmount{}, false, fmt.Errorf("query chain_economics: %w", err)
+	}
+	burnt, err := big.FromString(econ.BurntFil)
+	if err != nil {
+		return abi.TokenAmount{}, false, fmt.Errorf("parse burnt_fil: %w", err)
+	}
+	return burnt, true, nil
+}
+
+// gasBurnt returns the sum of the base fee and over estimation burns of the messages included in ts.
+func gasBurnt(ctx context.Context, db *storage.Database, ts *types.TipSet) (abi.TokenAmount, error) {
+	var sum string
+	_, err := db.AsORM().QueryOneContext(ctx, pg.Scan(&sum),
+		`SELECT COALESCE(SUM(base_fee_burn + over_estimation_burn), 0)::text FROM derived_gas_outputs WHERE height = ? AND state_root = ?`,
+		int64(ts.Height()), ts.ParentState().String(),
+	)
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("query derived_gas_outputs: %w", err)
+	}
+	return big.FromString(sum)
+}
+
+func checkMessages(ctx context.Context, c *checkContext) (visor.ValidationReportList, error) {
+	msgs, err := c.node.MessagesWithDeduplicationForTipSet(ctx, c.ts)
+	if err != nil {
+		return nil, fmt.Errorf("get tipset messages: %w", err)
+	}
+
+	height := int64(c.ts.Height())
+	var stored, included int
+	if _, err := c.db.AsORM().QueryOneContext(ctx, pg.Scan(&stored), `SELECT count(*) FROM messages WHERE height = ?`, height); err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+	if _, err := c.db.AsORM().QueryOneContext(ctx, pg.Scan(&included), `SELECT count(DISTINCT message) FROM block_messages WHERE height = ?`, height); err != nil {
+		return nil, fmt.Errorf("query block_messages: %w", err)
+	}
+
+	var out visor.ValidationReportList
+	out = append(out, c.compare("block_messages", len(msgs), included, "number of distinct messages in block_messages does not match the messages of the tipset")...)
+	out = append(out, c.compare("messages", included, stored, "number of messages does not match the number of distinct messages in block_messages")...)
+	return out, nil
+}
+
+func checkChainPower(ctx context.Context, c *checkContext) (visor.ValidationReportList, error) {
+	act, err := c.node.StateGetActor(ctx, power.Address, c.ts.Key())
+	if err != nil {
+		return nil, fmt.Errorf("load power actor: %w", err)
+	}
+	st, err := power.Load(c.node.Store(), act)
+	if err != nil {
+		return nil, fmt.Errorf("load power actor state: %w", err)
+	}
+
+	var out visor.ValidationReportList
+
+	var row powermodel.ChainPower
+	err = c.db.AsORM().ModelContext(ctx, &row).
+		Where("height = ?", int64(c.ts.Height())).
+		Where("state_root = ?", c.ts.ParentState().String()).
+		Select()
+	switch {
+	case errors.Is(err, pg.ErrNoRows):
+		// chain_powers is only extracted when the power actor changes.
+	case err != nil:
+		return nil, fmt.Errorf("query chain_powers: %w", err)
+	default:
+		reports, err := comparePowerTotals(c, st, &row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, reports...)
+	}
+
+	var claims []*powermodel.PowerActorClaim
+	err = c.db.AsORM().ModelContext(ctx, &claims).
+		Where("height = ?", int64(c.ts.Height())).
+		Where("state_root = ?", c.ts.ParentState().String()).
+		Select()
+	if err != nil {
+		return nil, fmt.Errorf("query power_actor_claims: %w", err)
+	}
+	for _, claim := range claims {
+		addr, err := address.NewFromString(claim.MinerID)
+		if err != nil {
+			out = append(out, c.discrepancy(claim.MinerID, "", claim.MinerID, "invalid miner address"))
+			continue
+		}
+		actual, found, err := st.MinerPower(addr)
+		if err != nil {
+			return nil, fmt.Errorf("load claim of %s: %w", addr, err)
+		}
+		if !found {
+			out = append(out, c.discrepancy(claim.MinerID, "", claim.QualityAdjPower, "power_actor_claims row for miner without a claim"))
+			continue
+		}
+		out = append(out, c.compare(claim.MinerID+"/raw_byte_power", actual.RawBytePower, claim.RawBytePower, "raw_byte_power does not match the claim of the miner")...)
+		out = append(out, c.compare(claim.MinerID+"/quality_adj_power", actual.QualityAdjPower, claim.QualityAdjPower, "quality_adj_power does not match the claim of the miner")...)
+	}
+	return out, nil
+}
+
+// comparePowerTotals compares a chain_powers row with the power actor state and the sum of its claims.
+func comparePowerTotals(c *checkContext, st power.State, row *powermodel.ChainPower) (visor.ValidationReportList, error) {
+	total, err := st.TotalPower()
+	if err != nil {
+		return nil, err
+	}
+	committed, err := st.TotalCommitted()
+	if err != nil {
+		return nil, err
+	}
+	participating, miners, err := st.MinerCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var out visor.ValidationReportList
+	out = append(out, c.compare("total_raw_bytes_power", total.RawBytePower, row.TotalRawBytesPower, "does not match power actor state")...)
+	out = append(out, c.compare("total_qa_bytes_power", total.QualityAdjPower, row.TotalQABytesPower, "does not match power actor state")...)
+	out = append(out, c.compare("total_raw_bytes_committed", committed.RawBytePower, row.TotalRawBytesCommitted, "does not match power actor state")...)
+	out = append(out, c.compare("total_qa_bytes_committed", committed.QualityAdjPower, row.TotalQABytesCommitted, "does not match power actor state")...)
+	out = append(out, c.compare("miner_count", miners, row.MinerCount, "does not match power actor state")...)
+	out = append(out, c.compare("participating_miner_count", participating, row.ParticipatingMinerCount, "does not match power actor state")...)
+
+	sum := power.Claim{RawBytePower: big.Zero(), QualityAdjPower: big.Zero()}
+	if err := st.ForEachClaim(func(_ address.Address, claim power.Claim) error {
+		sum = power.AddClaims(sum, claim)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("sum miner claims: %w", err)
+	}
+	out = append(out, c.compare("claims_raw_byte_power", sum.RawBytePower, row.TotalRawBytesCommitted, "total_raw_bytes_committed does not equal the sum of the miner claims")...)
+	out = append(out, c.compare("claims_quality_adj_power", sum.QualityAdjPower, row.TotalQABytesCommitted, "total_qa_bytes_committed does not equal the sum of the miner claims")...)
+	return out, nil
+}
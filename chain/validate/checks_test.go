@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+func TestBurnDiscrepancy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		prev     abi.TokenAmount
+		curr     abi.TokenAmount
+		gasBurnt abi.TokenAmount
+		expected *abi.TokenAmount
+	}{
+		{name: "equal to gas burnt", prev: big.NewInt(100), curr: big.NewInt(150), gasBurnt: big.NewInt(50)},
+		{name: "more than gas burnt", prev: big.NewInt(100), curr: big.NewInt(200), gasBurnt: big.NewInt(50)},
+		{name: "less than gas burnt", prev: big.NewInt(100), curr: big.NewInt(120), gasBurnt: big.NewInt(50), expected: &abi.TokenAmount{Int: big.NewInt(20).Int}},
+		{name: "decrease", prev: big.NewInt(100), curr: big.NewInt(90), gasBurnt: big.Zero(), expected: &abi.TokenAmount{Int: big.NewInt(-10).Int}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := BurnDiscrepancy(tc.prev, tc.curr, tc.gasBurnt)
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+			require.NotNil(t, actual)
+			assert.True(t, tc.expected.Equals(*actual), "expected %s, got %s", tc.expected, actual)
+		})
+	}
+}
+
+func TestNewValidatorChecks(t *testing.T) {
+	v, err := NewValidator(nil, nil, "test", 0, 10, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, AllChecks, v.checks)
+	// a validator built without a reporter reports to one of its own
+	assert.NotNil(t, v.report)
+
+	_, err = NewValidator(nil, nil, "test", 0, 10, []string{MessagesCheck, "unknown"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/storage"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/chain/validate")
+
+// Validator is a job that checks the data persisted for a range of heights against chain state and the data of other
+// tables. Discrepancies are persisted as visor.ValidationReports.
+type Validator struct {
+	db                   *storage.Database
+	node                 lens.API
+	name                 string
+	minHeight, maxHeight int64
+	checks               []string
+	report               *schedule.Reporter
+	done                 chan struct{}
+}
+
+// NewValidator returns a Validator that runs the named checks for each tipset from minHeight to maxHeight inclusive.
+// Its progress is reported to r, if not nil.
+func NewValidator(node lens.API, db *storage.Database, name string, minHeight, maxHeight int64, checks []string, r *schedule.Reporter) (*Validator, error) {
+	if len(checks) == 0 {
+		checks = AllChecks
+	}
+	if r == nil {
+		r = &schedule.Reporter{}
+	}
+	for _, c := range checks {
+		if _, ok := checkFuncs[c]; !ok {
+			return nil, fmt.Errorf("unknown check: %s", c)
+		}
+	}
+	return &Validator{
+		db:        db,
+		node:      node,
+		name:      name,
+		minHeight: minHeight,
+		maxHeight: maxHeight,
+		checks:    checks,
+		report:    r,
+	}, nil
+}
+
+func (v *Validator) Run(ctx context.Context) error {
+	// init the done channel for each run since jobs may be started and stopped.
+	v.done = make(chan struct{})
+	defer close(v.done)
+
+	head, err := v.node.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	if int64(head.Height()) < v.maxHeight {
+		return fmt.Errorf("cannot validate beyond chain head height %d", head.Height())
+	}
+
+	var discrepancies int
+	for height := v.minHeight; height <= v.maxHeight; height++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		ts, err := v.node.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(height), head.Key())
+		if err != nil {
+			return fmt.Errorf("get tipset at height %d: %w", height, err)
+		}
+		if int64(ts.Height()) != height {
+			// null round, there is nothing persisted to validate.
+			continue
+		}
+
+		reports, err := v.ValidateTipSet(ctx, ts)
+		if err != nil {
+			return err
+		}
+		if err := v.db.PersistBatch(ctx, reports); err != nil {
+			return fmt.Errorf("persist validation reports: %w", err)
+		}
+		discrepancies += len(reports)
+		v.report.UpdateCurrentHeight(height)
+	}
+
+	log.Infow("validation complete", "min_height", v.minHeight, "max_height", v.maxHeight, "reports", discrepancies, "reporter", v.name)
+	return nil
+}
+
+func (v *Validator) Done() <-chan struct{} {
+	return v.done
+}
+
+// ValidateTipSet runs the checks of the validator for ts. A check that cannot be completed is reported with status
+// ERROR rather than stopping the validator.
+func (v *Validator) ValidateTipSet(ctx context.Context, ts *types.TipSet) (visor.ValidationReportList, error) {
+	pts, err := v.node.ChainGetTipSet(ctx, ts.Parents())
+	if err != nil {
+		return nil, fmt.Errorf("get parent tipset of %s: %w", ts.Key(), err)
+	}
+
+	c := &checkContext{
+		node:        v.node,
+		db:          v.db,
+		ts:          ts,
+		pts:         pts,
+		reporter:    v.name,
+		validatedAt: time.Now(),
+	}
+
+	var out visor.ValidationReportList
+	for _, name := range v.checks {
+		c.check = name
+		reports, err := checkFuncs[name](ctx, c)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			log.Errorw("validation check failed", "check", name, "height", ts.Height(), "error", err, "reporter", v.name)
+			out = append(out, c.failure(err))
+			continue
+		}
+		out = append(out, reports...)
+	}
+	return out, nil
+}
@@ -3,6 +3,8 @@ package walk
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	logging "github.com/ipfs/go-log/v2"
 	"go.opentelemetry.io/otel"
@@ -11,15 +13,43 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/visor"
 	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/storage"
 
 	"github.com/filecoin-project/lotus/chain/types"
 )
 
 var log = logging.Logger("lily/chain/walk")
 
-func NewWalker(obs indexer.Indexer, node lens.API, name string, tasks []string, minHeight, maxHeight int64, r *schedule.Reporter, stopOnError bool, interval int) *Walker {
-	return &Walker{
+type WalkerOpt func(w *Walker)
+
+// WithCheckpointer records the progress of the walk in cp after each tipset is indexed.
+func WithCheckpointer(cp storage.Checkpointer) WalkerOpt {
+	return func(w *Walker) {
+		w.checkpointer = cp
+	}
+}
+
+// WithResume continues the walk from the height recorded in the checkpoint of a previous run of the job, if one exists.
+// A checkpointer must also be supplied.
+func WithResume(resume bool) WalkerOpt {
+	return func(w *Walker) {
+		w.resume = resume
+	}
+}
+
+// WithShards splits the range of the walk into n contiguous height ranges that are walked concurrently. Each shard is
+// reported separately and checkpoints its own progress, the checkpoint of the walk only advances past the heights of a
+// shard once every higher shard has finished. A value of 1 or less walks the range serially.
+func WithShards(n int) WalkerOpt {
+	return func(w *Walker) {
+		w.shards = n
+	}
+}
+
+func NewWalker(obs indexer.Indexer, node lens.API, name string, tasks []string, minHeight, maxHeight int64, r *schedule.Reporter, stopOnError bool, interval int, opts ...WalkerOpt) *Walker {
+	w := &Walker{
 		node:        node,
 		obs:         obs,
 		name:        name,
@@ -30,6 +60,10 @@ func NewWalker(obs indexer.Indexer, node lens.API, name string, tasks []string,
 		stopOnError: stopOnError,
 		interval:    interval,
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // Walker is a job that indexes blocks by walking the chain history.
@@ -44,6 +78,10 @@ type Walker struct {
 	report      *schedule.Reporter
 	stopOnError bool
 	interval    int
+
+	checkpointer storage.Checkpointer // optional, used to record progress
+	resume       bool                 // when true, continue from the last checkpoint
+	shards       int                  // number of height ranges walked concurrently
 }
 
 // Run starts walking the chain history and continues until the context is done or
@@ -63,11 +101,28 @@ func (c *Walker) Run(ctx context.Context) error {
 		return fmt.Errorf("cannot walk history, chain head (%d) is earlier than minimum height (%d)", int64(head.Height()), c.minHeight)
 	}
 
+	if c.shards > 1 {
+		return c.walkShards(ctx, head)
+	}
+	return c.walk(ctx, head)
+}
+
+// walk indexes the range of the walker serially, from its maximum height down to its minimum height.
+func (c *Walker) walk(ctx context.Context, head *types.TipSet) error {
+	maxHeight, done, err := c.startHeight(ctx)
+	if err != nil {
+		return err
+	}
+	if done {
+		log.Infow("walk already complete according to checkpoint", "reporter", c.name)
+		return nil
+	}
+
 	start := head
 	// Start at maxHeight+1 so that the tipset at maxHeight becomes the parent for any tasks that need to make a diff between two tipsets.
 	// A walk where min==max must still process two tipsets to be sure of extracting data.
-	if int64(head.Height()) > c.maxHeight+1 {
-		start, err = c.node.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(c.maxHeight), head.Key())
+	if int64(head.Height()) > maxHeight+1 {
+		start, err = c.node.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(maxHeight), head.Key())
 		if err != nil {
 			return fmt.Errorf("get tipset by height: %w", err)
 		}
@@ -80,6 +135,272 @@ func (c *Walker) Run(ctx context.Context) error {
 	return nil
 }
 
+// walkShards splits the range of the walker into shards and walks them concurrently. The shards are derived from the
+// configured range of the walk, so the same shards are walked whatever the height of the chain head. Each shard records
+// its progress under its own checkpoint, and the checkpoint of the walk records the lowest height below which every
+// shard has been indexed, so a walk resumed with a different number of shards continues from there.
+func (c *Walker) walkShards(ctx context.Context, head *types.TipSet) error {
+	top, done, err := c.startHeight(ctx)
+	if err != nil {
+		return err
+	}
+	if done {
+		log.Infow("walk already complete according to checkpoint", "reporter", c.name)
+		return nil
+	}
+
+	ranges := ShardRanges(c.minHeight, c.maxHeight, c.shards)
+	reports := make([]*schedule.ShardReport, len(ranges))
+	for i, r := range ranges {
+		reports[i] = &schedule.ShardReport{Shard: i, MinHeight: r.Min, MaxHeight: r.Max}
+	}
+	c.report.SetShards(reports)
+
+	var progress *shardProgress
+	if c.checkpointer != nil {
+		progress = newShardProgress(c, ranges, top)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(ranges))
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		if r.Min > top {
+			// indexed by a previous run of the walk according to its checkpoint.
+			reports[i].UpdateCurrentHeight(r.Min)
+			reports[i].SetComplete()
+			continue
+		}
+		log.Infow("walking shard", "shard", i, "min_height", r.Min, "max_height", r.Max, "reporter", c.name)
+		wg.Add(1)
+		go func(i int, r HeightRange) {
+			defer wg.Done()
+			shard := &Walker{
+				node:        c.node,
+				obs:         c.obs,
+				name:        shardName(c.name, c.minHeight, c.maxHeight, len(ranges), i),
+				tasks:       c.tasks,
+				minHeight:   r.Min,
+				maxHeight:   r.Max,
+				report:      &reports[i].Reporter,
+				stopOnError: c.stopOnError,
+				interval:    c.interval,
+				resume:      c.resume,
+			}
+			if r.Max > top {
+				shard.maxHeight = top
+			}
+			if progress != nil {
+				shard.checkpointer = progress.checkpointer(i)
+			}
+			err := func() error {
+				if int64(head.Height()) < r.Min {
+					return fmt.Errorf("chain head (%d) is earlier than minimum height of shard", int64(head.Height()))
+				}
+				return shard.walk(ctx, head)
+			}()
+			if err != nil {
+				reports[i].SetError(err)
+				errs[i] = fmt.Errorf("shard %d (%d-%d): %w", i, r.Min, r.Max, err)
+				if c.stopOnError {
+					cancel()
+				}
+				return
+			}
+			reports[i].SetComplete()
+			log.Infow("shard complete", "shard", i, "min_height", r.Min, "max_height", r.Max, "reporter", c.name)
+		}(i, r)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("walk chain: %v", failed)
+	}
+
+	c.report.UpdateCurrentHeight(c.minHeight)
+	return nil
+}
+
+// shardName returns the name of the checkpoint of shard i of n of a walk of the range [min, max].
+func shardName(name string, min, max int64, n, i int) string {
+	return fmt.Sprintf("%s-%d-%d-shard-%d-of-%d", name, min, max, i, n)
+}
+
+// shardProgress tracks the checkpoints of the shards of a walk and saves the checkpoint of the walk as the lowest
+// height that every higher height has been indexed down to.
+type shardProgress struct {
+	walker *Walker
+	ranges []HeightRange
+	top    int64 // the height the walk started from, every height above it was already indexed
+
+	mu      sync.Mutex
+	heights []int64 // the checkpoint height of each shard
+	known   []bool  // whether each shard has a checkpoint
+	saved   int64   // the height of the last checkpoint saved for the walk
+}
+
+func newShardProgress(w *Walker, ranges []HeightRange, top int64) *shardProgress {
+	return &shardProgress{
+		walker:  w,
+		ranges:  ranges,
+		top:     top,
+		heights: make([]int64, len(ranges)),
+		known:   make([]bool, len(ranges)),
+		saved:   top + 1,
+	}
+}
+
+// checkpointer returns the checkpointer of shard i.
+func (p *shardProgress) checkpointer(i int) storage.Checkpointer {
+	return &shardCheckpointer{progress: p, shard: i}
+}
+
+// update records that shard i has indexed every height of its range from height upwards, and saves the checkpoint of
+// the walk if it has advanced.
+func (p *shardProgress) update(ctx context.Context, i int, height int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.heights[i], p.known[i] = height, true
+
+	// walk down from the highest shard while each shard has indexed all of its range.
+	low := p.top + 1
+	for j := len(p.ranges) - 1; j >= 0; j-- {
+		r := p.ranges[j]
+		if r.Min > p.top {
+			continue
+		}
+		if !p.known[j] || p.heights[j] > p.top {
+			break
+		}
+		if p.heights[j] > r.Min {
+			low = p.heights[j]
+			break
+		}
+		low = r.Min
+	}
+	if low >= p.saved {
+		return nil
+	}
+	if err := p.walker.saveCheckpoint(ctx, low); err != nil {
+		return err
+	}
+	p.saved = low
+	return nil
+}
+
+// shardCheckpointer records the checkpoints of a shard in the walk's checkpointer and updates the progress of the walk.
+type shardCheckpointer struct {
+	progress *shardProgress
+	shard    int
+}
+
+func (s *shardCheckpointer) LoadCheckpoint(ctx context.Context, name string) (*visor.JobCheckpoint, error) {
+	cp, err := s.progress.walker.checkpointer.LoadCheckpoint(ctx, name)
+	if err != nil || cp == nil {
+		return cp, err
+	}
+	if err := s.progress.update(ctx, s.shard, cp.Height); err != nil {
+		log.Errorw("failed to save walk checkpoint", "error", err, "reporter", s.progress.walker.name)
+	}
+	return cp, nil
+}
+
+func (s *shardCheckpointer) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	if err := s.progress.walker.checkpointer.SaveCheckpoint(ctx, cp); err != nil {
+		return err
+	}
+	if err := s.progress.update(ctx, s.shard, cp.Height); err != nil {
+		log.Errorw("failed to save walk checkpoint", "error", err, "reporter", s.progress.walker.name)
+	}
+	return nil
+}
+
+// HeightRange is an inclusive range of heights.
+type HeightRange struct {
+	Min int64
+	Max int64
+}
+
+// ShardRanges splits the inclusive range [min, max] into at most n contiguous ranges of near equal size, ordered from
+// the lowest range to the highest.
+func ShardRanges(min, max int64, n int) []HeightRange {
+	if max < min {
+		return nil
+	}
+	size := max - min + 1
+	if n < 1 {
+		n = 1
+	}
+	if int64(n) > size {
+		n = int(size)
+	}
+
+	out := make([]HeightRange, 0, n)
+	per, rem := size/int64(n), size%int64(n)
+	lo := min
+	for i := 0; i < n; i++ {
+		hi := lo + per - 1
+		if int64(i) < rem {
+			hi++
+		}
+		out = append(out, HeightRange{Min: lo, Max: hi})
+		lo = hi + 1
+	}
+	return out
+}
+
+// startHeight returns the height the walk starts from, which is the maximum height unless the walk is resumed. done is
+// true when the walk is resumed and its checkpoint shows it is already complete.
+func (c *Walker) startHeight(ctx context.Context) (height int64, done bool, err error) {
+	if !c.resume {
+		return c.maxHeight, false, nil
+	}
+	return c.resumeHeight(ctx)
+}
+
+// resumeHeight returns the height the walk should resume from according to the job's checkpoint. done is true
+// when the checkpoint shows the walk has already reached the minimum height.
+func (c *Walker) resumeHeight(ctx context.Context) (height int64, done bool, err error) {
+	if c.checkpointer == nil {
+		return 0, false, fmt.Errorf("cannot resume walk: storage does not support checkpoints")
+	}
+
+	cp, err := c.checkpointer.LoadCheckpoint(ctx, c.name)
+	if err != nil {
+		return 0, false, fmt.Errorf("load checkpoint: %w", err)
+	}
+	if cp == nil || cp.Height > c.maxHeight {
+		log.Infow("no checkpoint found within walk range, starting from maximum height", "max_height", c.maxHeight, "reporter", c.name)
+		return c.maxHeight, false, nil
+	}
+	if cp.Height <= c.minHeight {
+		return 0, true, nil
+	}
+
+	log.Infow("resuming walk from checkpoint", "checkpoint_height", cp.Height, "reporter", c.name)
+	return cp.Height - 1, false, nil
+}
+
+func (c *Walker) saveCheckpoint(ctx context.Context, height int64) error {
+	if c.checkpointer == nil {
+		return nil
+	}
+	return c.checkpointer.SaveCheckpoint(ctx, &visor.JobCheckpoint{
+		JobName:   c.name,
+		JobType:   "walk",
+		Height:    height,
+		UpdatedAt: time.Now(),
+	})
+}
+
 func (c *Walker) Done() <-chan struct{} {
 	return c.done
 }
@@ -98,6 +419,9 @@ func (c *Walker) WalkChain(ctx context.Context, node lens.API, ts *types.TipSet)
 
 	var err error
 	errs := []error{}
+	// the checkpoint only advances past heights that were indexed successfully, once a tipset fails a resumed walk must
+	// start again from it.
+	checkpoint := true
 	for int64(ts.Height()) >= c.minHeight && ts.Height() != 0 {
 		select {
 		case <-ctx.Done():
@@ -112,6 +436,7 @@ func (c *Walker) WalkChain(ctx context.Context, node lens.API, ts *types.TipSet)
 			log.Errorf("%v", err)
 			// collect error
 			errs = append(errs, err)
+			checkpoint = false
 
 			// return an error only if the "stopOnError" flag is set to true.
 			if c.stopOnError {
@@ -119,9 +444,16 @@ func (c *Walker) WalkChain(ctx context.Context, node lens.API, ts *types.TipSet)
 			}
 		} else if !success {
 			log.Errorw("walk incomplete", "height", ts.Height(), "tipset", ts.Key().String(), "reporter", c.name)
+			checkpoint = false
 		}
 		log.Infow("walk tipset success", "height", ts.Height(), "reporter", c.name)
 
+		if checkpoint {
+			if err := c.saveCheckpoint(ctx, int64(ts.Height())); err != nil {
+				log.Errorw("failed to save walk checkpoint", "error", err, "height", ts.Height(), "reporter", c.name)
+			}
+		}
+
 		ts, err = node.ChainGetTipSet(ctx, ts.Parents())
 		if err != nil {
 			span.RecordError(err)
@@ -133,5 +465,12 @@ func (c *Walker) WalkChain(ctx context.Context, node lens.API, ts *types.TipSet)
 		return fmt.Errorf("errors: %v", errs)
 	}
 
+	// every height down to the minimum has been indexed, including any null rounds below the last tipset.
+	if checkpoint && int64(ts.Height()) < c.minHeight {
+		if err := c.saveCheckpoint(ctx, c.minHeight); err != nil {
+			log.Errorw("failed to save walk checkpoint", "error", err, "height", c.minHeight, "reporter", c.name)
+		}
+	}
+
 	return nil
 }
@@ -2,23 +2,32 @@ package walk
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/lily/chain/actors/builtin"
 	"github.com/filecoin-project/lily/chain/datasource"
+	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/integrated"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/lens"
 	"github.com/filecoin-project/lily/model/blocks"
+	"github.com/filecoin-project/lily/model/visor"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
 	"github.com/filecoin-project/lily/testutil"
 
+	"github.com/filecoin-project/lotus/chain/types"
 	itestkit "github.com/filecoin-project/lotus/itests/kit"
 )
 
@@ -108,3 +117,231 @@ func TestWalker(t *testing.T) {
 		}
 	})
 }
+
+func TestShardRanges(t *testing.T) {
+	require.Equal(t, []HeightRange{{Min: 10, Max: 20}}, ShardRanges(10, 20, 1))
+	require.Equal(t, []HeightRange{{Min: 10, Max: 20}}, ShardRanges(10, 20, 0))
+	require.Equal(t, []HeightRange{{Min: 0, Max: 3}, {Min: 4, Max: 6}, {Min: 7, Max: 9}}, ShardRanges(0, 9, 3))
+	require.Equal(t, []HeightRange{{Min: 5, Max: 5}, {Min: 6, Max: 6}}, ShardRanges(5, 6, 4))
+	require.Equal(t, []HeightRange{{Min: 7, Max: 7}}, ShardRanges(7, 7, 2))
+	require.Nil(t, ShardRanges(8, 7, 2))
+}
+
+// fakeChain serves a chain of single block tipsets, one at each height from genesis to its head, from memory.
+type fakeChain struct {
+	lens.API
+	tipsets []*types.TipSet // indexed by height
+}
+
+func newFakeChain(t *testing.T, head int64) *fakeChain {
+	c := &fakeChain{tipsets: make([]*types.TipSet, head+1)}
+	var parents []cid.Cid
+	for h := int64(0); h <= head; h++ {
+		bh := testutil.FakeBlockHeader(t, h, testutil.RandomCid())
+		bh.Parents = parents
+		ts, err := types.NewTipSet([]*types.BlockHeader{bh})
+		require.NoError(t, err)
+		c.tipsets[h] = ts
+		parents = ts.Cids()
+	}
+	return c
+}
+
+func (c *fakeChain) ChainHead(_ context.Context) (*types.TipSet, error) {
+	return c.tipsets[len(c.tipsets)-1], nil
+}
+
+func (c *fakeChain) ChainGetTipSet(_ context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	for _, ts := range c.tipsets {
+		if ts.Key() == tsk {
+			return ts, nil
+		}
+	}
+	return nil, fmt.Errorf("tipset %s not found", tsk)
+}
+
+func (c *fakeChain) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	return c.tipsets[h], nil
+}
+
+// fakeIndexer records the heights it indexes. It fails to index the heights in fail and only partially indexes the
+// heights in incomplete.
+type fakeIndexer struct {
+	fail       map[int64]bool
+	incomplete map[int64]bool
+
+	mu      sync.Mutex
+	indexed []int64
+}
+
+func (f *fakeIndexer) TipSet(_ context.Context, ts *types.TipSet, _ ...indexer.Option) (bool, error) {
+	h := int64(ts.Height())
+	f.mu.Lock()
+	f.indexed = append(f.indexed, h)
+	f.mu.Unlock()
+	if f.fail[h] {
+		return false, fmt.Errorf("failed to index %d", h)
+	}
+	return !f.incomplete[h], nil
+}
+
+type memCheckpointer struct {
+	mu          sync.Mutex
+	checkpoints map[string]*visor.JobCheckpoint
+}
+
+func (m *memCheckpointer) LoadCheckpoint(_ context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[jobName], nil
+}
+
+func (m *memCheckpointer) SaveCheckpoint(_ context.Context, cp *visor.JobCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.checkpoints == nil {
+		m.checkpoints = map[string]*visor.JobCheckpoint{}
+	}
+	m.checkpoints[cp.JobName] = cp
+	return nil
+}
+
+func TestWalkerCheckpointStopsAtFailedHeight(t *testing.T) {
+	testCases := []struct {
+		name    string
+		idx     *fakeIndexer
+		wantErr bool
+	}{
+		{name: "error", idx: &fakeIndexer{fail: map[int64]bool{15: true}}, wantErr: true},
+		{name: "incomplete", idx: &fakeIndexer{incomplete: map[int64]bool{15: true}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			chain := newFakeChain(t, 20)
+			cp := &memCheckpointer{}
+
+			w := NewWalker(tc.idx, chain, t.Name(), nil, 10, 20, &schedule.Reporter{}, false, 10, WithCheckpointer(cp))
+			err := w.Run(ctx)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, []int64{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10}, tc.idx.indexed)
+
+			// heights below the failed tipset were indexed but the checkpoint does not advance past it
+			saved, err := cp.LoadCheckpoint(ctx, t.Name())
+			require.NoError(t, err)
+			require.EqualValues(t, 16, saved.Height)
+
+			// a resumed walk starts again from the failed height
+			idx := &fakeIndexer{}
+			w = NewWalker(idx, chain, t.Name(), nil, 10, 20, &schedule.Reporter{}, false, 10, WithCheckpointer(cp), WithResume(true))
+			require.NoError(t, w.Run(ctx))
+			require.Equal(t, []int64{15, 14, 13, 12, 11, 10}, idx.indexed)
+
+			saved, err = cp.LoadCheckpoint(ctx, t.Name())
+			require.NoError(t, err)
+			require.EqualValues(t, 10, saved.Height)
+		})
+	}
+}
+
+func TestWalkerShards(t *testing.T) {
+	// heights returns the heights indexed by idx in ascending order.
+	heights := func(idx *fakeIndexer) []int64 {
+		out := append([]int64{}, idx.indexed...)
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out
+	}
+	between := func(lo, hi int64) []int64 {
+		var out []int64
+		for h := lo; h <= hi; h++ {
+			out = append(out, h)
+		}
+		return out
+	}
+
+	// walk 10-29 in two shards, 10-19 and 20-29, failing to index height 25.
+	failedWalk := func(t *testing.T, cp *memCheckpointer) {
+		ctx := context.Background()
+		idx := &fakeIndexer{fail: map[int64]bool{25: true}}
+		reporter := &schedule.Reporter{}
+		w := NewWalker(idx, newFakeChain(t, 30), t.Name(), nil, 10, 29, reporter, false, 10, WithCheckpointer(cp), WithShards(2))
+
+		// the report is read while the shards update it, as lily job list does.
+		stop := make(chan struct{})
+		read := make(chan struct{})
+		go func() {
+			defer close(read)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					reporter.Snapshot()
+				}
+			}
+		}()
+		err := w.Run(ctx)
+		close(stop)
+		<-read
+		require.Error(t, err)
+		require.Equal(t, between(10, 29), heights(idx))
+
+		report := reporter.Snapshot()
+		require.Len(t, report.Shards, 2)
+		require.True(t, report.Shards[0].Complete)
+		require.Empty(t, report.Shards[0].Error)
+		require.False(t, report.Shards[1].Complete)
+		require.NotEmpty(t, report.Shards[1].Error)
+
+		// the walk is checkpointed down to the failed height of the highest shard, even though the lower shard completed.
+		saved, err := cp.LoadCheckpoint(ctx, t.Name())
+		require.NoError(t, err)
+		require.EqualValues(t, 26, saved.Height)
+	}
+
+	t.Run("resume after head moved", func(t *testing.T) {
+		ctx := context.Background()
+		cp := &memCheckpointer{}
+		failedWalk(t, cp)
+
+		// only the failed part of the highest shard is walked again.
+		idx := &fakeIndexer{}
+		w := NewWalker(idx, newFakeChain(t, 50), t.Name(), nil, 10, 29, &schedule.Reporter{}, false, 10, WithCheckpointer(cp), WithShards(2), WithResume(true))
+		require.NoError(t, w.Run(ctx))
+		require.Equal(t, between(20, 25), heights(idx))
+
+		saved, err := cp.LoadCheckpoint(ctx, t.Name())
+		require.NoError(t, err)
+		require.EqualValues(t, 10, saved.Height)
+	})
+
+	t.Run("resume with different shards", func(t *testing.T) {
+		ctx := context.Background()
+		cp := &memCheckpointer{}
+		failedWalk(t, cp)
+
+		// the walk continues from its own checkpoint, below which the new shards have no progress.
+		idx := &fakeIndexer{}
+		reporter := &schedule.Reporter{}
+		w := NewWalker(idx, newFakeChain(t, 30), t.Name(), nil, 10, 29, reporter, false, 10, WithCheckpointer(cp), WithShards(3), WithResume(true))
+		require.NoError(t, w.Run(ctx))
+		require.Equal(t, between(10, 25), heights(idx))
+		for _, s := range reporter.Snapshot().Shards {
+			require.True(t, s.Complete, "shard %d", s.Shard)
+		}
+
+		saved, err := cp.LoadCheckpoint(ctx, t.Name())
+		require.NoError(t, err)
+		require.EqualValues(t, 10, saved.Height)
+
+		// a completed walk is not walked again.
+		idx = &fakeIndexer{}
+		w = NewWalker(idx, newFakeChain(t, 30), t.Name(), nil, 10, 29, &schedule.Reporter{}, false, 10, WithCheckpointer(cp), WithShards(3), WithResume(true))
+		require.NoError(t, w.Run(ctx))
+		require.Empty(t, idx.indexed)
+	})
+}
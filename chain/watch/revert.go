@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/lotus/chain/types"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/storage"
+)
+
+// RevertHandler is notified of tipsets that are reverted by the chain after they have been submitted for indexing.
+type RevertHandler interface {
+	Revert(ctx context.Context, ts *types.TipSet) error
+}
+
+// StorageRevertHandler records reverted tipsets in the visor_reverted_tipsets table and, when the storage
+// implements storage.Reverter, removes the data that was persisted for them.
+type StorageRevertHandler struct {
+	strg model.Storage
+	name string
+}
+
+var _ RevertHandler = (*StorageRevertHandler)(nil)
+
+func NewStorageRevertHandler(strg model.Storage, name string) *StorageRevertHandler {
+	return &StorageRevertHandler{
+		strg: strg,
+		name: name,
+	}
+}
+
+func (h *StorageRevertHandler) Revert(ctx context.Context, ts *types.TipSet) error {
+	if r, ok := h.strg.(storage.Reverter); ok {
+		if err := r.RevertTipSet(ctx, int64(ts.Height()), ts.ParentState().String()); err != nil {
+			return fmt.Errorf("revert tipset data: %w", err)
+		}
+	}
+
+	if err := h.strg.PersistBatch(ctx, &visor.RevertedTipSet{
+		Height:     int64(ts.Height()),
+		TipSet:     ts.Key().String(),
+		StateRoot:  ts.ParentState().String(),
+		Reporter:   h.name,
+		RevertedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("persist reverted tipset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/workerpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/chain/cache"
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/testutil"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// blockingIndexer indexes tipsets once release is closed and records the order of indexing and reverts.
+type blockingIndexer struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (b *blockingIndexer) TipSet(_ context.Context, _ *types.TipSet, _ ...indexer.Option) (bool, error) {
+	<-b.release
+	b.record("indexed")
+	return true, nil
+}
+
+func (b *blockingIndexer) Revert(_ context.Context, _ *types.TipSet) error {
+	b.record("reverted")
+	return nil
+}
+
+func (b *blockingIndexer) record(e string) {
+	b.mu.Lock()
+	b.events = append(b.events, e)
+	b.mu.Unlock()
+}
+
+func TestWatcherRevertWaitsForIndexing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	idx := &blockingIndexer{release: make(chan struct{})}
+	w := NewWatcher(nil, idx, t.Name(), &schedule.Reporter{}, WithConfidence(0), WithRevertHandler(idx))
+	// the watchers worker pool and cache are initialized in its Run method, since we don't call that here initialize them now.
+	w.cache = cache.NewTipSetCache(0)
+	w.pool = workerpool.New(1)
+	defer w.pool.Stop()
+
+	ts := testutil.MustFakeTipSet(t, 10)
+	require.NoError(t, w.index(ctx, &HeadEvent{Type: HeadEventCurrent, TipSet: ts}))
+
+	reverted := make(chan error)
+	go func() {
+		reverted <- w.index(ctx, &HeadEvent{Type: HeadEventRevert, TipSet: ts})
+	}()
+
+	select {
+	case <-reverted:
+		t.Fatal("tipset reverted while it was being indexed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(idx.release)
+	require.NoError(t, <-reverted)
+	require.Equal(t, []string{"indexed", "reverted"}, idx.events)
+}
@@ -62,6 +62,13 @@ func WithBufferSize(b int) WatcherOpt {
 	}
 }
 
+// WithRevertHandler sets a handler that is called for each tipset reverted after it was submitted for indexing.
+func WithRevertHandler(h RevertHandler) WatcherOpt {
+	return func(w *Watcher) {
+		w.revert = h
+	}
+}
+
 // Watcher is a task that indexes blocks by following the chain head.
 type Watcher struct {
 	// required
@@ -74,6 +81,7 @@ type Watcher struct {
 	poolSize   int
 	tasks      []string
 	interval   int
+	revert     RevertHandler // optional, called for tipsets reverted after indexing
 
 	// created internally
 	done       chan struct{}
@@ -82,6 +90,10 @@ type Watcher struct {
 	pool       *workerpool.WorkerPool // used for async tipset indexing
 	tsObserver *TipSetObserver
 
+	// tipsets submitted for indexing that have not completed, a tipset is reverted once they have.
+	inFlightMu sync.Mutex
+	inFlight   map[types.TipSetKey]*inFlightTipSet
+
 	// metric tracking
 	active int64 // must be accessed using atomic operations, updated automatically.
 	report *schedule.Reporter
@@ -114,6 +126,7 @@ func NewWatcher(api WatcherAPI, indexer indexer.Indexer, name string, r *schedul
 		tasks:      WatcherDefaultTasks,
 		interval:   WatcherDefaultInterval,
 		report:     r,
+		inFlight:   make(map[types.TipSetKey]*inFlightTipSet),
 	}
 
 	for _, opt := range opts {
@@ -220,6 +233,17 @@ func (c *Watcher) index(ctx context.Context, he *HeadEvent) error {
 				// The chain is unwinding but our cache is empty. This probably means we have already processed
 				// the tipset being reverted and may process it again or an alternate heaviest tipset for this height.
 				metrics.RecordInc(ctx, metrics.TipSetCacheEmptyRevert)
+				if c.revert != nil {
+					// a worker still indexing the tipset could persist its data again after it has been reverted.
+					if err := c.waitInFlight(ctx, he.TipSet.Key()); err != nil {
+						return fmt.Errorf("wait for tipset indexing: %w", err)
+					}
+					log.Warnw("reverting indexed tipset", "height", he.TipSet.Height(), "tipset", he.TipSet.Key().String(), "reporter", c.name)
+					if err := c.revert.Revert(ctx, he.TipSet); err != nil {
+						return fmt.Errorf("revert tipset: %w", err)
+					}
+					break
+				}
 			}
 			log.Errorw("tipset cache revert", "error", err.Error(), "reporter", c.name)
 		}
@@ -247,10 +271,12 @@ func (c *Watcher) indexTipSetAsync(ctx context.Context, ts *types.TipSet) error
 	log.Infow("submitting tipset for async indexing", "height", ts.Height(), "active", c.active, "reporter", c.name)
 	c.report.UpdateCurrentHeight(int64(ts.Height()))
 	ctx, span := otel.Tracer("").Start(ctx, "Watcher.indexTipSetAsync")
+	inFlight := c.startInFlight(ts.Key())
 	c.pool.Submit(func() {
 		atomic.AddInt64(&c.active, 1)
 		defer func() {
 			atomic.AddInt64(&c.active, -1)
+			c.finishInFlight(ts.Key(), inFlight)
 			span.End()
 		}()
 
@@ -268,6 +294,57 @@ func (c *Watcher) indexTipSetAsync(ctx context.Context, ts *types.TipSet) error
 	return nil
 }
 
+// inFlightTipSet tracks the submissions of a tipset for indexing that have not completed.
+type inFlightTipSet struct {
+	count int // guarded by Watcher.inFlightMu
+	wg    sync.WaitGroup
+}
+
+func (c *Watcher) startInFlight(tsk types.TipSetKey) *inFlightTipSet {
+	c.inFlightMu.Lock()
+	defer c.inFlightMu.Unlock()
+	f, ok := c.inFlight[tsk]
+	if !ok {
+		f = &inFlightTipSet{}
+		c.inFlight[tsk] = f
+	}
+	f.count++
+	f.wg.Add(1)
+	return f
+}
+
+func (c *Watcher) finishInFlight(tsk types.TipSetKey, f *inFlightTipSet) {
+	c.inFlightMu.Lock()
+	f.count--
+	if f.count == 0 {
+		delete(c.inFlight, tsk)
+	}
+	c.inFlightMu.Unlock()
+	f.wg.Done()
+}
+
+// waitInFlight blocks until the submissions of the tipset for indexing, queued or running, have completed.
+func (c *Watcher) waitInFlight(ctx context.Context, tsk types.TipSetKey) error {
+	c.inFlightMu.Lock()
+	f, ok := c.inFlight[tsk]
+	c.inFlightMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
 func (c *Watcher) setFatalError(err error) {
 	c.fatalMu.Lock()
 	c.fatal = err
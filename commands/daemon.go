@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -12,14 +13,17 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
+	"github.com/filecoin-project/go-jsonrpc/auth"
 	paramfetch "github.com/filecoin-project/go-paramfetch"
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/commands/util"
 	"github.com/filecoin-project/lily/config"
 	"github.com/filecoin-project/lily/lens/lily"
 	"github.com/filecoin-project/lily/lens/lily/modules"
 	lutil "github.com/filecoin-project/lily/lens/util"
 	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/query"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
 	"github.com/filecoin-project/lily/version"
@@ -68,6 +72,8 @@ type daemonOpts struct {
 	bootstrap bool // TODO: is this necessary - do we want to run lily in this mode?
 	config    string
 	genesis   string
+
+	queryStorage string // name of the storage queried by the http query api, the api is disabled when empty
 }
 
 var daemonFlags daemonOpts
@@ -125,8 +131,21 @@ daemon falls out of sync. Start a watch using 'lily watch'.
 A walk job will start immediately. Start a walk using 'lily walk'. A walk may
 only be performed between heights that have been synchronized with the network.
 
-Note that jobs are not persisted between restarts of the daemon. See
-'lily help job' for more information on managing jobs being run by the daemon.
+Note that jobs are not persisted between restarts of the daemon unless they
+are persisted with 'lily job persist'. See 'lily help job' for more information
+on managing jobs being run by the daemon.
+
+When --query-storage names a postgresql storage from the config, the daemon
+also serves read-only queries over the data in that database on the api
+address. Unlike the json-rpc api, requests must carry an api token with read
+permission, such as the token in the repository's token file, for example:
+
+  curl -H "Authorization: Bearer $(cat <path>/token)" \
+    'http://127.0.0.1:1234/v1/tables/miner_infos?height=1000&limit=10'
+
+Rows may be filtered on primary key columns only. Responses include a
+next_cursor value that is passed as the cursor parameter to fetch the next
+page. 'GET /v1/tables' lists the tables that may be queried.
 `,
 
 	Flags: []cli.Flag{
@@ -157,6 +176,12 @@ Note that jobs are not persisted between restarts of the daemon. See
 			EnvVars:     []string{"LILY_GENESIS"},
 			Destination: &daemonFlags.genesis,
 		},
+		&cli.StringFlag{
+			Name:        "query-storage",
+			Usage:       "Name of a postgresql storage from the config to serve read-only queries from at /v1/tables on the api address. Requests must carry an api token with read permission. The query api is disabled when not set.",
+			EnvVars:     []string{"LILY_QUERY_STORAGE"},
+			Destination: &daemonFlags.queryStorage,
+		},
 		&cli.UintFlag{
 			Name:        "blockstore-cache-size",
 			EnvVars:     []string{"LILY_BLOCKSTORE_CACHE_SIZE"},
@@ -257,6 +282,8 @@ Note that jobs are not persisted between restarts of the daemon. See
 			node.Override(new(*storage.Catalog), modules.NewStorageCatalog),
 			node.Override(new(*distributed.Catalog), modules.NewQueueCatalog),
 			node.Override(new(*lutil.CacheConfig), modules.CacheConfig(cacheFlags.BlockstoreCacheSize, cacheFlags.StatestoreCacheSize)),
+			node.Override(new(*schedule.JobRegistry), modules.NewJobRegistry),
+			node.Override(new(*processor.TaskConfig), modules.NewTaskConfig),
 			// End Injection
 
 			node.Override(new(dtypes.Bootstrapper), isBootstrapper),
@@ -291,6 +318,19 @@ Note that jobs are not persisted between restarts of the daemon. See
 			return fmt.Errorf("initializing node: %w", err)
 		}
 
+		if na, ok := api.(*lily.LilyNodeAPI); ok {
+			// submit any jobs that were persisted by a previous run of the daemon
+			if err := na.RestorePersistedJobs(ctx); err != nil {
+				log.Errorw("failed to restore persisted jobs", "error", err)
+			}
+
+			if daemonFlags.queryStorage != "" {
+				if err := registerQueryAPI(ctx, na, daemonFlags.queryStorage); err != nil {
+					return fmt.Errorf("starting query api: %w", err)
+				}
+			}
+		}
+
 		endpoint, err := r.APIEndpoint()
 		if err != nil {
 			return fmt.Errorf("getting api endpoint: %w", err)
@@ -301,3 +341,27 @@ Note that jobs are not persisted between restarts of the daemon. See
 		return util.ServeRPC(api, stop, endpoint, shutdown, maxAPIRequestSize)
 	},
 }
+
+// registerQueryAPI serves read-only queries against the named database storage alongside the json-rpc api. Requests
+// must carry an api token with read permission.
+func registerQueryAPI(ctx context.Context, na *lily.LilyNodeAPI, name string) error {
+	db, err := na.StorageCatalog.ConnectAsDatabase(ctx, name, storage.Metadata{JobName: "query"})
+	if err != nil {
+		return err
+	}
+
+	srv := query.NewServer(db.AsORM(), query.NewTables(storage.Models), query.DefaultOptions())
+	http.Handle("/v1/", &auth.Handler{
+		Verify: na.AuthVerify,
+		Next: func(w http.ResponseWriter, r *http.Request) {
+			// callers without a token are not given the default read permission of the json-rpc api.
+			if !auth.HasPerm(r.Context(), nil, util.PermRead) {
+				http.Error(w, "api token with read permission required", http.StatusUnauthorized)
+				return
+			}
+			srv.ServeHTTP(w, r)
+		},
+	})
+	log.Infow("serving query api", "storage", name)
+	return nil
+}
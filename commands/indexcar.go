@@ -0,0 +1,324 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/zstd"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/chain/datasource"
+	"github.com/filecoin-project/lily/chain/indexer/integrated"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
+	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/chain/walk"
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/lens/lily"
+	"github.com/filecoin-project/lily/lens/lily/modules"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/storage"
+
+	badgerbs "github.com/filecoin-project/lotus/blockstore/badger"
+	"github.com/filecoin-project/lotus/chain/consensus"
+	"github.com/filecoin-project/lotus/chain/consensus/filcns"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/vm"
+	"github.com/filecoin-project/lotus/node/repo"
+	"github.com/filecoin-project/lotus/storage/sealer/ffiwrapper"
+)
+
+type indexCarOps struct {
+	car         string
+	from        int64
+	to          int64
+	tasks       cli.StringSlice
+	name        string
+	config      string
+	storage     string
+	interval    int
+	stopOnError bool
+	tmpDir      string
+}
+
+var indexCarFlags indexCarOps
+
+var IndexCarCmd = &cli.Command{
+	Name:  "index-car",
+	Usage: "Index a range of the chain from a CAR file without running a lily daemon.",
+	Description: `
+The index-car command imports a chain export CAR file, such as one produced by lily export, into a temporary
+blockstore and indexes the tipsets between --from and --to (inclusive) with the given tasks. No repo, network
+connection or chain sync is required, making it possible to re-process archived snapshots on air-gapped machines.
+
+The CAR file must contain the block headers from its head back to genesis, along with the messages, receipts and
+state roots of the range being indexed. Tasks that depend on indexes maintained by the daemon, such as the fevm tasks
+and the actor event tasks, report errors when run offline.
+
+Storage and tasks are configured in the same way as the daemon, with --config naming the config file and --storage
+naming a storage defined in it. When --storage is omitted extracted data is discarded.
+
+  $ lily index-car --car=chain_export.car --from=100 --to=200 --tasks=block_header,messages --config=~/.lily/config.toml --storage=Database1
+`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "car",
+			Usage:       "Path to the CAR `FILE` to index, optionally zstd compressed",
+			Required:    true,
+			Destination: &indexCarFlags.car,
+		},
+		&cli.Int64Flag{
+			Name:        "from",
+			Usage:       "Limit actor and message processing to tipsets at or above `HEIGHT`",
+			Required:    true,
+			Destination: &indexCarFlags.from,
+		},
+		&cli.Int64Flag{
+			Name:        "to",
+			Usage:       "Limit actor and message processing to tipsets at or below `HEIGHT`",
+			Required:    true,
+			Destination: &indexCarFlags.to,
+		},
+		&cli.StringSliceFlag{
+			Name:        "tasks",
+			Usage:       "Comma separated list of tasks to run. Each task is reported separately in the storage.",
+			Value:       cli.NewStringSlice(tasktype.AllTableTasks...),
+			Destination: &indexCarFlags.tasks,
+		},
+		&cli.StringFlag{
+			Name:        "name",
+			Usage:       "Name of the job, used to identify its results in the storage",
+			Value:       "",
+			Destination: &indexCarFlags.name,
+		},
+		&cli.StringFlag{
+			Name:        "config",
+			Usage:       "Specify path of config file that defines --storage and configures tasks.",
+			EnvVars:     []string{"LILY_CONFIG"},
+			Value:       "~/.lily/config.toml",
+			Destination: &indexCarFlags.config,
+		},
+		&cli.StringFlag{
+			Name:        "storage",
+			Usage:       "Name of storage defined in the config file that results will be written to.",
+			Value:       "",
+			Destination: &indexCarFlags.storage,
+		},
+		&cli.IntFlag{
+			Name:        "interval",
+			Usage:       "The interval for specific task",
+			Value:       120,
+			Destination: &indexCarFlags.interval,
+		},
+		&cli.BoolFlag{
+			Name:        "stop-on-error",
+			Usage:       "Stop indexing if a tipset fails to be indexed",
+			Value:       false,
+			Destination: &indexCarFlags.stopOnError,
+		},
+		&cli.StringFlag{
+			Name:        "tmp-dir",
+			Usage:       "`DIR` the CAR file is imported into, a temporary directory is created and removed when not set",
+			Value:       "",
+			Destination: &indexCarFlags.tmpDir,
+		},
+	},
+	Before: func(_ *cli.Context) error {
+		if indexCarFlags.to < indexCarFlags.from {
+			return fmt.Errorf("value of --to (%d) should be >= --from (%d)", indexCarFlags.to, indexCarFlags.from)
+		}
+		for _, taskName := range indexCarFlags.tasks.Value() {
+			if _, found := tasktype.TaskLookup[taskName]; found {
+				continue
+			} else if _, found := tasktype.TableLookup[taskName]; found {
+				continue
+			}
+			return fmt.Errorf("unknown task: %s", taskName)
+		}
+		return nil
+	},
+	Action: func(cctx *cli.Context) error {
+		if err := setupLogging(LilyLogFlags); err != nil {
+			return fmt.Errorf("setup logging: %w", err)
+		}
+
+		// use command context to allowing killing the index at any point via ctrl+c
+		ctx := cctx.Context
+
+		name := indexCarFlags.name
+		if name == "" {
+			name = fmt.Sprintf("index-car_%d", time.Now().Unix())
+		}
+
+		cfgPath, err := homedir.Expand(indexCarFlags.config)
+		if err != nil {
+			return fmt.Errorf("expand config path (%s): %w", indexCarFlags.config, err)
+		}
+		cfg, err := config.FromFile(cfgPath)
+		if err != nil {
+			return fmt.Errorf("read config (%s): %w", cfgPath, err)
+		}
+		taskCfg, err := modules.NewTaskConfig(cfg)
+		if err != nil {
+			return err
+		}
+
+		strg, closeStorage, err := openIndexCarStorage(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+		defer closeStorage()
+
+		node, closer, err := openCarAPI(ctx, indexCarFlags.car, indexCarFlags.tmpDir)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		taskAPI, err := datasource.NewDataSource(node)
+		if err != nil {
+			return err
+		}
+
+		idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, name, tipset.WithTaskConfig(*taskCfg)))
+		if err != nil {
+			return err
+		}
+
+		tasks := indexCarFlags.tasks.Value()
+		log.Infow("indexing car file", "car", indexCarFlags.car, "from", indexCarFlags.from, "to", indexCarFlags.to, "tasks", tasks, "reporter", name)
+		return walk.NewWalker(idx, node, name, tasks, indexCarFlags.from, indexCarFlags.to, &schedule.Reporter{}, indexCarFlags.stopOnError, indexCarFlags.interval).Run(ctx)
+	},
+}
+
+// openIndexCarStorage connects to the storage named by --storage in cfg.
+func openIndexCarStorage(ctx context.Context, cfg *config.Conf, name string) (model.Storage, func(), error) {
+	if indexCarFlags.storage == "" {
+		return &storage.NullStorage{}, func() {}, nil
+	}
+
+	catalog, err := storage.NewCatalog(cfg.Storage)
+	if err != nil {
+		return nil, nil, err
+	}
+	strg, err := catalog.Connect(ctx, indexCarFlags.storage, storage.Metadata{JobName: name})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return strg, func() {
+		// finalize any partially written storage output.
+		if err := catalog.Close(context.Background()); err != nil {
+			log.Errorw("failed to close storage", "error", err.Error())
+		}
+	}, nil
+}
+
+// openCarAPI imports the CAR file at path into a blockstore in dir and returns a lily API serving the imported chain.
+// If dir is empty a temporary directory is used and removed by the returned closer.
+func openCarAPI(ctx context.Context, path string, dir string) (*lily.LilyNodeAPI, func(), error) {
+	carPath, err := homedir.Expand(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("expand car path (%s): %w", path, err)
+	}
+
+	removeDir := false
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "lily-index-car-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("create temporary directory: %w", err)
+		}
+		removeDir = true
+	} else if dir, err = homedir.Expand(dir); err != nil {
+		return nil, nil, fmt.Errorf("expand directory (%s): %w", dir, err)
+	}
+
+	opts, err := repo.BadgerBlockstoreOptions(repo.UniversalBlockstore, filepath.Join(dir, "blockstore"), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	bs, err := badgerbs.Open(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open blockstore (%s): %w", dir, err)
+	}
+
+	mds := dssync.MutexWrap(datastore.NewMapDatastore())
+	cs := store.NewChainStore(bs, bs, mds, filcns.Weight, nil)
+
+	closer := func() {
+		if err := cs.Close(); err != nil {
+			log.Errorw("failed to close chain store", "error", err.Error())
+		}
+		if err := bs.Close(); err != nil {
+			log.Errorw("failed to close blockstore", "error", err.Error())
+		}
+		if removeDir {
+			if err := os.RemoveAll(dir); err != nil {
+				log.Errorw("failed to remove temporary directory", "dir", dir, "error", err.Error())
+			}
+		}
+	}
+
+	if err := importCar(ctx, cs, carPath); err != nil {
+		closer()
+		return nil, nil, err
+	}
+
+	em := modules.NewBufferedExecMonitor()
+	sm, err := stmgr.NewStateManagerWithUpgradeScheduleAndMonitor(cs, consensus.NewTipSetExecutor(filcns.RewardFunc), vm.Syscalls(ffiwrapper.ProofVerifier), filcns.DefaultUpgradeSchedule(), nil, em, mds, nil)
+	if err != nil {
+		closer()
+		return nil, nil, fmt.Errorf("create state manager: %w", err)
+	}
+
+	return lily.NewOfflineAPI(cs, sm, em), closer, nil
+}
+
+// importCar loads the chain in the CAR file at path into cs and sets the head of cs to the head of the CAR file.
+func importCar(ctx context.Context, cs *store.ChainStore, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open car file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	bufr := bufio.NewReaderSize(f, 1<<20)
+	header, err := bufr.Peek(4)
+	if err != nil {
+		return fmt.Errorf("peek header: %w", err)
+	}
+
+	var r io.Reader = bufr
+	if string(header[1:]) == "\xB5\x2F\xFD" { // zstd
+		zr := zstd.NewReader(bufr)
+		defer func() {
+			if err := zr.Close(); err != nil {
+				log.Errorw("closing zstd reader", "error", err)
+			}
+		}()
+		r = zr
+	}
+
+	log.Infow("importing car file", "car", path)
+	head, genesis, err := cs.Import(ctx, datastore.NewMapDatastore(), r)
+	if err != nil {
+		return fmt.Errorf("import car file: %w", err)
+	}
+	if err := cs.SetGenesis(ctx, genesis); err != nil {
+		return fmt.Errorf("set genesis: %w", err)
+	}
+	if err := cs.ForceHeadSilent(ctx, head); err != nil {
+		return fmt.Errorf("set head: %w", err)
+	}
+	log.Infow("imported car file", "car", path, "head", head.Key().String(), "height", head.Height())
+	return nil
+}
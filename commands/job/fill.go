@@ -28,12 +28,16 @@ As an example, the below command:
   $ lily job run --tasks=block_header,message fill --from=10 --to=20
 fills gaps for block_header and messages tasks from epoch 10 to 20 (inclusive)
 
+The last epoch filled is recorded as a checkpoint in the visor_job_checkpoints table. When --resume is set along with the
+job --name of an earlier run, epochs at or below the checkpoint are skipped.
+
 Constraints:
 - the fill job must be executed AFTER a find job. These jobs must NOT be executed simultaneously.
 `,
 	Flags: []cli.Flag{
 		RangeFromFlag,
 		RangeToFlag,
+		ResumeFlag,
 	},
 	Subcommands: []*cli.Command{
 		GapFillNotifyCmd,
@@ -49,6 +53,9 @@ Constraints:
 				return fmt.Errorf("unknown task: %s", taskName)
 			}
 		}
+		if err := resumeFlags.validate(); err != nil {
+			return err
+		}
 		return rangeFlags.validate()
 	},
 	Action: func(cctx *cli.Context) error {
@@ -64,6 +71,7 @@ Constraints:
 			JobConfig: RunFlags.ParseJobConfig("fill"),
 			To:        rangeFlags.to,
 			From:      rangeFlags.from,
+			Resume:    resumeFlags.resume,
 		})
 		if err != nil {
 			return err
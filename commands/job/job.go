@@ -22,6 +22,9 @@ var JobCmd = &cli.Command{
 		JobStopCmd,
 		JobWaitCmd,
 		JobListCmd,
+		JobPersistCmd,
+		JobForgetCmd,
+		JobWorkerCmd,
 	},
 }
 
@@ -37,6 +40,13 @@ var JobRunCmd = &cli.Command{
 		RunRestartFailure,
 		RunRestartCompletion,
 		StopOnError,
+		RunTaskMaxAttemptsFlag,
+		RunTaskRetryBackoffFlag,
+		RunTaskRetryMaxBackoffFlag,
+		RunTaskRetryFlag,
+	},
+	Before: func(_ *cli.Context) error {
+		return RunFlags.ParseRetryPolicies()
 	},
 	Subcommands: []*cli.Command{
 		WalkCmd,
@@ -45,12 +55,14 @@ var JobRunCmd = &cli.Command{
 		SurveyCmd,
 		GapFillCmd,
 		GapFindCmd,
+		ValidateCmd,
 		TipSetWorkerCmd,
 	},
 }
 
 var jobControlFlags struct {
-	ID int
+	ID   int
+	Name string
 }
 
 var JobStartCmd = &cli.Command{
@@ -99,6 +111,61 @@ var JobStopCmd = &cli.Command{
 	},
 }
 
+var JobPersistCmd = &cli.Command{
+	Name:  "persist",
+	Usage: "persist a job so that it is submitted again when the daemon restarts.",
+	Description: `
+The persist command records the configuration a job was submitted with in the daemon's job registry (jobs.json in
+the lily repo). Persisted jobs are submitted again, with the same name and configuration, each time the daemon starts.
+A persisted job is identified by its name, persisting another job with the same name replaces it.
+`,
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:        "id",
+			Usage:       "Identifier of job to persist",
+			Required:    true,
+			Destination: &jobControlFlags.ID,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return api.LilyJobPersist(ctx, schedule.JobID(jobControlFlags.ID))
+	},
+}
+
+var JobForgetCmd = &cli.Command{
+	Name:  "forget",
+	Usage: "remove a persisted job so that it is no longer submitted when the daemon restarts.",
+	Description: `
+The forget command removes a job from the daemon's job registry. A running job is not stopped, use the stop command
+to stop it.
+`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "name",
+			Usage:       "Name of persisted job to forget",
+			Required:    true,
+			Destination: &jobControlFlags.Name,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return api.LilyJobForget(ctx, jobControlFlags.Name)
+	},
+}
+
 var JobListCmd = &cli.Command{
 	Name:  "list",
 	Usage: "list all jobs and their status",
@@ -2,10 +2,13 @@ package job
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
 	"github.com/filecoin-project/lily/lens/lily"
 )
@@ -23,6 +26,13 @@ type runOpts struct {
 	RestartFailure    bool
 	StopOnError       bool
 	Interval          int
+
+	TaskMaxAttempts     int
+	TaskRetryBackoff    time.Duration
+	TaskRetryMaxBackoff time.Duration
+	TaskRetry           cli.StringSlice
+
+	retry processor.RetryPolicies // parsed from the task retry flags by ParseRetryPolicies
 }
 
 func (r runOpts) ParseJobConfig(kind string) lily.LilyJobConfig {
@@ -38,11 +48,88 @@ func (r runOpts) ParseJobConfig(kind string) lily.LilyJobConfig {
 		RestartOnCompletion: RunFlags.RestartCompletion,
 		RestartDelay:        RunFlags.RestartDelay,
 		StopOnError:         RunFlags.StopOnError,
+		Retry:               RunFlags.retry,
+	}
+}
+
+// ParseRetryPolicies builds the task retry policies from the task retry flags. Each value of --task-retry has the
+// form <task>:<max-attempts>[:<backoff>] and overrides the default policy for a task or task alias.
+func (r *runOpts) ParseRetryPolicies() error {
+	r.retry = processor.RetryPolicies{
+		Default: processor.RetryPolicy{
+			MaxAttempts: r.TaskMaxAttempts,
+			Backoff:     r.TaskRetryBackoff,
+			MaxBackoff:  r.TaskRetryMaxBackoff,
+		},
+	}
+
+	for _, v := range r.TaskRetry.Value() {
+		parts := strings.Split(v, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("invalid task retry %q, expected <task>:<max-attempts>[:<backoff>]", v)
+		}
+		task := parts[0]
+		if _, ok := tasktype.TableLookup[task]; !ok {
+			if _, ok := tasktype.TaskLookup[task]; !ok {
+				return fmt.Errorf("invalid task retry %q, unknown task %q", v, task)
+			}
+		}
+
+		policy := r.retry.Default
+		attempts, err := strconv.Atoi(parts[1])
+		if err != nil || attempts < 1 {
+			return fmt.Errorf("invalid task retry %q, max attempts must be a positive integer", v)
+		}
+		policy.MaxAttempts = attempts
+		if len(parts) == 3 {
+			backoff, err := time.ParseDuration(parts[2])
+			if err != nil {
+				return fmt.Errorf("invalid task retry %q, backoff: %w", v, err)
+			}
+			policy.Backoff = backoff
+		}
+
+		if r.retry.Tasks == nil {
+			r.retry.Tasks = make(map[string]processor.RetryPolicy)
+		}
+		r.retry.Tasks[task] = policy
 	}
+	return nil
 }
 
 var RunFlags runOpts
 
+var RunTaskMaxAttemptsFlag = &cli.IntFlag{
+	Name:        "task-max-attempts",
+	Usage:       "Maximum number of times a task is run for a tipset before it is reported as failed. A task fails when it returns an error or reports errors.",
+	EnvVars:     []string{"LILY_JOB_TASK_MAX_ATTEMPTS"},
+	Value:       1,
+	Destination: &RunFlags.TaskMaxAttempts,
+}
+
+var RunTaskRetryBackoffFlag = &cli.DurationFlag{
+	Name:        "task-retry-backoff",
+	Usage:       "Duration to wait before retrying a failed task, doubled after each further attempt.",
+	EnvVars:     []string{"LILY_JOB_TASK_RETRY_BACKOFF"},
+	Value:       time.Second,
+	Destination: &RunFlags.TaskRetryBackoff,
+}
+
+var RunTaskRetryMaxBackoffFlag = &cli.DurationFlag{
+	Name:        "task-retry-max-backoff",
+	Usage:       "Maximum duration to wait between attempts of a failed task.",
+	EnvVars:     []string{"LILY_JOB_TASK_RETRY_MAX_BACKOFF"},
+	Value:       time.Minute,
+	Destination: &RunFlags.TaskRetryMaxBackoff,
+}
+
+var RunTaskRetryFlag = &cli.StringSliceFlag{
+	Name:        "task-retry",
+	Usage:       "Retry policy for a single task or task alias in the form <task>:<max-attempts>[:<backoff>], overriding --task-max-attempts and --task-retry-backoff. May be repeated.",
+	EnvVars:     []string{"LILY_JOB_TASK_RETRY"},
+	Destination: &RunFlags.TaskRetry,
+}
+
 var RunWindowFlag = &cli.DurationFlag{
 	Name:        "window",
 	Usage:       "Duaration after which job execution will be canceled",
@@ -61,7 +148,7 @@ var RunTaskFlag = &cli.StringSliceFlag{
 
 var RunStorageFlag = &cli.StringFlag{
 	Name:        "storage",
-	Usage:       "Name of storage backend the job will write result to.",
+	Usage:       "Name of storage backend the job will write result to. Use a comma separated list to write to several storages, suffixing a name with ':best-effort' to continue when writing to it fails.",
 	EnvVars:     []string{"LILY_JOB_STORAGE"},
 	Value:       "",
 	Destination: &RunFlags.Storage,
@@ -159,3 +246,24 @@ var IntervalFlag = &cli.IntFlag{
 	Value:       120,
 	Destination: &watchFlags.interval,
 }
+
+type resumeOps struct {
+	resume bool
+}
+
+var resumeFlags resumeOps
+
+func (r resumeOps) validate() error {
+	if resumeFlags.resume && RunFlags.Name == "" {
+		return fmt.Errorf("--resume requires the job --name used by the run being resumed")
+	}
+	return nil
+}
+
+var ResumeFlag = &cli.BoolFlag{
+	Name:        "resume",
+	Usage:       "Continue from the last height checkpointed by a previous run of the job with the same --name",
+	EnvVars:     []string{"LILY_RESUME"},
+	Value:       false,
+	Destination: &resumeFlags.resume,
+}
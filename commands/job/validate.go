@@ -0,0 +1,91 @@
+package job
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/chain/validate"
+	"github.com/filecoin-project/lily/commands"
+	"github.com/filecoin-project/lily/lens/lily"
+
+	lotuscli "github.com/filecoin-project/lotus/cli"
+)
+
+type validateOps struct {
+	checks cli.StringSlice
+}
+
+var validateFlags validateOps
+
+var ValidateChecksFlag = &cli.StringSliceFlag{
+	Name:        "checks",
+	Usage:       fmt.Sprintf("Comma separated list of checks to run, one or more of %s.", strings.Join(validate.AllChecks, ", ")),
+	Value:       cli.NewStringSlice(validate.AllChecks...),
+	Destination: &validateFlags.checks,
+}
+
+//revive:disable
+var ValidateCmd = &cli.Command{
+	Name:  "validate",
+	Usage: "validate data persisted in the database for a given range against chain state.",
+	Description: `
+The validate job reads the rows persisted for each epoch of the specified range (--from --to) and checks them against
+chain state and against each other. The checks (--checks) are:
+- burnt_fil: chain_economics.burnt_fil equals the balance of the burnt funds actor, and its increase between two tipsets
+  is at least the gas burnt by the messages in derived_gas_outputs.
+- messages: the number of messages in the messages table equals the number of distinct messages in block_messages,
+  which equals the number of distinct messages in the tipset.
+- chain_powers: chain_powers and power_actor_claims match the state of the power actor, and the committed power totals
+  equal the sum of the miner claims.
+Discrepancies are written to the visor_validation_reports table with status 'DISCREPANCY', checks that could not be
+completed are written with status 'ERROR'.
+
+As an example, the below command:
+ $ lily job run --storage=Database1 validate --from=10 --to=20 --checks=burnt_fil,messages
+validates the burnt_fil and messages checks from epoch 10 to 20 (inclusive).
+
+Constraints:
+- the validate job must be executed against a range that has been indexed by the tasks the checks read, a find job
+  will report any gaps that must be filled first.
+`,
+	Flags: []cli.Flag{
+		RangeFromFlag,
+		RangeToFlag,
+		ValidateChecksFlag,
+	},
+	Before: func(_ *cli.Context) error {
+		known := make(map[string]bool, len(validate.AllChecks))
+		for _, c := range validate.AllChecks {
+			known[c] = true
+		}
+		for _, c := range validateFlags.checks.Value() {
+			if !known[c] {
+				return fmt.Errorf("unknown check: %s", c)
+			}
+		}
+		return rangeFlags.validate()
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		res, err := api.LilyValidate(ctx, &lily.LilyValidateConfig{
+			JobConfig: RunFlags.ParseJobConfig("validate"),
+			To:        rangeFlags.to,
+			From:      rangeFlags.from,
+			Checks:    validateFlags.checks.Value(),
+		})
+		if err != nil {
+			return err
+		}
+		return commands.PrintNewJob(os.Stdout, res)
+	},
+}
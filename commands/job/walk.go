@@ -15,6 +15,7 @@ import (
 
 type walkOps struct {
 	interval int `zap:"interval"`
+	shards   int `zap:"shards"`
 }
 
 var walkFlags walkOps
@@ -26,6 +27,14 @@ var WalkIntervalFlag = &cli.IntFlag{
 	Destination: &walkFlags.interval,
 }
 
+var WalkShardsFlag = &cli.IntFlag{
+	Name:        "shards",
+	Usage:       "Split the range into `N` contiguous height ranges that are walked concurrently",
+	EnvVars:     []string{"LILY_WALK_SHARDS"},
+	Value:       1,
+	Destination: &walkFlags.shards,
+}
+
 //revive:disable
 var WalkCmd = &cli.Command{
 	Name:  "walk",
@@ -38,11 +47,27 @@ As and example, the below command:
   $ lily job run --tasks=block_header,messages walk --from=10 --to=20
 walks epochs 20 through 10 (inclusive) executing the block_header and messages task for each epoch.
 The status of each epoch and its set of tasks can be observed in the visor_processing_reports table.
+
+The walk records the last epoch it completed as a checkpoint in the storage (the visor_job_checkpoints table, or a
+<name>.checkpoint.json file alongside CSV and Parquet files). A walk writing to a list of storages records the checkpoint
+in each of them that supports checkpoints. A walk that was interrupted may be continued with --resume and the same job
+--name:
+  $ lily job run --name=backfill --tasks=block_header walk --from=10 --to=20 --resume
+
+Large ranges may be split into shards (--shards) that are walked concurrently, each shard indexing its own part of the
+range serially. The below command walks epochs 1000 through 1999 and 2000 through 2999 at the same time:
+  $ lily job run --name=backfill --tasks=block_header walk --from=1000 --to=2999 --shards=2
+The progress of each shard is shown by lily job list. Each shard records its own checkpoint and the walk itself is
+checkpointed at the lowest height every higher shard has been indexed down to. A sharded walk resumed with the same
+--from, --to and --shards continues each shard from its checkpoint, one resumed with a different --shards continues from
+the checkpoint of the walk.
 `,
 	Flags: []cli.Flag{
 		RangeFromFlag,
 		RangeToFlag,
+		ResumeFlag,
 		WalkIntervalFlag,
+		WalkShardsFlag,
 	},
 	Subcommands: []*cli.Command{
 		WalkNotifyCmd,
@@ -58,6 +83,12 @@ The status of each epoch and its set of tasks can be observed in the visor_proce
 				return fmt.Errorf("unknown task: %s", taskName)
 			}
 		}
+		if err := resumeFlags.validate(); err != nil {
+			return err
+		}
+		if walkFlags.shards < 1 {
+			return fmt.Errorf("value of --shards (%d) should be >= 1", walkFlags.shards)
+		}
 		return rangeFlags.validate()
 	},
 	Action: func(cctx *cli.Context) error {
@@ -74,6 +105,8 @@ The status of each epoch and its set of tasks can be observed in the visor_proce
 			From:      rangeFlags.from,
 			To:        rangeFlags.to,
 			Interval:  walkFlags.interval,
+			Resume:    resumeFlags.resume,
+			Shards:    walkFlags.shards,
 		}
 
 		res, err := api.LilyWalk(ctx, cfg)
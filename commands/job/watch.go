@@ -3,6 +3,7 @@ package job
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
@@ -19,6 +20,9 @@ type watchOps struct {
 	workers    int
 	bufferSize int
 	interval   int
+
+	leaderLock         string
+	leaderLockInterval time.Duration
 }
 
 var watchFlags watchOps
@@ -52,6 +56,21 @@ var WatchBufferSizeFlag = &cli.IntFlag{
 	Destination: &watchFlags.bufferSize,
 }
 
+var WatchLeaderLockFlag = &cli.StringFlag{
+	Name:        "leader-lock",
+	Usage:       "Name of a lock to contend for with other lily daemons watching the chain into the same storage. Only the daemon holding the lock indexes, the others wait in standby.",
+	EnvVars:     []string{"LILY_WATCH_LEADER_LOCK"},
+	Destination: &watchFlags.leaderLock,
+}
+
+var WatchLeaderLockIntervalFlag = &cli.DurationFlag{
+	Name:        "leader-lock-interval",
+	Usage:       "How often a standby daemon tries to take the leader lock, and the daemon holding it checks it still does.",
+	EnvVars:     []string{"LILY_WATCH_LEADER_LOCK_INTERVAL"},
+	Value:       schedule.DefaultLockRetryInterval,
+	Destination: &watchFlags.leaderLockInterval,
+}
+
 //revive:disable
 var WatchCmd = &cli.Command{
 	Name:  "watch",
@@ -89,15 +108,34 @@ the --confidence flag may be used to buffer the amount of tipsets observed befor
                                              └────────┘      └────────┘
                                               (process)       (process)
 
+If a reorg is deeper than the confidence window, tipsets that have already been indexed are reverted: they are recorded
+in the visor_reverted_tipsets table and, when the storage is a postgres database, rows persisted for the reverted tipset's
+height and state root are removed. Tables without a state root whose rows are derived only from the tipset at their
+height, such as messages, receipts of FEVM messages and event tables, are reverted by height. Other tables without a
+state root, such as block_headers, are not reverted.
+
 As and example, the below command:
   $ lily job run --tasks-block_header,messages watch --confidence=10 --workers=2
 watches the chain head and only indexes a tipset after observing 10 subsequent tipsets indexing at most two tipset simultaneously.
+
+Several lily daemons may watch the chain into the same storage in active/standby mode by giving their watch jobs the
+same --leader-lock name. Only the daemon holding the lock indexes tipsets, the others check every --leader-lock-interval
+and take over when the lock is released, for example because the daemon holding it stopped. The lock is a postgres
+advisory lock held by a connection of the daemon when the storage is a database, and a lock file in the output directory
+when it is a CSV or Parquet storage. A list of storages or a routing storage takes the lock in the first of its storages
+that supports locks. The state of the lock is shown by 'lily job list' and the job_lock_held metric.
+
+As an example, the below command run by each daemon:
+  $ lily job run --storage=db watch --leader-lock=mainnet
+indexes the chain head from a single daemon at a time.
 `,
 	Flags: []cli.Flag{
 		WatchConfidenceFlag,
 		WatchWorkersFlag,
 		WatchBufferSizeFlag,
 		WatchIntervalFlag,
+		WatchLeaderLockFlag,
+		WatchLeaderLockIntervalFlag,
 	},
 	Before: func(cctx *cli.Context) error {
 		tasks := RunFlags.Tasks.Value()
@@ -131,6 +169,9 @@ watches the chain head and only indexes a tipset after observing 10 subsequent t
 			Confidence: watchFlags.confidence,
 			Workers:    watchFlags.workers,
 			Interval:   watchFlags.interval,
+
+			LeaderLock:         watchFlags.leaderLock,
+			LeaderLockInterval: watchFlags.leaderLockInterval,
 		}
 
 		res, err = api.LilyWatch(ctx, cfg)
@@ -1,6 +1,8 @@
 package job
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/urfave/cli/v2"
@@ -47,3 +49,145 @@ var TipSetWorkerCmd = &cli.Command{
 		return commands.PrintNewJob(os.Stdout, res)
 	},
 }
+
+var workerFailedFlags struct {
+	queue       string
+	indexQueues cli.StringSlice
+	ids         cli.StringSlice
+	all         bool
+}
+
+var JobWorkerCmd = &cli.Command{
+	Name:  "worker",
+	Usage: "Manage the work of distributed tipset-workers.",
+	Subcommands: []*cli.Command{
+		WorkerFailedCmd,
+	},
+}
+
+var WorkerFailedCmd = &cli.Command{
+	Name:  "failed",
+	Usage: "Inspect tasks that exhausted their retries and were archived by a queue.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "queue",
+			Usage:       "Name of the worker, notifier or postgresql queue config to inspect.",
+			Required:    true,
+			Destination: &workerFailedFlags.queue,
+		},
+		&cli.StringSliceFlag{
+			Name:        "index-queue",
+			Usage:       "Limit to tasks enqueued by this type of job, one of watch, walk, index or fill. May be repeated. Defaults to all.",
+			Destination: &workerFailedFlags.indexQueues,
+		},
+	},
+	Subcommands: []*cli.Command{
+		WorkerFailedListCmd,
+		WorkerFailedRetryCmd,
+		WorkerFailedPurgeCmd,
+	},
+}
+
+var WorkerFailedListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List archived index and gap fill tasks with their tipset, tasks and last error.",
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		failed, err := api.LilyWorkerFailedList(ctx, workerFailedConfig())
+		if err != nil {
+			return err
+		}
+		prettyFailed, err := json.MarshalIndent(failed, "", "\t")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(os.Stdout, "%s\n", prettyFailed); err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var workerFailedSelectFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:        "id",
+		Usage:       "Identifier of an archived task. May be repeated.",
+		Destination: &workerFailedFlags.ids,
+	},
+	&cli.BoolFlag{
+		Name:        "all",
+		Usage:       "Select all archived tasks.",
+		Destination: &workerFailedFlags.all,
+	},
+}
+
+var WorkerFailedRetryCmd = &cli.Command{
+	Name:   "retry",
+	Usage:  "Return archived tasks to their queue so they are run again.",
+	Flags:  workerFailedSelectFlags,
+	Before: checkWorkerFailedSelection,
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		count, err := api.LilyWorkerFailedRetry(ctx, workerFailedConfig())
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(os.Stdout, "retried %d tasks\n", count)
+		return err
+	},
+}
+
+var WorkerFailedPurgeCmd = &cli.Command{
+	Name:   "purge",
+	Usage:  "Delete archived tasks.",
+	Flags:  workerFailedSelectFlags,
+	Before: checkWorkerFailedSelection,
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		count, err := api.LilyWorkerFailedPurge(ctx, workerFailedConfig())
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(os.Stdout, "purged %d tasks\n", count)
+		return err
+	},
+}
+
+// checkWorkerFailedSelection ensures exactly one of --id or --all was given, so that all archived tasks are never
+// retried or purged by accident.
+func checkWorkerFailedSelection(_ *cli.Context) error {
+	hasIDs := len(workerFailedFlags.ids.Value()) > 0
+	if hasIDs == workerFailedFlags.all {
+		return fmt.Errorf("exactly one of --id or --all must be given")
+	}
+	return nil
+}
+
+func workerFailedConfig() *lily.LilyWorkerFailedConfig {
+	cfg := &lily.LilyWorkerFailedConfig{
+		Queue:       workerFailedFlags.queue,
+		IndexQueues: workerFailedFlags.indexQueues.Value(),
+	}
+	if !workerFailedFlags.all {
+		cfg.IDs = workerFailedFlags.ids.Value()
+	}
+	return cfg
+}
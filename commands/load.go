@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/storage"
+)
+
+type loadOps struct {
+	from        string
+	config      string
+	storage     string
+	tables      cli.StringSlice
+	jobName     string
+	omitHeader  bool
+	filePattern string
+}
+
+var loadFlags loadOps
+
+var LoadCmd = &cli.Command{
+	Name:  "load",
+	Usage: "Load CSV files written by lily into a database storage.",
+	Description: `
+The load command reads the CSV files written by a CSV storage to the directory given by --from and upserts their rows
+into the postgresql storage named by --storage in the config file named by --config. Rows are upserted on the primary
+key of their table, each file being loaded in its own transaction.
+
+The files are matched to tables using the same file pattern as the CSV storage that wrote them (--file-pattern), with
+the {jobname} token replaced by --job-name or matching every job when it is not set. When the files were written with
+OmitHeader unset their headers are checked against the columns of their table. The database schema must be at the
+version supported by this version of lily, which is also the version of the CSV files it writes.
+
+  $ lily load --from=/data/csv --storage=Database1 --config=~/.lily/config.toml
+`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "from",
+			Usage:       "`DIR` holding the CSV files to load",
+			Required:    true,
+			Destination: &loadFlags.from,
+		},
+		&cli.StringFlag{
+			Name:        "config",
+			Usage:       "Specify path of config file that defines --storage.",
+			EnvVars:     []string{"LILY_CONFIG"},
+			Value:       "~/.lily/config.toml",
+			Destination: &loadFlags.config,
+		},
+		&cli.StringFlag{
+			Name:        "storage",
+			Usage:       "Name of the postgresql storage defined in the config file that the CSV files are loaded into.",
+			Required:    true,
+			Destination: &loadFlags.storage,
+		},
+		&cli.StringSliceFlag{
+			Name:        "tables",
+			Usage:       "Comma separated list of tables to load. All tables are loaded when not set.",
+			Destination: &loadFlags.tables,
+		},
+		&cli.StringFlag{
+			Name:        "job-name",
+			Usage:       "Name of the job that wrote the CSV files, replaces {jobname} in --file-pattern.",
+			Value:       "",
+			Destination: &loadFlags.jobName,
+		},
+		&cli.BoolFlag{
+			Name:        "omit-header",
+			Usage:       "The CSV files were written without a header row.",
+			Value:       false,
+			Destination: &loadFlags.omitHeader,
+		},
+		&cli.StringFlag{
+			Name:        "file-pattern",
+			Usage:       "Pattern of the CSV file names, may contain the tokens {table} and {jobname}.",
+			Value:       storage.DefaultFilePattern,
+			Destination: &loadFlags.filePattern,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if err := setupLogging(LilyLogFlags); err != nil {
+			return fmt.Errorf("setup logging: %w", err)
+		}
+
+		ctx := cctx.Context
+
+		cfgPath, err := homedir.Expand(loadFlags.config)
+		if err != nil {
+			return fmt.Errorf("expand config path (%s): %w", loadFlags.config, err)
+		}
+		cfg, err := config.FromFile(cfgPath)
+		if err != nil {
+			return fmt.Errorf("read config (%s): %w", cfgPath, err)
+		}
+		dir, err := homedir.Expand(loadFlags.from)
+		if err != nil {
+			return fmt.Errorf("expand directory (%s): %w", loadFlags.from, err)
+		}
+
+		catalog, err := storage.NewCatalog(cfg.Storage)
+		if err != nil {
+			return err
+		}
+		db, err := catalog.ConnectAsDatabase(ctx, loadFlags.storage, storage.Metadata{})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := catalog.Close(context.Background()); err != nil {
+				log.Errorw("failed to close storage", "error", err.Error())
+			}
+		}()
+
+		loaded, err := db.LoadCSV(ctx, dir, storage.CSVLoadOptions{
+			CSVStorageOptions: storage.CSVStorageOptions{
+				OmitHeader:  loadFlags.omitHeader,
+				FilePattern: loadFlags.filePattern,
+			},
+			JobName: loadFlags.jobName,
+			Tables:  loadFlags.tables.Value(),
+		})
+		if err != nil {
+			return err
+		}
+
+		tables := make([]string, 0, len(loaded))
+		for name := range loaded {
+			tables = append(tables, name)
+		}
+		sort.Strings(tables)
+		for _, name := range tables {
+			fmt.Printf("%s\t%d\n", name, loaded[name])
+		}
+		return nil
+	},
+}
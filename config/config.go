@@ -24,11 +24,17 @@ type Conf struct {
 	Chainstore config.Chainstore
 	Storage    StorageConf
 	Queue      QueueConfig
+	// BuiltinActorEvents selects the built-in actor events written by the builtin_actor_event and event_* tasks.
+	BuiltinActorEvents BuiltinActorEventConf
+	// ContractEvents holds the named filters selecting the FEVM contract events written by the contract_events task.
+	ContractEvents map[string]ContractEventFilterConf
 }
 
 type StorageConf struct {
 	Postgresql map[string]PgStorageConf
 	File       map[string]FileStorageConf
+	Stream     map[string]StreamStorageConf
+	Routing    map[string]RoutingStorageConf
 }
 
 type PgStorageConf struct {
@@ -45,11 +51,103 @@ type FileStorageConf struct {
 	Path        string
 	OmitHeader  bool   // when true, don't write column headers to new output files
 	FilePattern string // pattern to use for filenames written in the path specified
+	HeightRange int64  // number of epochs written to each file before rolling over to a new one, Parquet only
+}
+
+// RoutingStorageConf configures a storage that writes the data of each table to the storage named by its route. Storage
+// names may be any storage defined in the Postgresql, File or Stream sections, or a comma separated list of them.
+type RoutingStorageConf struct {
+	Default string            // name of the storage that tables without a route are written to, may be empty to discard them
+	Routes  map[string]string // maps table names, as in the database schema, to the name of the storage they are written to
+}
+
+type StreamStorageConf struct {
+	Transport    string // transport used to publish messages, currently only Redis streams are supported
+	URLEnv       string // name of an environment variable that contains the transport URL
+	URL          string // URL used to connect to the transport if URLEnv is not set
+	Encoding     string // encoding of published messages, either JSON or CBOR
+	TopicPattern string // pattern to use for topic names, defaults to the table name
+	MaxLen       int64  // approximate maximum number of messages retained per topic, zero means unlimited
+}
+
+// BuiltinActorEventConf selects the built-in actor events to be indexed.
+type BuiltinActorEventConf struct {
+	Events []string // event types such as deal-activated, see FIP-0083; empty indexes every type emitted by the built-in actors
+}
+
+// ContractEventFilterConf selects FEVM contract events to be written to the contract_events table. An event matches
+// the filter when it was emitted by one of its emitters and its first topic is one of its signatures. An event is written
+// once for each filter it matches.
+type ContractEventFilterConf struct {
+	Emitters []string // addresses of the contracts emitting the events, as f410, ID or 0x addresses; empty matches any contract
+	Topic0   []string // event signatures such as Transfer(address,address,uint256), or their 0x prefixed keccak256 hashes; empty matches any event
+	ABI      string   // optional JSON ABI of the contracts, or the path of a file holding it, used to decode the arguments of the events
 }
 
 type QueueConfig struct {
-	Workers   map[string]AsynqWorkerConfig
-	Notifiers map[string]RedisConfig
+	Workers    map[string]AsynqWorkerConfig
+	Notifiers  map[string]RedisConfig
+	Postgresql map[string]PgQueueConfig
+	MaxRetry   MaxRetryConfig
+}
+
+// PgQueueConfig configures a queue held in the visor_queue_tasks table of a postgres database lily writes to. The same
+// queue config is used by notifiers to enqueue tipsets and by tipset-workers to process them.
+type PgQueueConfig struct {
+	// Storage is the name of the Postgresql storage config holding the queue table.
+	Storage string
+
+	// WorkerConfig configures the concurrency and queue priorities of tipset-workers consuming from the queue.
+	// LoggerLevel is unused.
+	WorkerConfig WorkerConfig
+
+	// PollInterval is how long an idle worker waits before checking the queue for new tasks.
+	//
+	// If unset or zero, default interval of 1 second is used.
+	PollInterval time.Duration
+
+	// LeaseTimeout is how long a task may be held by a worker that has stopped renewing its lease before the task is
+	// made available to other workers. Workers renew the lease of the tasks they are processing every third of this
+	// timeout.
+	//
+	// If unset or zero, default timeout of 5 minutes is used.
+	LeaseTimeout time.Duration
+}
+
+// DefaultQueueMaxRetry is the number of times a task is retried when the queue it was enqueued on has no MaxRetry set.
+const DefaultQueueMaxRetry = 3
+
+// MaxRetryConfig sets the number of times a task is retried on each queue before it is archived. Archived tasks may be
+// inspected, retried or purged with the `lily job worker failed` commands. A value of zero uses DefaultQueueMaxRetry,
+// a negative value disables retries.
+type MaxRetryConfig struct {
+	WatchQueueMaxRetry int
+	FillQueueMaxRetry  int
+	IndexQueueMaxRetry int
+	WalkQueueMaxRetry  int
+}
+
+// For returns the max retry count for the named queue.
+func (m MaxRetryConfig) For(queue string) int {
+	var n int
+	switch queue {
+	case indexer.Watch.String():
+		n = m.WatchQueueMaxRetry
+	case indexer.Fill.String():
+		n = m.FillQueueMaxRetry
+	case indexer.Index.String():
+		n = m.IndexQueueMaxRetry
+	case indexer.Walk.String():
+		n = m.WalkQueueMaxRetry
+	}
+	switch {
+	case n == 0:
+		return DefaultQueueMaxRetry
+	case n < 0:
+		return 0
+	default:
+		return n
+	}
 }
 
 type AsynqWorkerConfig struct {
@@ -197,6 +295,39 @@ func SampleConf() *Conf {
 				OmitHeader:  false,
 				FilePattern: "{table}.csv",
 			},
+			"Parquet": {
+				Format:      "Parquet",
+				Path:        "/tmp",
+				FilePattern: "{table}-{from}-{to}.parquet",
+				HeightRange: 2880,
+			},
+		},
+
+		Stream: map[string]StreamStorageConf{
+			"Stream1": {
+				Transport:    "Redis",
+				URL:          "redis://127.0.0.1:6379/0",
+				Encoding:     "JSON",
+				TopicPattern: "lily.{table}",
+			},
+		},
+
+		Routing: map[string]RoutingStorageConf{
+			"Tiered": {
+				Default: "Database1",
+				Routes: map[string]string{
+					"vm_messages":  "Parquet",
+					"actor_states": "Parquet",
+					"fevm_traces":  "Parquet",
+				},
+			},
+		},
+	}
+	cfg.ContractEvents = map[string]ContractEventFilterConf{
+		"ERC20Transfers": {
+			Emitters: []string{"0x60e1773636cf5e4a227d9ac24f20feca034ee25a"},
+			Topic0:   []string{"Transfer(address,address,uint256)"},
+			ABI:      `[{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}]`,
 		},
 	}
 	cfg.Queue = QueueConfig{
@@ -234,6 +365,12 @@ func SampleConf() *Conf {
 				PoolSize:    0,
 			},
 		},
+		MaxRetry: MaxRetryConfig{
+			WatchQueueMaxRetry: DefaultQueueMaxRetry,
+			FillQueueMaxRetry:  DefaultQueueMaxRetry,
+			IndexQueueMaxRetry: DefaultQueueMaxRetry,
+			WalkQueueMaxRetry:  DefaultQueueMaxRetry,
+		},
 	}
 
 	return &cfg
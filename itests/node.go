@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/commands"
 	"github.com/filecoin-project/lily/commands/util"
 	"github.com/filecoin-project/lily/config"
@@ -69,6 +70,7 @@ func NewTestNode(ctx context.Context, t testing.TB, cfg *TestNodeConfig) (lily.L
 		node.Override(new(*schedule.Scheduler), schedule.NewSchedulerDaemon),
 		node.Override(new(*storage.Catalog), modules.NewStorageCatalog),
 		node.Override(new(*distributed.Catalog), modules.NewQueueCatalog),
+		node.Override(new(*processor.TaskConfig), modules.NewTaskConfig),
 		// End Injection
 
 		node.Override(new(dtypes.Bootstrapper), false),
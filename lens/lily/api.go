@@ -10,6 +10,8 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/schedule"
 
 	"github.com/filecoin-project/lotus/api"
@@ -36,10 +38,26 @@ type LilyAPI interface {
 	LilyJobWait(ctx context.Context, ID schedule.JobID) (*schedule.JobListResult, error)
 	LilyJobList(ctx context.Context) ([]schedule.JobListResult, error)
 
+	// LilyJobPersist records the submission of a job in the job registry so that it is submitted again when the daemon restarts.
+	LilyJobPersist(ctx context.Context, ID schedule.JobID) error
+	// LilyJobForget removes the named job from the job registry.
+	LilyJobForget(ctx context.Context, name string) error
+
 	LilyGapFind(ctx context.Context, cfg *LilyGapFindConfig) (*schedule.JobSubmitResult, error)
 	LilyGapFill(ctx context.Context, cfg *LilyGapFillConfig) (*schedule.JobSubmitResult, error)
 	LilyGapFillNotify(ctx context.Context, cfg *LilyGapFillNotifyConfig) (*schedule.JobSubmitResult, error)
 
+	// LilyValidate submits a job that checks persisted data against chain state and records discrepancies in the
+	// visor_validation_reports table.
+	LilyValidate(ctx context.Context, cfg *LilyValidateConfig) (*schedule.JobSubmitResult, error)
+
+	// LilyWorkerFailedList returns the index and gap fill tasks archived by a queue after exhausting their retries.
+	LilyWorkerFailedList(ctx context.Context, cfg *LilyWorkerFailedConfig) ([]*queue.FailedTask, error)
+	// LilyWorkerFailedRetry returns archived tasks to their queue and returns the number of tasks retried.
+	LilyWorkerFailedRetry(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error)
+	// LilyWorkerFailedPurge deletes archived tasks and returns the number of tasks deleted.
+	LilyWorkerFailedPurge(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error)
+
 	// SyncState returns the current status of the chain sync system.
 	SyncState(context.Context) (*api.SyncState, error) //perm:read
 
@@ -109,8 +127,11 @@ type LilyJobConfig struct {
 	StopOnError bool
 	// RestartDelay configures how long to wait before restarting the job.
 	RestartDelay time.Duration
-	// Storage is the name of the storage system the job will use, may be empty.
+	// Storage is the name of the storage system the job will use, may be empty. A comma separated list of names writes
+	// the results of the job to each storage, a name may be suffixed with ":best-effort" to ignore its failures.
 	Storage string
+	// Retry configures how tasks that fail while indexing a tipset are retried, by default they are not.
+	Retry processor.RetryPolicies
 }
 
 type LilyWatchConfig struct {
@@ -120,6 +141,9 @@ type LilyWatchConfig struct {
 	Confidence int
 	Workers    int // number of indexing jobs that can run in parallel
 	Interval   int
+
+	LeaderLock         string        // name of a lock in the storage to contend for with other daemons, empty to always index
+	LeaderLockInterval time.Duration // how often a standby daemon tries to take the lock and the leader checks it holds it
 }
 
 type LilyWatchNotifyConfig struct {
@@ -136,6 +160,10 @@ type LilyWalkConfig struct {
 	From     int64
 	To       int64
 	Interval int
+	// Resume when true continues the walk from the last height checkpointed by a previous run of the job.
+	Resume bool
+	// Shards is the number of height ranges the walk is split into and walked concurrently.
+	Shards int
 }
 
 type LilyWalkNotifyConfig struct {
@@ -144,6 +172,16 @@ type LilyWalkNotifyConfig struct {
 	Queue string
 }
 
+type LilyWorkerFailedConfig struct {
+	// Queue is the name of a worker or notifier queue from the daemon config.
+	Queue string
+	// IndexQueues limits the operation to tasks enqueued on these queues (watch, walk, index or fill), all queues are
+	// used when empty.
+	IndexQueues []string
+	// IDs selects the tasks to retry or purge, all archived tasks are selected when empty.
+	IDs []string
+}
+
 type LilyGapFindConfig struct {
 	JobConfig LilyJobConfig
 
@@ -151,11 +189,22 @@ type LilyGapFindConfig struct {
 	From int64
 }
 
+type LilyValidateConfig struct {
+	JobConfig LilyJobConfig
+
+	To   int64
+	From int64
+	// Checks are the names of the checks to run, all checks are run if empty.
+	Checks []string
+}
+
 type LilyGapFillConfig struct {
 	JobConfig LilyJobConfig
 
 	To   int64
 	From int64
+	// Resume when true skips heights at or below the last height checkpointed by a previous run of the job.
+	Resume bool
 }
 
 type LilyGapFillNotifyConfig struct {
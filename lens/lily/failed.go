@@ -0,0 +1,37 @@
+package lily
+
+import (
+	"context"
+
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
+)
+
+func (m *LilyNodeAPI) LilyWorkerFailedList(ctx context.Context, cfg *LilyWorkerFailedConfig) ([]*queue.FailedTask, error) {
+	var out []*queue.FailedTask
+	err := m.failedTasks(ctx, cfg.Queue, func(ft queue.FailedTasks) error {
+		var err error
+		out, err = ft.List(ctx, cfg.IndexQueues...)
+		return err
+	})
+	return out, err
+}
+
+func (m *LilyNodeAPI) LilyWorkerFailedRetry(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error) {
+	var count int
+	err := m.failedTasks(ctx, cfg.Queue, func(ft queue.FailedTasks) error {
+		var err error
+		count, err = ft.Retry(ctx, cfg.IndexQueues, cfg.IDs)
+		return err
+	})
+	return count, err
+}
+
+func (m *LilyNodeAPI) LilyWorkerFailedPurge(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error) {
+	var count int
+	err := m.failedTasks(ctx, cfg.Queue, func(ft queue.FailedTasks) error {
+		var err error
+		count, err = ft.Purge(ctx, cfg.IndexQueues, cfg.IDs)
+		return err
+	})
+	return count, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/ipfs/go-cid"
@@ -24,12 +25,15 @@ import (
 	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/chain/indexer/distributed/queue/tasks"
 	"github.com/filecoin-project/lily/chain/indexer/integrated"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
+	"github.com/filecoin-project/lily/chain/validate"
 	"github.com/filecoin-project/lily/chain/walk"
 	"github.com/filecoin-project/lily/chain/watch"
 	"github.com/filecoin-project/lily/lens"
 	"github.com/filecoin-project/lily/lens/lily/modules"
 	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model/visor"
 	"github.com/filecoin-project/lily/network"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
@@ -71,8 +75,24 @@ type LilyNodeAPI struct {
 	StorageCatalog *storage.Catalog
 	QueueCatalog   *distributed.Catalog
 
+	// JobRegistry holds job submissions that are submitted again when the daemon starts.
+	JobRegistry *schedule.JobRegistry
+	// TaskConfig holds the configuration of the tasks run by jobs.
+	TaskConfig *processor.TaskConfig
+
 	actorStore     adt.Store
 	actorStoreInit sync.Once
+
+	submissionsMu sync.Mutex
+	submissions   map[schedule.JobID]schedule.PersistedJob // submissions that may be persisted, by job id
+}
+
+// taskConfig returns the configuration of the tasks run by jobs, the defaults if none was given.
+func (m *LilyNodeAPI) taskConfig() processor.TaskConfig {
+	if m.TaskConfig == nil {
+		return processor.TaskConfig{}
+	}
+	return *m.TaskConfig
 }
 
 func (m *LilyNodeAPI) Host() host.Host {
@@ -92,7 +112,7 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 		return nil, err
 	}
 
-	worker, err := m.QueueCatalog.Worker(cfg.Queue)
+	queues, err := m.QueueCatalog.WorkerQueues(cfg.Queue)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +122,7 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 		return nil, err
 	}
 
-	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name))
+	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name, tipset.WithRetryPolicies(cfg.JobConfig.Retry), tipset.WithTaskConfig(m.taskConfig())))
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +130,7 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 	handlers := []queue.TaskHandler{tasks.NewIndexHandler(im)}
 	// check if queue config contains configuration for gap fill tasks and if it expects the tasks to be processed. This
 	// is specified by giving the Fill queue a priority greater than 1.
-	priority, ok := worker.ServerConfig.Queues[indexer.Fill.String()]
+	priority, ok := queues[indexer.Fill.String()]
 	if ok {
 		if priority > 0 {
 			// if gap fill tasks have a priority storage must be a database.
@@ -123,6 +143,11 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 		}
 	}
 
+	worker, err := m.tipSetWorker(ctx, cfg.Queue, cfg.JobConfig.Name, md, handlers...)
+	if err != nil {
+		return nil, err
+	}
+
 	res := m.Scheduler.Submit(&schedule.JobConfig{
 		Name: cfg.JobConfig.Name,
 		Type: "tipset-worker",
@@ -130,11 +155,12 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 			"queue":   cfg.Queue,
 			"storage": cfg.JobConfig.Storage,
 		},
-		Job:                 queue.NewAsynqWorker(cfg.JobConfig.Name, worker, handlers...),
+		Job:                 worker,
 		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 	})
+	m.trackSubmission(res, "StartTipSetWorker", cfg)
 	return res, nil
 }
 
@@ -157,7 +183,7 @@ func (m *LilyNodeAPI) LilyIndex(_ context.Context, cfg *LilyIndexConfig) (interf
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name, tipset.WithRetryPolicies(cfg.JobConfig.Retry), tipset.WithTaskConfig(m.taskConfig())), integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
@@ -168,15 +194,29 @@ func (m *LilyNodeAPI) LilyIndex(_ context.Context, cfg *LilyIndexConfig) (interf
 	}
 
 	success, err := im.TipSet(ctx, ts, indexer.WithTasks(cfg.JobConfig.Tasks))
+	if err != nil {
+		return success, err
+	}
+
+	if cp, ok := storage.CheckpointerOf(strg); ok && success {
+		if err := cp.SaveCheckpoint(ctx, &visor.JobCheckpoint{
+			JobName:   cfg.JobConfig.Name,
+			JobType:   "index",
+			Height:    int64(ts.Height()),
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			log.Errorw("failed to save index checkpoint", "error", err, "height", ts.Height(), "job", cfg.JobConfig.Name)
+		}
+	}
 
-	return success, err
+	return success, nil
 }
 
 func (m *LilyNodeAPI) LilyIndexNotify(_ context.Context, cfg *LilyIndexNotifyConfig) (interface{}, error) {
 	// the context's passed to these methods live for the duration of the clients request, so make a new one.
 	ctx := context.Background()
 
-	notifier, err := m.QueueCatalog.Notifier(cfg.Queue)
+	q, err := m.tipSetQueue(ctx, cfg.Queue, storage.Metadata{JobName: cfg.IndexConfig.JobConfig.Name})
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +226,7 @@ func (m *LilyNodeAPI) LilyIndexNotify(_ context.Context, cfg *LilyIndexNotifyCon
 		return nil, err
 	}
 
-	idx := distributed.NewTipSetIndexer(queue.NewAsynq(notifier))
+	idx := distributed.NewTipSetIndexer(q)
 
 	return idx.TipSet(ctx, ts, indexer.WithIndexerType(indexer.Index), indexer.WithTasks(cfg.IndexConfig.JobConfig.Tasks))
 }
@@ -221,7 +261,7 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name, tipset.WithRetryPolicies(cfg.JobConfig.Retry), tipset.WithTaskConfig(m.taskConfig())), integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +274,7 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 		watch.WithConcurrentWorkers(cfg.Workers),
 		watch.WithBufferSize(cfg.BufferSize),
 		watch.WithInterval(cfg.Interval),
+		watch.WithRevertHandler(watch.NewStorageRevertHandler(strg, cfg.JobConfig.Name)),
 	)
 	jobConfig := &schedule.JobConfig{
 		Name: cfg.JobConfig.Name,
@@ -254,7 +295,20 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 		Reporter:            reporter,
 	}
 
+	// only the daemon holding the leader lock indexes, the others wait in standby to take over when it is released.
+	if cfg.LeaderLock != "" {
+		lock, err := m.StorageCatalog.Lock(ctx, cfg.JobConfig.Storage, cfg.LeaderLock)
+		if err != nil {
+			return nil, fmt.Errorf("leader lock: %w", err)
+		}
+		jobConfig.Locker = lock
+		jobConfig.Standby = true
+		jobConfig.LockRetryInterval = cfg.LeaderLockInterval
+		jobConfig.Params["leader-lock"] = cfg.LeaderLock
+	}
+
 	res := m.Scheduler.Submit(jobConfig)
+	m.trackSubmission(res, "LilyWatch", cfg)
 	return res, nil
 }
 
@@ -264,11 +318,11 @@ func (m *LilyNodeAPI) LilyWatchNotify(_ context.Context, cfg *LilyWatchNotifyCon
 		ChainModuleAPI: m.ChainModuleAPI,
 	}
 
-	notifier, err := m.QueueCatalog.Notifier(cfg.Queue)
+	q, err := m.tipSetQueue(context.Background(), cfg.Queue, storage.Metadata{JobName: cfg.JobConfig.Name})
 	if err != nil {
 		return nil, err
 	}
-	idx := distributed.NewTipSetIndexer(queue.NewAsynq(notifier))
+	idx := distributed.NewTipSetIndexer(q)
 	reporter := &schedule.Reporter{}
 	watchJob := watch.NewWatcher(wapi, idx, cfg.JobConfig.Name,
 		reporter,
@@ -292,7 +346,8 @@ func (m *LilyNodeAPI) LilyWatchNotify(_ context.Context, cfg *LilyWatchNotifyCon
 		Reporter:            reporter,
 	}
 	res := m.Scheduler.Submit(jobConfig)
-	return res, err
+	m.trackSubmission(res, "LilyWatchNotify", cfg)
+	return res, nil
 }
 
 func (m *LilyNodeAPI) LilyWalk(_ context.Context, cfg *LilyWalkConfig) (*schedule.JobSubmitResult, error) {
@@ -315,11 +370,18 @@ func (m *LilyNodeAPI) LilyWalk(_ context.Context, cfg *LilyWalkConfig) (*schedul
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name, tipset.WithRetryPolicies(cfg.JobConfig.Retry), tipset.WithTaskConfig(m.taskConfig())), integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
 
+	walkOpts := []walk.WalkerOpt{walk.WithResume(cfg.Resume), walk.WithShards(cfg.Shards)}
+	if cp, ok := storage.CheckpointerOf(strg); ok {
+		walkOpts = append(walkOpts, walk.WithCheckpointer(cp))
+	} else if cfg.Resume {
+		return nil, fmt.Errorf("storage %q does not support checkpoints, walk cannot be resumed", cfg.JobConfig.Storage)
+	}
+
 	reporter := &schedule.Reporter{}
 	jobConfig := &schedule.JobConfig{
 		Name: cfg.JobConfig.Name,
@@ -329,25 +391,28 @@ func (m *LilyNodeAPI) LilyWalk(_ context.Context, cfg *LilyWalkConfig) (*schedul
 			"minHeight": fmt.Sprintf("%d", cfg.From),
 			"maxHeight": fmt.Sprintf("%d", cfg.To),
 			"storage":   cfg.JobConfig.Storage,
+			"resume":    strconv.FormatBool(cfg.Resume),
+			"shards":    strconv.Itoa(cfg.Shards),
 		},
 		Tasks:               cfg.JobConfig.Tasks,
 		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
-		Job:                 walk.NewWalker(idx, m, cfg.JobConfig.Name, cfg.JobConfig.Tasks, cfg.From, cfg.To, reporter, cfg.JobConfig.StopOnError, cfg.Interval),
+		Job:                 walk.NewWalker(idx, m, cfg.JobConfig.Name, cfg.JobConfig.Tasks, cfg.From, cfg.To, reporter, cfg.JobConfig.StopOnError, cfg.Interval, walkOpts...),
 		Reporter:            reporter,
 	}
 
 	res := m.Scheduler.Submit(jobConfig)
+	m.trackSubmission(res, "LilyWalk", cfg)
 	return res, nil
 }
 
 func (m *LilyNodeAPI) LilyWalkNotify(_ context.Context, cfg *LilyWalkNotifyConfig) (*schedule.JobSubmitResult, error) {
-	notifier, err := m.QueueCatalog.Notifier(cfg.Queue)
+	q, err := m.tipSetQueue(context.Background(), cfg.Queue, storage.Metadata{JobName: cfg.WalkConfig.JobConfig.Name})
 	if err != nil {
 		return nil, err
 	}
-	idx := distributed.NewTipSetIndexer(queue.NewAsynq(notifier))
+	idx := distributed.NewTipSetIndexer(q)
 
 	reporter := &schedule.Reporter{}
 	jobConfig := &schedule.JobConfig{
@@ -366,6 +431,7 @@ func (m *LilyNodeAPI) LilyWalkNotify(_ context.Context, cfg *LilyWalkNotifyConfi
 		Reporter:            reporter,
 	}
 	res := m.Scheduler.Submit(jobConfig)
+	m.trackSubmission(res, "LilyWalkNotify", cfg)
 	return res, nil
 }
 
@@ -398,6 +464,47 @@ func (m *LilyNodeAPI) LilyGapFind(_ context.Context, cfg *LilyGapFindConfig) (*s
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 	})
 
+	m.trackSubmission(res, "LilyGapFind", cfg)
+	return res, nil
+}
+
+func (m *LilyNodeAPI) LilyValidate(_ context.Context, cfg *LilyValidateConfig) (*schedule.JobSubmitResult, error) {
+	// the context's passed to these methods live for the duration of the clients request, so make a new one.
+	ctx := context.Background()
+
+	md := storage.Metadata{
+		JobName: cfg.JobConfig.Name,
+	}
+
+	// create a database connection for this validation, ensure its pingable, and run migrations if needed/configured to.
+	db, err := m.StorageCatalog.ConnectAsDatabase(ctx, cfg.JobConfig.Storage, md)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter := &schedule.Reporter{}
+	validator, err := validate.NewValidator(m, db, cfg.JobConfig.Name, cfg.From, cfg.To, cfg.Checks, reporter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := m.Scheduler.Submit(&schedule.JobConfig{
+		Name:  cfg.JobConfig.Name,
+		Type:  "validate",
+		Tasks: cfg.Checks,
+		Params: map[string]string{
+			"minHeight": fmt.Sprintf("%d", cfg.From),
+			"maxHeight": fmt.Sprintf("%d", cfg.To),
+			"storage":   cfg.JobConfig.Storage,
+		},
+		Job:                 validator,
+		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
+		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
+		RestartDelay:        cfg.JobConfig.RestartDelay,
+		Reporter:            reporter,
+	})
+
+	m.trackSubmission(res, "LilyValidate", cfg)
 	return res, nil
 }
 
@@ -422,15 +529,17 @@ func (m *LilyNodeAPI) LilyGapFill(_ context.Context, cfg *LilyGapFillConfig) (*s
 			"minHeight": fmt.Sprintf("%d", cfg.From),
 			"maxHeight": fmt.Sprintf("%d", cfg.To),
 			"storage":   cfg.JobConfig.Storage,
+			"resume":    strconv.FormatBool(cfg.Resume),
 		},
 		Tasks:               cfg.JobConfig.Tasks,
 		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 		Reporter:            reporter,
-		Job:                 gap.NewFiller(m, db, cfg.JobConfig.Name, cfg.From, cfg.To, cfg.JobConfig.Tasks, reporter),
+		Job:                 gap.NewFiller(m, db, cfg.JobConfig.Name, cfg.From, cfg.To, cfg.JobConfig.Tasks, reporter, cfg.Resume, gap.WithRetryPolicies(cfg.JobConfig.Retry), gap.WithTaskConfig(m.taskConfig())),
 	}
 	res := m.Scheduler.Submit(jobConfig)
+	m.trackSubmission(res, "LilyGapFill", cfg)
 	return res, nil
 }
 
@@ -442,7 +551,7 @@ func (m *LilyNodeAPI) LilyGapFillNotify(_ context.Context, cfg *LilyGapFillNotif
 		JobName: cfg.GapFillConfig.JobConfig.Name,
 	}
 
-	notifier, err := m.QueueCatalog.Notifier(cfg.Queue)
+	q, err := m.tipSetQueue(ctx, cfg.Queue, md)
 	if err != nil {
 		return nil, err
 	}
@@ -462,12 +571,13 @@ func (m *LilyNodeAPI) LilyGapFillNotify(_ context.Context, cfg *LilyGapFillNotif
 			"queue":     cfg.Queue,
 		},
 		Tasks:               cfg.GapFillConfig.JobConfig.Tasks,
-		Job:                 gap.NewNotifier(m, db, queue.NewAsynq(notifier), cfg.GapFillConfig.JobConfig.Name, cfg.GapFillConfig.From, cfg.GapFillConfig.To, cfg.GapFillConfig.JobConfig.Tasks),
+		Job:                 gap.NewNotifier(m, db, q, cfg.GapFillConfig.JobConfig.Name, cfg.GapFillConfig.From, cfg.GapFillConfig.To, cfg.GapFillConfig.JobConfig.Tasks),
 		RestartOnFailure:    cfg.GapFillConfig.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.GapFillConfig.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.GapFillConfig.JobConfig.RestartDelay,
 	})
 
+	m.trackSubmission(res, "LilyGapFillNotify", cfg)
 	return res, nil
 }
 
@@ -863,6 +973,7 @@ func (m *LilyNodeAPI) LilySurvey(_ context.Context, cfg *LilySurveyConfig) (*sch
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 	})
 
+	m.trackSubmission(res, "LilySurvey", cfg)
 	return res, nil
 }
 
@@ -1,17 +1,32 @@
 package modules
 
 import (
+	"path/filepath"
+
 	"go.uber.org/fx"
 
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/lily/tasks/fevm/contractevent"
+	"github.com/filecoin-project/lily/tasks/messages/builtinactorevent"
 
 	"github.com/filecoin-project/lotus/node/modules/helpers"
+	"github.com/filecoin-project/lotus/node/repo"
 )
 
-func NewStorageCatalog(_ helpers.MetricsCtx, _ fx.Lifecycle, cfg *config.Conf) (*storage.Catalog, error) {
-	return storage.NewCatalog(cfg.Storage)
+func NewStorageCatalog(_ helpers.MetricsCtx, lc fx.Lifecycle, cfg *config.Conf) (*storage.Catalog, error) {
+	sc, err := storage.NewCatalog(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+	// finalize any partially written storage output when the daemon stops
+	lc.Append(fx.Hook{
+		OnStop: sc.Close,
+	})
+	return sc, nil
 }
 
 func LoadConf(path string) func(mctx helpers.MetricsCtx, lc fx.Lifecycle) (*config.Conf, error) {
@@ -20,6 +35,28 @@ func LoadConf(path string) func(mctx helpers.MetricsCtx, lc fx.Lifecycle) (*conf
 	}
 }
 
+// NewTaskConfig returns the configuration of the tasks run by the jobs of the daemon.
+func NewTaskConfig(cfg *config.Conf) (*processor.TaskConfig, error) {
+	if _, err := builtinactorevent.TargetEvents(cfg.BuiltinActorEvents.Events); err != nil {
+		return nil, err
+	}
+	filters, err := contractevent.NewFilters(cfg.ContractEvents)
+	if err != nil {
+		return nil, err
+	}
+	return &processor.TaskConfig{
+		BuiltinActorEvents:   cfg.BuiltinActorEvents.Events,
+		ContractEventFilters: filters,
+	}, nil
+}
+
 func NewQueueCatalog(_ helpers.MetricsCtx, _ fx.Lifecycle, cfg *config.Conf) (*distributed.Catalog, error) {
 	return distributed.NewCatalog(cfg.Queue)
 }
+
+// JobRegistryFilename is the name of the file in the lily repo that holds persisted job submissions.
+const JobRegistryFilename = "jobs.json"
+
+func NewJobRegistry(lr repo.LockedRepo) *schedule.JobRegistry {
+	return schedule.NewJobRegistry(filepath.Join(lr.Path(), JobRegistryFilename))
+}
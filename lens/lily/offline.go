@@ -0,0 +1,79 @@
+package lily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/lily/lens/lily/modules"
+	"github.com/filecoin-project/lily/lens/util"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/node/impl/full"
+)
+
+// ErrOffline is returned by methods that depend on services of a running lily daemon, such as the eth transaction
+// index or the actor event index, when called on an API returned by NewOfflineAPI.
+var ErrOffline = errors.New("not available when indexing offline")
+
+// NewOfflineAPI returns a LilyNodeAPI that serves the lens.API from the chain store cs and the state manager sm without
+// starting the network stack, syncing the chain or running the job scheduler. The state manager must have been
+// created with em as its execution monitor so that message executions of tipsets it computes can be retrieved.
+// Only the methods of lens.API are supported.
+func NewOfflineAPI(cs *store.ChainStore, sm *stmgr.StateManager, em *modules.BufferedExecMonitor) *LilyNodeAPI {
+	bs := cs.StateBlockstore()
+	return &LilyNodeAPI{
+		ChainAPI: full.ChainAPI{
+			ChainModuleAPI: &full.ChainModule{
+				Chain:             cs,
+				ExposedBlockstore: bs,
+			},
+			Chain:             cs,
+			ExposedBlockstore: bs,
+			BaseBlockstore:    bs,
+		},
+		StateAPI: full.StateAPI{
+			StateModuleAPI: &full.StateModule{
+				StateManager: sm,
+				Chain:        cs,
+			},
+			StateManager: sm,
+			Chain:        cs,
+		},
+		EthTransactionAPIV1: offlineEthTransactionAPI{},
+		ActorEventAPI:       offlineActorEventAPI{},
+		ExecMonitor:         em,
+		CacheConfig:         &util.CacheConfig{},
+	}
+}
+
+// offlineEthTransactionAPI fails the eth methods used by lens.API, they require the transaction index maintained by
+// the daemon.
+type offlineEthTransactionAPI struct {
+	full.EthTransactionAPIV1
+}
+
+func (offlineEthTransactionAPI) EthGetBlockByHash(_ context.Context, _ ethtypes.EthHash, _ bool) (ethtypes.EthBlock, error) {
+	return ethtypes.EthBlock{}, fmt.Errorf("EthGetBlockByHash: %w", ErrOffline)
+}
+
+func (offlineEthTransactionAPI) EthGetTransactionReceipt(_ context.Context, _ ethtypes.EthHash) (*api.EthTxReceipt, error) {
+	return nil, fmt.Errorf("EthGetTransactionReceipt: %w", ErrOffline)
+}
+
+func (offlineEthTransactionAPI) EthGetTransactionByHash(_ context.Context, _ *ethtypes.EthHash) (*ethtypes.EthTx, error) {
+	return nil, fmt.Errorf("EthGetTransactionByHash: %w", ErrOffline)
+}
+
+// offlineActorEventAPI fails the actor event methods, they require the event index maintained by the daemon.
+type offlineActorEventAPI struct {
+	full.ActorEventAPI
+}
+
+func (offlineActorEventAPI) GetActorEventsRaw(_ context.Context, _ *types.ActorEventFilter) ([]*types.ActorEvent, error) {
+	return nil, fmt.Errorf("GetActorEventsRaw: %w", ErrOffline)
+}
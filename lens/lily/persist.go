@@ -0,0 +1,161 @@
+package lily
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/lily/schedule"
+)
+
+// trackSubmission remembers the configuration a job was submitted with so that it can later be persisted.
+func (m *LilyNodeAPI) trackSubmission(res *schedule.JobSubmitResult, method string, cfg interface{}) {
+	if res == nil {
+		return
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		log.Errorw("failed to encode job config", "error", err, "job", res.Name, "method", method)
+		return
+	}
+
+	m.submissionsMu.Lock()
+	defer m.submissionsMu.Unlock()
+	if m.submissions == nil {
+		m.submissions = make(map[schedule.JobID]schedule.PersistedJob)
+	}
+	m.submissions[res.ID] = schedule.PersistedJob{
+		Name:   res.Name,
+		Type:   res.Type,
+		Method: method,
+		Config: data,
+	}
+}
+
+func (m *LilyNodeAPI) LilyJobPersist(_ context.Context, ID schedule.JobID) error {
+	if m.JobRegistry == nil {
+		return fmt.Errorf("job registry is not available")
+	}
+
+	m.submissionsMu.Lock()
+	job, ok := m.submissions[ID]
+	m.submissionsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("job %d not found", ID)
+	}
+
+	job.PersistedAt = time.Now()
+	if err := m.JobRegistry.Put(job); err != nil {
+		return err
+	}
+	log.Infow("persisted job", "id", ID, "job", job.Name, "type", job.Type)
+	return nil
+}
+
+func (m *LilyNodeAPI) LilyJobForget(_ context.Context, name string) error {
+	if m.JobRegistry == nil {
+		return fmt.Errorf("job registry is not available")
+	}
+
+	found, err := m.JobRegistry.Remove(name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("job %q is not persisted", name)
+	}
+	log.Infow("forgot persisted job", "job", name)
+	return nil
+}
+
+// RestorePersistedJobs submits every job held in the job registry. Jobs that cannot be submitted are logged and
+// skipped so that one bad entry does not prevent the others from running.
+func (m *LilyNodeAPI) RestorePersistedJobs(ctx context.Context) error {
+	if m.JobRegistry == nil {
+		return nil
+	}
+
+	jobs, err := m.JobRegistry.List()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		res, err := m.submitPersistedJob(ctx, job)
+		if err != nil {
+			log.Errorw("failed to restore persisted job", "error", err, "job", job.Name, "method", job.Method)
+			continue
+		}
+		log.Infow("restored persisted job", "id", res.ID, "job", job.Name, "type", job.Type)
+	}
+	return nil
+}
+
+func (m *LilyNodeAPI) submitPersistedJob(ctx context.Context, job schedule.PersistedJob) (*schedule.JobSubmitResult, error) {
+	switch job.Method {
+	case "LilyWatch":
+		cfg := &LilyWatchConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyWatch(ctx, cfg)
+	case "LilyWatchNotify":
+		cfg := &LilyWatchNotifyConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyWatchNotify(ctx, cfg)
+	case "LilyWalk":
+		cfg := &LilyWalkConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyWalk(ctx, cfg)
+	case "LilyWalkNotify":
+		cfg := &LilyWalkNotifyConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyWalkNotify(ctx, cfg)
+	case "LilyGapFind":
+		cfg := &LilyGapFindConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyGapFind(ctx, cfg)
+	case "LilyValidate":
+		cfg := &LilyValidateConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyValidate(ctx, cfg)
+	case "LilyGapFill":
+		cfg := &LilyGapFillConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyGapFill(ctx, cfg)
+	case "LilyGapFillNotify":
+		cfg := &LilyGapFillNotifyConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilyGapFillNotify(ctx, cfg)
+	case "LilySurvey":
+		cfg := &LilySurveyConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.LilySurvey(ctx, cfg)
+	case "StartTipSetWorker":
+		cfg := &LilyTipSetWorkerConfig{}
+		if err := json.Unmarshal(job.Config, cfg); err != nil {
+			return nil, err
+		}
+		return m.StartTipSetWorker(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported job method %q", job.Method)
+	}
+}
@@ -0,0 +1,68 @@
+package lily
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
+	"github.com/filecoin-project/lily/storage"
+)
+
+// tipSetQueue returns the queue tipsets are enqueued on for the queue config `name`, which is either a postgres queue
+// or an asynq notifier.
+func (m *LilyNodeAPI) tipSetQueue(ctx context.Context, name string, md storage.Metadata) (distributed.Queue, error) {
+	if pc, ok := m.QueueCatalog.Postgres(name); ok {
+		db, err := m.StorageCatalog.ConnectAsDatabase(ctx, pc.Storage, md)
+		if err != nil {
+			return nil, fmt.Errorf("queue %q: %w", name, err)
+		}
+		return queue.NewPgQueue(db, m.QueueCatalog.MaxRetry), nil
+	}
+
+	notifier, err := m.QueueCatalog.Notifier(name)
+	if err != nil {
+		return nil, err
+	}
+	return queue.NewAsynq(notifier, m.QueueCatalog.MaxRetry), nil
+}
+
+// tipSetWorker returns a worker consuming tasks from the queue config `name`, which is either a postgres queue or an
+// asynq worker.
+func (m *LilyNodeAPI) tipSetWorker(ctx context.Context, name string, jobName string, md storage.Metadata, handlers ...queue.TaskHandler) (queue.Worker, error) {
+	if pc, ok := m.QueueCatalog.Postgres(name); ok {
+		db, err := m.StorageCatalog.ConnectAsDatabase(ctx, pc.Storage, md)
+		if err != nil {
+			return nil, fmt.Errorf("queue %q: %w", name, err)
+		}
+		return queue.NewPgWorker(jobName, db, pc, handlers...), nil
+	}
+
+	worker, err := m.QueueCatalog.Worker(name)
+	if err != nil {
+		return nil, err
+	}
+	return queue.NewAsynqWorker(jobName, worker, handlers...), nil
+}
+
+// failedTasks calls fn with the archived tasks of the queue config `name`.
+func (m *LilyNodeAPI) failedTasks(ctx context.Context, name string, fn func(ft queue.FailedTasks) error) error {
+	if pc, ok := m.QueueCatalog.Postgres(name); ok {
+		db, err := m.StorageCatalog.ConnectAsDatabase(ctx, pc.Storage, storage.Metadata{})
+		if err != nil {
+			return fmt.Errorf("queue %q: %w", name, err)
+		}
+		return fn(queue.NewPgFailedTasks(db))
+	}
+
+	inspector, err := m.QueueCatalog.Inspector(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := inspector.Close(); err != nil {
+			log.Warnw("failed to close queue inspector", "queue", name, "error", err)
+		}
+	}()
+	return fn(queue.NewFailedTaskInspector(inspector))
+}
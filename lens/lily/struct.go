@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/specs-actors/actors/util/adt"
 
@@ -45,9 +46,18 @@ type LilyAPIStruct struct {
 		LilyJobWait  func(ctx context.Context, ID schedule.JobID) (*schedule.JobListResult, error) `perm:"read"`
 		LilyJobList  func(ctx context.Context) ([]schedule.JobListResult, error)                   `perm:"read"`
 
+		LilyJobPersist func(ctx context.Context, ID schedule.JobID) error `perm:"read"`
+		LilyJobForget  func(ctx context.Context, name string) error       `perm:"read"`
+
 		LilyGapFind func(ctx context.Context, cfg *LilyGapFindConfig) (*schedule.JobSubmitResult, error) `perm:"read"`
 		LilyGapFill func(ctx context.Context, cfg *LilyGapFillConfig) (*schedule.JobSubmitResult, error) `perm:"read"`
 
+		LilyValidate func(ctx context.Context, cfg *LilyValidateConfig) (*schedule.JobSubmitResult, error) `perm:"read"`
+
+		LilyWorkerFailedList  func(ctx context.Context, cfg *LilyWorkerFailedConfig) ([]*queue.FailedTask, error) `perm:"read"`
+		LilyWorkerFailedRetry func(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error)                 `perm:"read"`
+		LilyWorkerFailedPurge func(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error)                 `perm:"read"`
+
 		Shutdown func(context.Context) error `perm:"read"`
 
 		SyncState func(ctx context.Context) (*api.SyncState, error) `perm:"read"`
@@ -204,6 +214,26 @@ func (s *LilyAPIStruct) LilyJobList(ctx context.Context) ([]schedule.JobListResu
 	return s.Internal.LilyJobList(ctx)
 }
 
+func (s *LilyAPIStruct) LilyJobPersist(ctx context.Context, ID schedule.JobID) error {
+	return s.Internal.LilyJobPersist(ctx, ID)
+}
+
+func (s *LilyAPIStruct) LilyJobForget(ctx context.Context, name string) error {
+	return s.Internal.LilyJobForget(ctx, name)
+}
+
+func (s *LilyAPIStruct) LilyWorkerFailedList(ctx context.Context, cfg *LilyWorkerFailedConfig) ([]*queue.FailedTask, error) {
+	return s.Internal.LilyWorkerFailedList(ctx, cfg)
+}
+
+func (s *LilyAPIStruct) LilyWorkerFailedRetry(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error) {
+	return s.Internal.LilyWorkerFailedRetry(ctx, cfg)
+}
+
+func (s *LilyAPIStruct) LilyWorkerFailedPurge(ctx context.Context, cfg *LilyWorkerFailedConfig) (int, error) {
+	return s.Internal.LilyWorkerFailedPurge(ctx, cfg)
+}
+
 func (s *LilyAPIStruct) LilyGapFind(ctx context.Context, cfg *LilyGapFindConfig) (*schedule.JobSubmitResult, error) {
 	return s.Internal.LilyGapFind(ctx, cfg)
 }
@@ -212,6 +242,10 @@ func (s *LilyAPIStruct) LilyGapFill(ctx context.Context, cfg *LilyGapFillConfig)
 	return s.Internal.LilyGapFill(ctx, cfg)
 }
 
+func (s *LilyAPIStruct) LilyValidate(ctx context.Context, cfg *LilyValidateConfig) (*schedule.JobSubmitResult, error) {
+	return s.Internal.LilyValidate(ctx, cfg)
+}
+
 func (s *LilyAPIStruct) Shutdown(ctx context.Context) error {
 	return s.Internal.Shutdown(ctx)
 }
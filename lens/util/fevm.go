@@ -3,11 +3,14 @@ package util
 import (
 	"context"
 
+	"github.com/ipfs/go-cid"
+
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lily/tasks"
 
 	builtin "github.com/filecoin-project/lotus/chain/actors/builtin"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 )
 
 func IsEVMAddress(ctx context.Context, ds tasks.DataSource, addr address.Address, tsk types.TipSetKey) bool {
@@ -39,3 +42,42 @@ func IsEVMMessage(ctx context.Context, ds tasks.DataSource, message *types.Messa
 
 	return false
 }
+
+// EthLogFromEntries returns the topics and data of the EVM log held in the entries of an event emitted by an EVM
+// actor. ok is false if the entries are not a valid EVM log, for example if they are those of a built-in actor event.
+func EthLogFromEntries(entries []types.EventEntry) (topics []ethtypes.EthHash, data []byte, ok bool) {
+	var found [4]bool
+	for _, entry := range entries {
+		// EVM logs are encoded as raw bytes, built-in actor events as CBOR.
+		if entry.Codec != cid.Raw {
+			return nil, nil, false
+		}
+		switch {
+		case len(entry.Key) == 2 && entry.Key >= "t1" && entry.Key <= "t4":
+			idx := int(entry.Key[1] - '1')
+			if len(entry.Value) != 32 || found[idx] {
+				return nil, nil, false
+			}
+			found[idx] = true
+			for len(topics) <= idx {
+				topics = append(topics, ethtypes.EthHash{})
+			}
+			copy(topics[idx][:], entry.Value)
+		case entry.Key == "d":
+			if data != nil {
+				return nil, nil, false
+			}
+			data = entry.Value
+			if data == nil {
+				data = []byte{}
+			}
+		}
+	}
+	// topics must be contiguous, a log with t2 but without t1 is invalid.
+	for idx := range topics {
+		if !found[idx] {
+			return nil, nil, false
+		}
+	}
+	return topics, data, true
+}
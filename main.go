@@ -159,7 +159,9 @@ func main() {
 			commands.ChainCmd,
 			commands.DaemonCmd,
 			commands.ExportChainCmd,
+			commands.IndexCarCmd,
 			commands.InitCmd,
+			commands.LoadCmd,
 			commands.LogCmd,
 			commands.MigrateCmd,
 			commands.NetCmd,
@@ -18,6 +18,7 @@ var (
 	JobType, _   = tag.NewKey("job_type") // type of job (walk, watch, fill, find, watch-notify, walk-notify, etc.)
 	Name, _      = tag.NewKey("name")     // name of running instance of visor
 	Table, _     = tag.NewKey("table")    // name of table data is persisted for
+	Storage, _   = tag.NewKey("storage")  // name of storage data is persisted to
 	ConnState, _ = tag.NewKey("conn_state")
 	API, _       = tag.NewKey("api")        // name of method on lotus api
 	ActorCode, _ = tag.NewKey("actor_code") // human readable code of actor being processed
@@ -45,6 +46,7 @@ var (
 	TipSetSkip              = stats.Int64("tipset_skip", "Number of tipsets that were not processed. This is is an indication that lily cannot keep up with chain.", stats.UnitDimensionless)
 	JobStart                = stats.Int64("job_start", "Number of jobs started", stats.UnitDimensionless)
 	JobRunning              = stats.Int64("job_running", "Numer of jobs currently running", stats.UnitDimensionless)
+	JobLockHeld             = stats.Int64("job_lock_held", "Number of jobs currently holding their lock", stats.UnitDimensionless)
 	JobComplete             = stats.Int64("job_complete", "Number of jobs completed without error", stats.UnitDimensionless)
 	JobError                = stats.Int64("job_error", "Number of jobs stopped due to a fatal error", stats.UnitDimensionless)
 	JobTimeout              = stats.Int64("job_timeout", "Number of jobs stopped due to taking longer than expected", stats.UnitDimensionless)
@@ -209,7 +211,7 @@ var DefaultViews = []*view.View{
 		Name:        PersistFailure.Name() + "_total",
 		Measure:     PersistFailure,
 		Aggregation: view.Count(),
-		TagKeys:     []tag.Key{TaskType, Table, ActorCode},
+		TagKeys:     []tag.Key{TaskType, Table, ActorCode, Storage},
 	},
 	{
 		Measure:     WatchHeight,
@@ -227,6 +229,11 @@ var DefaultViews = []*view.View{
 		Aggregation: view.Sum(),
 		TagKeys:     []tag.Key{Job, JobType},
 	},
+	{
+		Measure:     JobLockHeld,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{Job, JobType},
+	},
 	{
 		Name:        JobStart.Name() + "_total",
 		Measure:     JobStart,
@@ -256,7 +263,7 @@ var DefaultViews = []*view.View{
 		Name:        PersistModel.Name() + "_total",
 		Measure:     PersistModel,
 		Aggregation: view.Count(),
-		TagKeys:     []tag.Key{TaskType, Table},
+		TagKeys:     []tag.Key{TaskType, Table, Storage},
 	},
 
 	{
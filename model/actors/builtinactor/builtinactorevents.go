@@ -23,6 +23,9 @@ type BuiltInActorEvent struct {
 	EventIdx     int64  `pg:",pk,notnull"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*BuiltInActorEvent) RevertedByHeight() {}
+
 func (ds *BuiltInActorEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_actor_events"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
@@ -0,0 +1,281 @@
+package builtinactor
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// EventDealLifecycle is a deal-published, deal-activated, deal-terminated or deal-completed event emitted by the
+// market actor.
+type EventDealLifecycle struct {
+	tableName struct{} `pg:"event_deal_lifecycle"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// Type of the event, for example deal-activated.
+	EventType string `pg:",notnull"`
+	// Identifier of the deal.
+	DealID uint64 `pg:",notnull,use_zero"`
+	// Address of the client of the deal.
+	Client string `pg:",notnull"`
+	// Address of the provider of the deal.
+	Provider string `pg:",notnull"`
+}
+
+func (e *EventDealLifecycle) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_deal_lifecycle"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*EventDealLifecycle) RevertedByHeight() {}
+
+type EventDealLifecycleList []*EventDealLifecycle
+
+func (l EventDealLifecycleList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "EventDealLifecycleList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_deal_lifecycle"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
+
+// EventVerifierBalance is a verifier-balance event emitted by the verified registry actor when the datacap a
+// verifier may allocate changes.
+type EventVerifierBalance struct {
+	tableName struct{} `pg:"event_verifier_balance"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// Address of the verifier.
+	Verifier string `pg:",notnull"`
+	// Datacap the verifier may allocate after the change, in bytes.
+	Balance string `pg:"type:numeric,notnull"`
+}
+
+func (e *EventVerifierBalance) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifier_balance"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*EventVerifierBalance) RevertedByHeight() {}
+
+type EventVerifierBalanceList []*EventVerifierBalance
+
+func (l EventVerifierBalanceList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "EventVerifierBalanceList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifier_balance"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
+
+// EventVerifregAllocation is an allocation or allocation-removed event emitted by the verified registry actor.
+type EventVerifregAllocation struct {
+	tableName struct{} `pg:"event_verifreg_allocation"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// Type of the event, allocation or allocation-removed.
+	EventType string `pg:",notnull"`
+	// Identifier of the allocation.
+	AllocationID uint64 `pg:",notnull,use_zero"`
+	// Address of the client of the allocation.
+	Client string `pg:",notnull"`
+	// Address of the provider the allocation is made to.
+	Provider string `pg:",notnull"`
+	// CID of the piece.
+	PieceCid string
+	// Size of the piece in bytes.
+	PieceSize uint64 `pg:",use_zero"`
+	// Minimum number of epochs the piece must be stored for.
+	TermMin int64 `pg:",use_zero"`
+	// Maximum number of epochs the piece may be stored for.
+	TermMax int64 `pg:",use_zero"`
+	// Epoch by which the piece must be committed to a sector.
+	Expiration int64 `pg:",use_zero"`
+}
+
+func (e *EventVerifregAllocation) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifreg_allocation"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*EventVerifregAllocation) RevertedByHeight() {}
+
+type EventVerifregAllocationList []*EventVerifregAllocation
+
+func (l EventVerifregAllocationList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "EventVerifregAllocationList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifreg_allocation"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
+
+// EventVerifregClaim is a claim, claim-updated or claim-removed event emitted by the verified registry actor.
+type EventVerifregClaim struct {
+	tableName struct{} `pg:"event_verifreg_claim"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// Type of the event, claim, claim-updated or claim-removed.
+	EventType string `pg:",notnull"`
+	// Identifier of the claim.
+	ClaimID uint64 `pg:",notnull,use_zero"`
+	// Address of the client of the claim.
+	Client string `pg:",notnull"`
+	// Address of the provider storing the piece.
+	Provider string `pg:",notnull"`
+	// CID of the piece.
+	PieceCid string
+	// Size of the piece in bytes.
+	PieceSize uint64 `pg:",use_zero"`
+	// Minimum number of epochs the piece must be stored for.
+	TermMin int64 `pg:",use_zero"`
+	// Maximum number of epochs the piece may be stored for.
+	TermMax int64 `pg:",use_zero"`
+	// Epoch at which the piece was committed.
+	TermStart int64 `pg:",use_zero"`
+	// Number of the sector the piece is stored in.
+	Sector uint64 `pg:",use_zero"`
+}
+
+func (e *EventVerifregClaim) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifreg_claim"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*EventVerifregClaim) RevertedByHeight() {}
+
+type EventVerifregClaimList []*EventVerifregClaim
+
+func (l EventVerifregClaimList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "EventVerifregClaimList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_verifreg_claim"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
+
+// EventSectorLifecycle is a sector-precommitted, sector-activated, sector-updated or sector-terminated event emitted
+// by a miner actor.
+type EventSectorLifecycle struct {
+	tableName struct{} `pg:"event_sector_lifecycle"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Address of the miner that emitted the event.
+	MinerID string `pg:",notnull"`
+	// Type of the event, for example sector-activated.
+	EventType string `pg:",notnull"`
+	// Number of the sector.
+	SectorNumber uint64 `pg:",notnull,use_zero"`
+	// CID of the unsealed data of the sector, empty for sectors without data and for events other than
+	// sector-activated and sector-updated.
+	UnsealedCid string
+	// CIDs of the pieces in the sector, in the order they were emitted.
+	PieceCids []string `pg:",array"`
+	// Sizes of the pieces in the sector in bytes, in the same order as PieceCids.
+	PieceSizes []uint64 `pg:",array"`
+}
+
+func (e *EventSectorLifecycle) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_sector_lifecycle"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*EventSectorLifecycle) RevertedByHeight() {}
+
+type EventSectorLifecycleList []*EventSectorLifecycle
+
+func (l EventSectorLifecycleList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "EventSectorLifecycleList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "event_sector_lifecycle"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
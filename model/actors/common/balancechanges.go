@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// ActorBalanceChange records the balance of an actor before and after an epoch in which its balance changed, along
+// with the messages that moved funds to or from the actor and the flows they moved them through.
+type ActorBalanceChange struct {
+	tableName struct{} `pg:"actor_balance_changes"` // nolint: structcheck
+
+	// Epoch at which the balance changed.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// ID address of the actor.
+	Address string `pg:",pk,notnull"`
+	// CID of the parent state root containing the new balance.
+	StateRoot string `pg:",pk,notnull"`
+	// Balance of the actor in attoFIL before the change.
+	OldBalance string `pg:"type:numeric,notnull"`
+	// Balance of the actor in attoFIL after the change.
+	NewBalance string `pg:"type:numeric,notnull"`
+	// Difference between NewBalance and OldBalance in attoFIL.
+	BalanceDelta string `pg:"type:numeric,notnull"`
+	// CIDs of the messages, including implicit messages, that transferred value to or from the actor, or whose gas fees
+	// it paid or received.
+	MessageCids []string `pg:",array"`
+	// Flows the balance changed through: message, gas, reward, burn or vesting.
+	Causes []string `pg:",array"`
+}
+
+func (a *ActorBalanceChange) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "actor_balance_changes"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, a)
+}
+
+// ActorBalanceChangeList is a slice of ActorBalanceChanges persistable in a single batch.
+type ActorBalanceChangeList []*ActorBalanceChange
+
+func (l ActorBalanceChangeList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "ActorBalanceChangeList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "actor_balance_changes"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
@@ -21,6 +21,9 @@ type MinerCronFee struct {
 	Penalty string `pg:"type:numeric,notnull"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*MinerCronFee) RevertedByHeight() {}
+
 func (m *MinerCronFee) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, span := otel.Tracer("").Start(ctx, "MinerCronFee.Persist")
 	defer span.End()
@@ -0,0 +1,80 @@
+package miner
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Types of penalty paid by a miner.
+const (
+	// PenaltyFaultFee is the fee paid at the end of a proving deadline for sectors that remain faulty.
+	PenaltyFaultFee = "FAULT_FEE"
+	// PenaltyPreCommitExpiry is the deposit burnt for precommitted sectors that were not proven in time.
+	PenaltyPreCommitExpiry = "PRECOMMIT_EXPIRY"
+	// PenaltyTermination is the fee paid for sectors terminated before their expiration.
+	PenaltyTermination = "TERMINATION_FEE"
+	// PenaltyPoStDispute is the penalty paid for a window PoSt that was successfully disputed.
+	PenaltyPoStDispute = "POST_DISPUTE"
+	// PenaltyConsensusFault is the penalty paid for a consensus fault.
+	PenaltyConsensusFault = "CONSENSUS_FAULT"
+	// PenaltyFeeDebtRepayment is fee debt from earlier penalties burnt when the miner received funds.
+	PenaltyFeeDebtRepayment = "FEE_DEBT_REPAYMENT"
+	// PenaltyFeeDebtIncurred is the increase in the fee debt of a miner, for penalties it could not pay when they
+	// were applied.
+	PenaltyFeeDebtIncurred = "FEE_DEBT_INCURRED"
+	// PenaltyDailyFee is the daily fee charged for the power of a proving deadline from actors v16. It is not a
+	// penalty but is burnt together with the fees for faults.
+	PenaltyDailyFee = "DAILY_FEE"
+	// PenaltyNetworkFee is the network fee burnt for batched precommits and aggregated proofs.
+	PenaltyNetworkFee = "NETWORK_FEE"
+	// PenaltyOther is any other amount burnt by a miner.
+	PenaltyOther = "OTHER"
+)
+
+// MinerPenalty records the total amount of a type of penalty paid by a miner at an epoch.
+type MinerPenalty struct {
+	tableName struct{} `pg:"miner_penalties"` // nolint: structcheck
+
+	// Epoch at which the messages in whose execution the penalty was paid were executed.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Address of the miner who paid the penalty.
+	MinerID string `pg:",pk,notnull"`
+	// CID of the parent state root at which the messages were executed.
+	StateRoot string `pg:",pk,notnull"`
+	// Type of the penalty.
+	PenaltyType string `pg:",pk,notnull"`
+	// Amount of the penalty in attoFIL.
+	Amount string `pg:"type:numeric,notnull"`
+	// CIDs of the messages, including implicit messages, in whose execution the penalty was paid.
+	MessageCids []string `pg:",array"`
+}
+
+func (m *MinerPenalty) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_penalties"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, m)
+}
+
+type MinerPenaltyList []*MinerPenalty
+
+func (l MinerPenaltyList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MinerPenaltyList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_penalties"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
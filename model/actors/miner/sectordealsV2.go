@@ -20,6 +20,9 @@ type MinerSectorDealV2 struct {
 	DealID   uint64 `pg:",pk,use_zero"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*MinerSectorDealV2) RevertedByHeight() {}
+
 func (ds *MinerSectorDealV2) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_sector_deals_v2"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
@@ -0,0 +1,71 @@
+package miner
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Lifecycle states of a sector.
+const (
+	SectorStatePreCommitted     = "PRECOMMITTED"
+	SectorStatePreCommitExpired = "PRECOMMIT_EXPIRED"
+	SectorStateActive           = "ACTIVE"
+	SectorStateFaulty           = "FAULTY"
+	SectorStateRecovering       = "RECOVERING"
+	SectorStateTerminated       = "TERMINATED"
+)
+
+// MinerSectorLifecycle records a sector entering a lifecycle state. The sector exits the state at the EnteredEpoch of
+// its next transition, which the miner_sector_lifecycle_view derives in the database.
+type MinerSectorLifecycle struct {
+	tableName struct{} `pg:"miner_sector_lifecycle"` // nolint: structcheck
+
+	// Epoch at which the transition was observed.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Address of the miner who owns the sector.
+	MinerID string `pg:",pk,notnull"`
+	// Numeric identifier of the sector.
+	SectorID uint64 `pg:",pk,use_zero"`
+	// CID of the parent state root at this epoch.
+	StateRoot string `pg:",pk,notnull"`
+	// Lifecycle state the sector entered.
+	State string `pg:",pk,notnull"`
+	// Lifecycle state the sector exited, empty if the sector was not known before it entered State.
+	PreviousState string
+	// Event that caused the transition, as recorded in miner_sector_events.
+	Cause string `pg:",notnull"`
+	// Epoch at which the sector entered State. This may be earlier than Height for sectors whose miner is first
+	// observed with the sector already in State.
+	EnteredEpoch int64 `pg:",notnull,use_zero"`
+}
+
+func (m *MinerSectorLifecycle) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_sector_lifecycle"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, m)
+}
+
+type MinerSectorLifecycleList []*MinerSectorLifecycle
+
+func (l MinerSectorLifecycleList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MinerSectorLifecycleList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_sector_lifecycle"))
+
+	if len(l) == 0 {
+		return nil
+	}
+
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+	return s.PersistModel(ctx, l)
+}
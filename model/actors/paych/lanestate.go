@@ -0,0 +1,38 @@
+package paych
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+type PaychLaneState struct {
+	tableName struct{} `pg:"paych_lane_states"` // nolint: structcheck
+	Height    int64    `pg:",pk,notnull,use_zero"`
+	StateRoot string   `pg:",pk,notnull"`
+	Address   string   `pg:",pk,notnull"`
+	Lane      uint64   `pg:",pk,notnull,use_zero"`
+
+	Redeemed string `pg:"type:numeric,notnull"`
+	Nonce    uint64 `pg:",notnull,use_zero"`
+}
+
+func (pls *PaychLaneState) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "paych_lane_states"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, pls)
+}
+
+type PaychLaneStateList []*PaychLaneState
+
+func (pl PaychLaneStateList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(pl) == 0 {
+		return nil
+	}
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "paych_lane_states"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(pl))
+	return s.PersistModel(ctx, pl)
+}
@@ -0,0 +1,40 @@
+package paych
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+type PaychState struct {
+	tableName struct{} `pg:"paych_states"` // nolint: structcheck
+	Height    int64    `pg:",pk,notnull,use_zero"`
+	StateRoot string   `pg:",pk,notnull"`
+	Address   string   `pg:",pk,notnull"`
+
+	From       string `pg:",notnull"`
+	To         string `pg:",notnull"`
+	ToSend     string `pg:"type:numeric,notnull"`
+	SettlingAt int64  `pg:",notnull,use_zero"`
+	LaneCount  uint64 `pg:",notnull,use_zero"`
+}
+
+func (ps *PaychState) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "paych_states"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, ps)
+}
+
+type PaychStateList []*PaychState
+
+func (pl PaychStateList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(pl) == 0 {
+		return nil
+	}
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "paych_states"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(pl))
+	return s.PersistModel(ctx, pl)
+}
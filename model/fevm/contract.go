@@ -28,6 +28,9 @@ type FEVMContract struct {
 	Nonce uint64 `pg:",pk,use_zero"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMContract) RevertedByHeight() {}
+
 func (f *FEVMContract) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_contracts"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
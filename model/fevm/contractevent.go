@@ -0,0 +1,71 @@
+package fevm
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// ContractEvent is an event emitted by an FEVM contract that matched a contract event filter of the lily config.
+type ContractEvent struct {
+	tableName struct{} `pg:"contract_events"` // nolint: structcheck
+
+	// Epoch at which the event was emitted.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the message that emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// Index of the event among the events emitted in the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Name of the filter the event matched.
+	Filter string `pg:",pk,notnull"`
+	// Address of the contract that emitted the event.
+	Emitter string `pg:",notnull"`
+	// Address of the contract in ETH.
+	EmitterEthAddress string
+	// First topic of the event, the keccak256 hash of the event signature for non-anonymous events.
+	Topic0 string
+	// Second topic of the event.
+	Topic1 string
+	// Third topic of the event.
+	Topic2 string
+	// Fourth topic of the event.
+	Topic3 string
+	// Data of the event in hex.
+	Data string
+	// Name of the event, empty if it could not be decoded with the ABI of the filter.
+	EventName string
+	// Arguments of the event by name, empty if it could not be decoded with the ABI of the filter.
+	DecodedArgs string `pg:",type:jsonb"`
+}
+
+func (c *ContractEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "contract_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, c)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*ContractEvent) RevertedByHeight() {}
+
+type ContractEventList []*ContractEvent
+
+func (l ContractEventList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "ContractEventList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "contract_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
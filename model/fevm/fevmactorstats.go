@@ -32,6 +32,9 @@ type FEVMActorStats struct {
 	PlaceholderCount uint64 `pg:",use_zero"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMActorStats) RevertedByHeight() {}
+
 func (f *FEVMActorStats) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_actor_stats"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
@@ -44,6 +44,9 @@ type FEVMReceipt struct {
 	Logs string `pg:",type:jsonb"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMReceipt) RevertedByHeight() {}
+
 func (f *FEVMReceipt) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_receipts"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
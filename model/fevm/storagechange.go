@@ -0,0 +1,57 @@
+package fevm
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// FEVMStorageChange is a change to a storage slot of an EVM actor.
+type FEVMStorageChange struct {
+	tableName struct{} `pg:"fevm_storage_changes"` // nolint: structcheck
+
+	// Height of the state the change is found in.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Actor address.
+	ActorID string `pg:",pk,notnull"`
+	// Actor Address in ETH.
+	EthAddress string `pg:",notnull"`
+	// Storage slot, as a 32 byte hex string.
+	Slot string `pg:",pk,notnull"`
+	// Value of the slot before the change, as a 32 byte hex string. Slots that are not set hold zero.
+	OldValue string `pg:",notnull"`
+	// Value of the slot after the change, as a 32 byte hex string. Slots that are not set hold zero.
+	NewValue string `pg:",notnull"`
+}
+
+func (f *FEVMStorageChange) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_storage_changes"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, f)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMStorageChange) RevertedByHeight() {}
+
+type FEVMStorageChangeList []*FEVMStorageChange
+
+func (l FEVMStorageChangeList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "FEVMStorageChangeList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_storage_changes"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
@@ -0,0 +1,123 @@
+package fevm
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Token standards.
+const (
+	TokenStandardERC20  = "ERC20"
+	TokenStandardERC721 = "ERC721"
+)
+
+// Token event types.
+const (
+	TokenEventTransfer = "TRANSFER"
+	TokenEventApproval = "APPROVAL"
+)
+
+// FEVMTokenTransfer is a Transfer or Approval log of an ERC-20 or ERC-721 token contract.
+type FEVMTokenTransfer struct {
+	tableName struct{} `pg:"fevm_token_transfers"` // nolint: structcheck
+
+	// Height message was executed at.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Hash of the transaction that emitted the log.
+	TransactionHash string `pg:",pk,notnull"`
+	// Index of the log among the logs emitted by the transaction.
+	LogIndex uint64 `pg:",pk,notnull,use_zero"`
+	// Message CID of the transaction.
+	Message string `pg:",notnull"`
+	// ETH address of the token contract.
+	TokenAddress string `pg:",notnull"`
+	// Standard of the token, ERC20 or ERC721, as determined by the number of topics of the log.
+	TokenStandard string `pg:",notnull"`
+	// Type of the log, TRANSFER or APPROVAL.
+	EventType string `pg:",notnull"`
+	// ETH address tokens are transferred from, or of the owner for approvals.
+	From string `pg:",notnull"`
+	// ETH address tokens are transferred to, or of the approved spender for approvals.
+	To string `pg:",notnull"`
+	// Amount of tokens transferred or approved, ERC-20 only.
+	Amount string `pg:"type:numeric"`
+	// Identifier of the token transferred or approved, ERC-721 only.
+	TokenID string `pg:"type:numeric"`
+}
+
+func (f *FEVMTokenTransfer) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_token_transfers"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, f)
+}
+
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMTokenTransfer) RevertedByHeight() {}
+
+type FEVMTokenTransferList []*FEVMTokenTransfer
+
+func (l FEVMTokenTransferList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "FEVMTokenTransferList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_token_transfers"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
+
+// FEVMToken is a contract identified as a token when it was first seen emitting a Transfer or Approval log.
+type FEVMToken struct {
+	tableName struct{} `pg:"fevm_tokens"` // nolint: structcheck
+
+	// Height at which the token was identified.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// ETH address of the token contract.
+	TokenAddress string `pg:",pk,notnull"`
+	// Filecoin address of the token contract.
+	ActorID string `pg:",notnull"`
+	// Standard of the token, ERC20 or ERC721.
+	TokenStandard string `pg:",notnull"`
+	// Name returned by the name() method of the contract, empty if it has none.
+	Name string
+	// Symbol returned by the symbol() method of the contract, empty if it has none.
+	Symbol string
+	// Decimals returned by the decimals() method of the contract, nil if it has none.
+	Decimals *uint8
+}
+
+func (f *FEVMToken) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_tokens"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, f)
+}
+
+type FEVMTokenList []*FEVMToken
+
+func (l FEVMTokenList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "FEVMTokenList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_tokens"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
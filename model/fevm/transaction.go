@@ -61,6 +61,9 @@ type FEVMTransaction struct {
 	MessageCid string `pg:",notnull"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*FEVMTransaction) RevertedByHeight() {}
+
 func (f *FEVMTransaction) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
 	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fevm_transactions"))
 	metrics.RecordCount(ctx, metrics.PersistModel, 1)
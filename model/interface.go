@@ -53,3 +53,10 @@ func (pl PersistableList) Persist(ctx context.Context, s StorageBatch, version V
 	}
 	return nil
 }
+
+// A HeightReverted model has no state root column but the rows it persists at a height are derived only from the
+// tipset at that height, such as from the messages it includes or their execution. Its rows are removed by height when
+// that tipset is reverted.
+type HeightReverted interface {
+	RevertedByHeight()
+}
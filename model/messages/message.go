@@ -28,6 +28,9 @@ type Message struct {
 	Method    uint64 `pg:",use_zero"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*Message) RevertedByHeight() {}
+
 type MessageV0 struct {
 	tableName struct{} `pg:"messages"` // nolint: structcheck
 	Height    int64    `pg:",pk,notnull,use_zero"`
@@ -45,6 +48,9 @@ type MessageV0 struct {
 	Method    uint64 `pg:",use_zero"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*MessageV0) RevertedByHeight() {}
+
 func (m *Message) AsVersion(version model.Version) (interface{}, bool) {
 	switch version.Major {
 	case 0:
@@ -0,0 +1,64 @@
+package messages
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// MethodGasStats summarizes the gas used by the messages executed at an epoch that called a method of an actor family.
+type MethodGasStats struct {
+	tableName struct{} `pg:"method_gas_stats"` // nolint: structcheck
+
+	// Epoch at which the messages were executed.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// CID of the parent state root at which the messages were executed.
+	StateRoot string `pg:",pk,notnull"`
+	// Family of the actor the messages were sent to, for example storageminer.
+	ActorFamily string `pg:",pk,notnull"`
+	// Method number called by the messages.
+	Method uint64 `pg:",pk,use_zero"`
+	// Name of the method, empty if it could not be determined.
+	MethodName string
+	// Number of messages.
+	MessageCount int64 `pg:",use_zero"`
+	// Median of the gas used by the messages.
+	GasUsedP50 int64 `pg:",use_zero"`
+	// 90th percentile of the gas used by the messages.
+	GasUsedP90 int64 `pg:",use_zero"`
+	// 99th percentile of the gas used by the messages.
+	GasUsedP99 int64 `pg:",use_zero"`
+	// Total base fee burnt by the messages in attoFIL.
+	BaseFeeBurn string `pg:"type:numeric,notnull"`
+	// Total overestimation burn of the messages in attoFIL.
+	OverEstimationBurn string `pg:"type:numeric,notnull"`
+}
+
+func (m *MethodGasStats) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "method_gas_stats"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, m)
+}
+
+type MethodGasStatsList []*MethodGasStats
+
+func (l MethodGasStatsList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MethodGasStatsList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "method_gas_stats"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+
+	if len(l) == 0 {
+		return nil
+	}
+	return s.PersistModel(ctx, l)
+}
@@ -22,6 +22,9 @@ type ParsedMessage struct {
 	Params string `pg:",type:jsonb"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*ParsedMessage) RevertedByHeight() {}
+
 type ParsedMessageV0 struct {
 	tableName struct{} `pg:"parsed_messages"` // nolint: structcheck
 	Height    int64    `pg:",pk,notnull,use_zero"`
@@ -33,6 +36,9 @@ type ParsedMessageV0 struct {
 	Params    string   `pg:",type:jsonb,notnull"`
 }
 
+// RevertedByHeight implements model.HeightReverted.
+func (*ParsedMessageV0) RevertedByHeight() {}
+
 func (pm *ParsedMessage) AsVersion(version model.Version) (interface{}, bool) {
 	switch version.Major {
 	case 0:
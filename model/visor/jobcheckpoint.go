@@ -0,0 +1,28 @@
+package visor
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// JobCheckpoint records the progress of a job so that it may be resumed after a restart.
+type JobCheckpoint struct {
+	tableName struct{} `pg:"visor_job_checkpoints"` // nolint: structcheck
+
+	JobName string `pg:",pk,notnull"`
+	JobType string `pg:",notnull"`
+	// Height is the last height the job completed
+	Height    int64     `pg:",use_zero"`
+	UpdatedAt time.Time `pg:",use_zero"`
+}
+
+func (c *JobCheckpoint) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "visor_job_checkpoints"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, c)
+}
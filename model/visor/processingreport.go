@@ -43,6 +43,10 @@ type ProcessingReport struct {
 	Status            string `pg:",notnull"`
 	StatusInformation string
 	ErrorsDetected    interface{} `pg:",type:jsonb"`
+
+	// Attempts is the number of times the task was run before producing the report, it is greater than one when the
+	// task was retried after failing.
+	Attempts int `pg:",notnull"`
 }
 
 func (p *ProcessingReport) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
@@ -0,0 +1,32 @@
+package visor
+
+import (
+	"time"
+)
+
+// States of a QueueTask.
+const (
+	QueueTaskPending  = "pending"
+	QueueTaskActive   = "active"
+	QueueTaskArchived = "archived"
+)
+
+// QueueTask is a tipset task enqueued on a postgres backed distributed indexer queue. A task is deleted once it has
+// been processed successfully and archived once it has exhausted its retries.
+type QueueTask struct {
+	tableName struct{} `pg:"visor_queue_tasks"` // nolint: structcheck
+
+	ID       int64  `pg:",pk"`
+	Queue    string `pg:",notnull"`
+	Type     string `pg:",notnull"`
+	Payload  []byte `pg:",notnull"`
+	State    string `pg:",notnull"`
+	Retried  int    `pg:",use_zero,notnull"`
+	MaxRetry int    `pg:",use_zero,notnull"`
+	// LastError is the error returned by the last failed attempt to process the task.
+	LastError    string
+	CreatedAt    time.Time `pg:",notnull"`
+	RunAt        time.Time `pg:",notnull"`
+	LockedAt     time.Time
+	LastFailedAt time.Time
+}
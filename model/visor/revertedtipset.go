@@ -0,0 +1,30 @@
+package visor
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// RevertedTipSet records a tipset that was reverted from the chain after it had been submitted for indexing.
+type RevertedTipSet struct {
+	tableName struct{} `pg:"visor_reverted_tipsets"` // nolint: structcheck
+
+	Height    int64  `pg:",pk,use_zero"`
+	TipSet    string `pg:",pk,notnull"`
+	StateRoot string `pg:",notnull"`
+
+	// Reporter is the name of the instance that is reporting the revert
+	Reporter   string    `pg:",pk,notnull"`
+	RevertedAt time.Time `pg:",pk,use_zero"`
+}
+
+func (r *RevertedTipSet) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "visor_reverted_tipsets"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, r)
+}
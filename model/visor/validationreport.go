@@ -0,0 +1,66 @@
+package visor
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+const (
+	// ValidationStatusDiscrepancy is the status of a report of persisted data that does not match chain state or
+	// another table.
+	ValidationStatusDiscrepancy = "DISCREPANCY"
+	// ValidationStatusError is the status of a report of a check that could not be completed.
+	ValidationStatusError = "ERROR"
+)
+
+// ValidationReport records a discrepancy found by a validate job between persisted data and chain state or the data
+// of another table.
+type ValidationReport struct {
+	tableName struct{} `pg:"visor_validation_reports"` // nolint: structcheck
+
+	Height    int64  `pg:",pk,use_zero"`
+	CheckName string `pg:",pk"`
+	// Subject identifies the value that was checked within the height, such as a miner address or column name.
+	Subject   string `pg:",pk,use_zero"`
+	StateRoot string `pg:",notnull"`
+	Status    string `pg:",notnull"`
+
+	// Expected is the value derived from chain state or another table, Actual is the persisted value.
+	Expected string
+	Actual   string
+	Message  string
+
+	// Reporter is the name of the instance that is reporting the result
+	Reporter    string    `pg:",pk,notnull"`
+	ValidatedAt time.Time `pg:",use_zero"`
+}
+
+func (r *ValidationReport) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "visor_validation_reports"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, r)
+}
+
+type ValidationReportList []*ValidationReport
+
+func (rl ValidationReportList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(rl) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "ValidationReportList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(rl)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "visor_validation_reports"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(rl))
+	return s.PersistModel(ctx, rl)
+}
@@ -0,0 +1,298 @@
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("lily/query")
+
+const (
+	// DefaultLimit is the number of rows returned in a page when the request does not specify a limit.
+	DefaultLimit = 100
+	// DefaultMaxLimit is the largest page size a request may ask for.
+	DefaultMaxLimit = 1000
+
+	limitParam  = "limit"
+	cursorParam = "cursor"
+)
+
+// DB is the subset of the go-pg database used to run queries.
+type DB interface {
+	QueryContext(ctx context.Context, model, query interface{}, params ...interface{}) (pg.Result, error)
+}
+
+var _ DB = (*pg.DB)(nil)
+
+type Options struct {
+	DefaultLimit int
+	MaxLimit     int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		DefaultLimit: DefaultLimit,
+		MaxLimit:     DefaultMaxLimit,
+	}
+}
+
+// Server is a read-only http interface to the tables lily writes to a database. It serves the following endpoints:
+//
+//	GET /v1/tables          lists the tables that may be queried along with their primary key columns
+//	GET /v1/tables/{table}  returns rows from a table, ordered by primary key
+//
+// Rows may be filtered by passing primary key columns as query parameters, for example
+// /v1/tables/miner_infos?height=1000&miner_id=f01000. Results are paginated, the number of rows in a page is set by the
+// limit parameter and the next page is requested by passing the next_cursor value from a response as the cursor
+// parameter.
+type Server struct {
+	db     DB
+	tables Tables
+	opts   Options
+	mux    *http.ServeMux
+}
+
+func NewServer(db DB, tables Tables, opts Options) *Server {
+	s := &Server{
+		db:     db,
+		tables: tables,
+		opts:   opts,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /v1/tables", s.handleTables)
+	s.mux.HandleFunc("GET /v1/tables/{table}", s.handleRows)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type TablesResponse struct {
+	Tables []*Table `json:"tables"`
+}
+
+type RowsResponse struct {
+	Table      string                   `json:"table"`
+	Rows       []map[string]interface{} `json:"rows"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleTables(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, &TablesResponse{Tables: s.tables.List()})
+}
+
+func (s *Server) handleRows(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("table")
+	t, ok := s.tables[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown table %q", name))
+		return
+	}
+
+	q, err := s.parseRowsQuery(t, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sql, params := q.sql()
+
+	// fetch one more row than the limit so we can tell if there is another page
+	var rows []map[string]interface{}
+	if _, err := s.db.QueryContext(r.Context(), &rows, sql, params...); err != nil {
+		log.Errorw("query failed", "table", t.Name, "error", err)
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("query failed"))
+		return
+	}
+
+	res := &RowsResponse{
+		Table: t.Name,
+		Rows:  rows,
+	}
+	if len(rows) > q.limit {
+		res.Rows = rows[:q.limit]
+		next, err := encodeCursor(t, res.Rows[q.limit-1])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		res.NextCursor = next
+	}
+	if res.Rows == nil {
+		res.Rows = []map[string]interface{}{}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+type filter struct {
+	column string
+	value  string
+}
+
+type rowsQuery struct {
+	table   *Table
+	filters []filter
+	after   []string // primary key values of the last row of the previous page
+	limit   int
+}
+
+func (s *Server) parseRowsQuery(t *Table, r *http.Request) (*rowsQuery, error) {
+	q := &rowsQuery{
+		table: t,
+		limit: s.opts.DefaultLimit,
+	}
+
+	values := r.URL.Query()
+	for key, vals := range values {
+		switch key {
+		case limitParam:
+			limit, err := strconv.Atoi(values.Get(key))
+			if err != nil || limit <= 0 {
+				return nil, fmt.Errorf("invalid limit %q", values.Get(key))
+			}
+			if limit > s.opts.MaxLimit {
+				limit = s.opts.MaxLimit
+			}
+			q.limit = limit
+		case cursorParam:
+			after, err := decodeCursor(t, values.Get(key))
+			if err != nil {
+				return nil, err
+			}
+			q.after = after
+		default:
+			if !t.IsPrimaryKey(key) {
+				return nil, fmt.Errorf("cannot filter on %q, only primary key columns may be used: %s", key, strings.Join(t.PrimaryKey, ", "))
+			}
+			if len(vals) != 1 {
+				return nil, fmt.Errorf("filter %q must be given exactly once", key)
+			}
+		}
+	}
+
+	// filters are applied in primary key order so the same request always produces the same query
+	for _, pk := range t.PrimaryKey {
+		if values.Has(pk) {
+			q.filters = append(q.filters, filter{column: pk, value: values.Get(pk)})
+		}
+	}
+
+	return q, nil
+}
+
+// sql returns the query and its parameters. Pages are selected by comparing the primary key of each row against the
+// primary key of the last row of the previous page, so pages remain stable while new rows are being written.
+func (q *rowsQuery) sql() (string, []interface{}) {
+	var (
+		where  []string
+		params []interface{}
+	)
+
+	params = append(params, pg.Ident(q.table.Name))
+	for _, f := range q.filters {
+		where = append(where, "? = ?")
+		params = append(params, pg.Ident(f.column), f.value)
+	}
+
+	if len(q.after) > 0 {
+		cols := make([]string, len(q.table.PrimaryKey))
+		vals := make([]string, len(q.table.PrimaryKey))
+		for i, pk := range q.table.PrimaryKey {
+			cols[i] = "?"
+			params = append(params, pg.Ident(pk))
+		}
+		for i, v := range q.after {
+			vals[i] = "?"
+			params = append(params, v)
+		}
+		where = append(where, fmt.Sprintf("(%s) > (%s)", strings.Join(cols, ", "), strings.Join(vals, ", ")))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM ?")
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
+
+	order := make([]string, len(q.table.PrimaryKey))
+	for i, pk := range q.table.PrimaryKey {
+		order[i] = "?"
+		params = append(params, pg.Ident(pk))
+	}
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(strings.Join(order, ", "))
+
+	sb.WriteString(" LIMIT ?")
+	params = append(params, q.limit+1)
+
+	return sb.String(), params
+}
+
+// encodeCursor returns an opaque cursor holding the primary key values of row.
+func encodeCursor(t *Table, row map[string]interface{}) (string, error) {
+	vals := make([]string, len(t.PrimaryKey))
+	for i, pk := range t.PrimaryKey {
+		vals[i] = cursorValue(row[pk])
+	}
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(t *Table, cursor string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var vals []string
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if len(vals) != len(t.PrimaryKey) {
+		return nil, fmt.Errorf("invalid cursor for table %q", t.Name)
+	}
+	return vals, nil
+}
+
+// cursorValue formats a column value so that postgres can parse it back into the column type.
+func cursorValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorw("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestModel struct {
+	tableName struct{} `pg:"test_models"` // nolint: structcheck
+	Height    int64    `pg:",pk,notnull,use_zero"`
+	MinerID   string   `pg:",pk,notnull"`
+	Value     string   `pg:",notnull"`
+}
+
+type fakeDB struct {
+	rows    []map[string]interface{}
+	queries []string
+}
+
+func (f *fakeDB) QueryContext(_ context.Context, model, query interface{}, params ...interface{}) (pg.Result, error) {
+	f.queries = append(f.queries, string(orm.NewFormatter().FormatQuery(nil, query.(string), params...)))
+	*model.(*[]map[string]interface{}) = f.rows
+	return nil, nil
+}
+
+func newTestServer(db DB) *Server {
+	return NewServer(db, NewTables([]interface{}{(*TestModel)(nil)}), Options{DefaultLimit: 2, MaxLimit: 10})
+}
+
+func get(t *testing.T, s *Server, url string, v interface{}) int {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if v != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+	return rec.Code
+}
+
+func TestNewTables(t *testing.T) {
+	tables := NewTables([]interface{}{(*TestModel)(nil)})
+	require.Contains(t, tables, "test_models")
+	assert.Equal(t, []string{"height", "miner_id"}, tables["test_models"].PrimaryKey)
+	assert.Equal(t, []string{"height", "miner_id", "value"}, tables["test_models"].Columns)
+}
+
+func TestListTables(t *testing.T) {
+	s := newTestServer(&fakeDB{})
+
+	var res TablesResponse
+	require.Equal(t, http.StatusOK, get(t, s, "/v1/tables", &res))
+	require.Len(t, res.Tables, 1)
+	assert.Equal(t, "test_models", res.Tables[0].Name)
+}
+
+func TestQueryRows(t *testing.T) {
+	db := &fakeDB{
+		rows: []map[string]interface{}{
+			{"height": int64(10), "miner_id": "f01000", "value": "a"},
+			{"height": int64(10), "miner_id": "f01001", "value": "b"},
+			{"height": int64(10), "miner_id": "f01002", "value": "c"},
+		},
+	}
+	s := newTestServer(db)
+
+	var res RowsResponse
+	require.Equal(t, http.StatusOK, get(t, s, "/v1/tables/test_models?height=10", &res))
+	assert.Equal(t, "test_models", res.Table)
+	require.Len(t, res.Rows, 2)
+	require.NotEmpty(t, res.NextCursor)
+	assert.Equal(t, `SELECT * FROM "test_models" WHERE "height" = '10' ORDER BY "height", "miner_id" LIMIT 3`, db.queries[0])
+
+	// the cursor selects rows after the last row of the previous page
+	cursor := res.NextCursor
+	db.rows = db.rows[2:]
+	res = RowsResponse{}
+	require.Equal(t, http.StatusOK, get(t, s, "/v1/tables/test_models?height=10&cursor="+cursor, &res))
+	assert.Equal(t, `SELECT * FROM "test_models" WHERE "height" = '10' AND ("height", "miner_id") > ('10', 'f01001') ORDER BY "height", "miner_id" LIMIT 3`, db.queries[1])
+	require.Len(t, res.Rows, 1)
+	assert.Empty(t, res.NextCursor)
+}
+
+func TestQueryRowsErrors(t *testing.T) {
+	s := newTestServer(&fakeDB{})
+
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/v1/tables/unknown", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/v1/tables/test_models?value=a", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/v1/tables/test_models?limit=0", nil))
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/v1/tables/test_models?cursor=notacursor", nil))
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"sort"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/filecoin-project/lily/storage"
+)
+
+// Table describes a table that may be queried.
+type Table struct {
+	Name       string   `json:"name"`
+	PrimaryKey []string `json:"primary_key"`
+	Columns    []string `json:"columns"`
+}
+
+// IsPrimaryKey reports whether col is one of the primary key columns of the table.
+func (t *Table) IsPrimaryKey(col string) bool {
+	for _, pk := range t.PrimaryKey {
+		if pk == col {
+			return true
+		}
+	}
+	return false
+}
+
+// Tables is a set of queryable tables keyed by table name.
+type Tables map[string]*Table
+
+// NewTables returns the tables described by a list of go-pg models, such as storage.Models. Models without a
+// primary key cannot be paginated and are skipped.
+func NewTables(models []interface{}) Tables {
+	out := Tables{}
+	for _, m := range models {
+		tm := pg.Model(m).TableModel().Table()
+		if len(tm.PKs) == 0 {
+			continue
+		}
+
+		t := &Table{Name: storage.StripQuotes(tm.SQLName)}
+		for _, pk := range tm.PKs {
+			t.PrimaryKey = append(t.PrimaryKey, pk.SQLName)
+		}
+		for _, f := range tm.Fields {
+			t.Columns = append(t.Columns, f.SQLName)
+		}
+		out[t.Name] = t
+	}
+	return out
+}
+
+// List returns the tables ordered by name.
+func (ts Tables) List() []*Table {
+	out := make([]*Table, 0, len(ts))
+	for _, t := range ts {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,132 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PersistedJob is a job submission that is recorded in a JobRegistry so that it can be submitted again when the
+// daemon restarts.
+type PersistedJob struct {
+	// Name is the name of the job, it uniquely identifies the job in the registry.
+	Name string
+	// Type is the type of the job, for example walk or watch.
+	Type string
+	// Method is the name of the api method used to submit the job.
+	Method string
+	// Config is the json encoded configuration passed to Method.
+	Config json.RawMessage
+	// PersistedAt is the time the job was added to the registry.
+	PersistedAt time.Time
+}
+
+// JobRegistry is a set of job submissions kept in a json file.
+type JobRegistry struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewJobRegistry(path string) *JobRegistry {
+	return &JobRegistry{path: path}
+}
+
+// List returns all jobs in the registry ordered by name.
+func (r *JobRegistry) List() ([]PersistedJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]PersistedJob, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, j)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// Put adds a job to the registry, replacing any job with the same name.
+func (r *JobRegistry) Put(job PersistedJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs, err := r.load()
+	if err != nil {
+		return err
+	}
+	jobs[job.Name] = job
+	return r.save(jobs)
+}
+
+// Remove removes the named job from the registry. It returns false if the job was not in the registry.
+func (r *JobRegistry) Remove(name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs, err := r.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := jobs[name]; !ok {
+		return false, nil
+	}
+	delete(jobs, name)
+	return true, r.save(jobs)
+}
+
+func (r *JobRegistry) load() (map[string]PersistedJob, error) {
+	jobs := map[string]PersistedJob{}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return jobs, nil
+		}
+		return nil, fmt.Errorf("read job registry: %w", err)
+	}
+
+	var list []PersistedJob
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode job registry: %w", err)
+	}
+	for _, j := range list {
+		jobs[j.Name] = j
+	}
+	return jobs, nil
+}
+
+// save writes the registry to a temporary file which is then renamed so that the registry is never left partially
+// written.
+func (r *JobRegistry) save(jobs map[string]PersistedJob) error {
+	list := make([]PersistedJob, 0, len(jobs))
+	for _, j := range jobs {
+		list = append(list, j)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	data, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		return fmt.Errorf("encode job registry: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write job registry: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("write job registry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package schedule_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/schedule"
+)
+
+func TestJobRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+
+	reg := schedule.NewJobRegistry(path)
+	jobs, err := reg.List()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	require.NoError(t, reg.Put(schedule.PersistedJob{Name: "watch", Type: "watch", Method: "LilyWatch", Config: json.RawMessage(`{"Confidence":10}`)}))
+	require.NoError(t, reg.Put(schedule.PersistedJob{Name: "walk", Type: "walk", Method: "LilyWalk", Config: json.RawMessage(`{"From":1}`)}))
+	// a job with the same name replaces the existing one
+	require.NoError(t, reg.Put(schedule.PersistedJob{Name: "walk", Type: "walk", Method: "LilyWalk", Config: json.RawMessage(`{"From":2}`)}))
+
+	// a new registry reading the same file sees the persisted jobs
+	jobs, err = schedule.NewJobRegistry(path).List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "walk", jobs[0].Name)
+	assert.JSONEq(t, `{"From":2}`, string(jobs[0].Config))
+	assert.Equal(t, "watch", jobs[1].Name)
+
+	found, err := reg.Remove("walk")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = reg.Remove("walk")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	jobs, err = reg.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "watch", jobs[0].Name)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	logging "github.com/ipfs/go-log/v2"
@@ -44,6 +45,9 @@ type JobConfig struct {
 	// errorMsg will contain a (helpful) string iff a jobs execution has halted due to an error.
 	errorMsg string
 
+	// lockState is the state of the job's lock, empty if the job has no lock or is not running.
+	lockState string
+
 	log *zap.SugaredLogger
 
 	// Reporter is a job report
@@ -61,6 +65,15 @@ type JobConfig struct {
 	// Locker is an optional lock that must be taken before the job can execute.
 	Locker Locker
 
+	// Standby controls whether a job whose lock is held by another process waits until it can take the lock, instead
+	// of not starting. A standby job that loses its lock while running is stopped and waits for the lock again. This
+	// allows several processes to run the same job with only one of them active at a time.
+	Standby bool
+
+	// LockRetryInterval is how often a standby job tries to take its lock, and how often a job checks it still holds
+	// a LeaseLocker. DefaultLockRetryInterval is used when it is zero.
+	LockRetryInterval time.Duration
+
 	// RestartOnFailure controls whether the job should be restarted if it stops with an error.
 	RestartOnFailure bool
 
@@ -84,20 +97,121 @@ type JobConfig struct {
 }
 
 type Reporter struct {
+	mu sync.Mutex
+
 	// Current Height is the current height of the job
 	CurrentHeight int64
+
+	// Shards reports the progress of each part of the range of a job that indexes its range in parallel.
+	Shards []*ShardReport `json:",omitempty"`
 }
 
 func (r *Reporter) UpdateCurrentHeight(height int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.CurrentHeight = height
 }
 
+// SetShards replaces the reports of the shards of the job.
+func (r *Reporter) SetShards(shards []*ShardReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Shards = shards
+}
+
+// Snapshot returns a copy of the report that is safe to read while the job continues to update it.
+func (r *Reporter) Snapshot() *Reporter {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := &Reporter{CurrentHeight: r.CurrentHeight}
+	for _, s := range r.Shards {
+		out.Shards = append(out.Shards, s.snapshot())
+	}
+	return out
+}
+
+// ShardReport is the report of one shard of a job's range.
+type ShardReport struct {
+	Reporter
+
+	// Shard is the index of the shard, starting from zero at the lowest range.
+	Shard int
+	// MinHeight and MaxHeight are the inclusive bounds of the shard.
+	MinHeight int64
+	MaxHeight int64
+	// Complete is true once the shard has processed all of its range.
+	Complete bool
+	// Error contains the error the shard stopped with, if any.
+	Error string `json:",omitempty"`
+}
+
+// SetComplete records that the shard has processed all of its range.
+func (s *ShardReport) SetComplete() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Complete = true
+}
+
+// SetError records the error the shard stopped with.
+func (s *ShardReport) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Error = err.Error()
+}
+
+func (s *ShardReport) snapshot() *ShardReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &ShardReport{
+		Reporter:  Reporter{CurrentHeight: s.CurrentHeight},
+		Shard:     s.Shard,
+		MinHeight: s.MinHeight,
+		MaxHeight: s.MaxHeight,
+		Complete:  s.Complete,
+		Error:     s.Error,
+	}
+}
+
 // Locker represents a general lock that a job may need to take before operating.
 type Locker interface {
 	Lock(context.Context) error
 	Unlock(context.Context) error
 }
 
+// A LeaseLocker is a Locker that may be lost while it is held, such as a lock held by a database session. Held reports
+// whether the lock is still held.
+type LeaseLocker interface {
+	Locker
+	Held(context.Context) (bool, error)
+}
+
+// DefaultLockRetryInterval is the LockRetryInterval of jobs that do not set one.
+const DefaultLockRetryInterval = 10 * time.Second
+
+// States of the lock of a running job.
+const (
+	// LockHeld is the state of a job that holds its lock.
+	LockHeld = "held"
+	// LockStandby is the state of a standby job waiting for its lock to be released by another process.
+	LockStandby = "standby"
+)
+
+func (jc *JobConfig) setLockState(state string) {
+	jc.lk.Lock()
+	jc.lockState = state
+	jc.lk.Unlock()
+}
+
+func (jc *JobConfig) lockRetryInterval() time.Duration {
+	if jc.LockRetryInterval > 0 {
+		return jc.LockRetryInterval
+	}
+	return DefaultLockRetryInterval
+}
+
 func NewScheduler(jobDelay time.Duration, scheduledJobs ...*JobConfig) *Scheduler {
 	// Enforce a minimum delay
 	if jobDelay == 0 {
@@ -325,6 +439,7 @@ func (s *Scheduler) WaitJob(ctx context.Context, id JobID) (*JobListResult, erro
 		Error:               job.errorMsg,
 		Tasks:               job.Tasks,
 		Running:             job.running,
+		Lock:                job.lockState,
 		RestartOnFailure:    job.RestartOnFailure,
 		RestartOnCompletion: job.RestartOnCompletion,
 		RestartDelay:        job.RestartDelay,
@@ -352,6 +467,8 @@ type JobListResult struct {
 	Tasks []string
 
 	Running bool
+	// Lock is the state of the job's lock, LockHeld or LockStandby, empty if the job has no lock or is not running.
+	Lock string `json:",omitempty"`
 
 	RestartOnFailure    bool
 	RestartOnCompletion bool
@@ -385,13 +502,14 @@ func (s *Scheduler) Jobs() []JobListResult {
 			Type:                j.Type,
 			Error:               j.errorMsg,
 			Running:             j.running,
+			Lock:                j.lockState,
 			RestartOnFailure:    j.RestartOnFailure,
 			RestartOnCompletion: j.RestartOnCompletion,
 			RestartDelay:        j.RestartDelay,
 			Params:              j.Params,
 			StartedAt:           j.StartedAt,
 			EndedAt:             j.EndedAt,
-			Report:              j.Reporter,
+			Report:              j.Reporter.Snapshot(),
 		}
 		out = append(out, result)
 		j.lk.Unlock()
@@ -427,27 +545,107 @@ func (s *Scheduler) execute(jc *JobConfig, complete chan struct{}) {
 		jc.log.Info("job execution ended")
 	}()
 
-	// Attempt to get the job lock if specified
-	if jc.Locker != nil {
-		if err := jc.Locker.Lock(ctx); err != nil {
-			jc.errorMsg = err.Error()
-			if errors.Is(err, storage.ErrLockNotAcquired) {
+	if jc.Locker == nil {
+		s.run(ctx, jc)
+		return
+	}
+
+	for {
+		if !s.lock(ctx, jc) {
+			return
+		}
+		if lost := s.runLocked(ctx, jc); !lost || !jc.Standby {
+			return
+		}
+		jc.log.Warnw("job lock lost, returning to standby")
+	}
+}
+
+// lock takes the lock of the job, reporting whether it was taken. A standby job retries every LockRetryInterval until
+// the lock is taken or the job is stopped.
+func (s *Scheduler) lock(ctx context.Context, jc *JobConfig) bool {
+	for {
+		err := jc.Locker.Lock(ctx)
+		if err == nil {
+			jc.setLockState(LockHeld)
+			metrics.RecordInc(ctx, metrics.JobLockHeld)
+			jc.log.Info("job lock acquired")
+			return true
+		}
+
+		if errors.Is(err, storage.ErrLockNotAcquired) {
+			if !jc.Standby {
+				jc.errorMsg = err.Error()
 				jc.log.Infow("job not started: lock not acquired")
-				return
+				return false
 			}
+		} else {
+			jc.errorMsg = err.Error()
 			jc.log.Errorw("job not started: lock not acquired", "error", err.Error())
-			return
+			if !jc.Standby {
+				return false
+			}
 		}
-		defer func() {
-			if err := jc.Locker.Unlock(ctx); err != nil {
-				if !errors.Is(err, context.Canceled) {
-					jc.errorMsg = err.Error()
-					jc.log.Errorw("failed to unlock job", "error", err.Error())
+
+		jc.setLockState(LockStandby)
+		select {
+		case <-ctx.Done():
+			jc.setLockState("")
+			return false
+		case <-time.After(jc.lockRetryInterval()):
+		}
+	}
+}
+
+// runLocked runs the job while it holds its lock and releases the lock when the job stops. When the Locker is a
+// LeaseLocker the lock is checked every LockRetryInterval and the job is stopped if the lock is lost. It returns true
+// if the job was stopped because its lock was lost.
+func (s *Scheduler) runLocked(ctx context.Context, jc *JobConfig) bool {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var lost atomic.Bool
+	if ll, ok := jc.Locker.(LeaseLocker); ok {
+		go func() {
+			ticker := time.NewTicker(jc.lockRetryInterval())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-runCtx.Done():
+					return
+				case <-ticker.C:
+				}
+				held, err := ll.Held(runCtx)
+				if runCtx.Err() != nil {
+					return
+				}
+				if err != nil || !held {
+					// a lock that cannot be checked may have been lost, stop before another process takes it.
+					jc.log.Errorw("job lock lost, stopping job", "error", err)
+					lost.Store(true)
+					cancel()
+					return
 				}
 			}
 		}()
 	}
 
+	s.run(runCtx, jc)
+	cancel()
+
+	metrics.RecordDec(ctx, metrics.JobLockHeld)
+	jc.setLockState("")
+	if err := jc.Locker.Unlock(ctx); err != nil && !lost.Load() {
+		if !errors.Is(err, context.Canceled) {
+			jc.errorMsg = err.Error()
+			jc.log.Errorw("failed to unlock job", "error", err.Error())
+		}
+	}
+	return lost.Load()
+}
+
+// run runs the job, restarting it according to its configuration, until it exits or the context is done.
+func (s *Scheduler) run(ctx context.Context, jc *JobConfig) {
 	// Keep this job running forever
 	delayNextRestart := false
 	for {
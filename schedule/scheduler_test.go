@@ -3,6 +3,7 @@ package schedule_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"go.uber.org/fx/fxtest"
 
 	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/storage"
 )
 
 func newTestJob(fn func(ctx context.Context) error) *testJob {
@@ -221,3 +223,90 @@ func TestScheduler(t *testing.T) {
 
 	})
 }
+
+// testLock is a lock shared by the jobs of a test, each taking it through its own testLease.
+type testLock struct {
+	mu    sync.Mutex
+	owner int
+}
+
+// take gives the lock to id without its owner unlocking it, as when the session holding a database lock ends and
+// another process takes the lock.
+func (l *testLock) take(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.owner = id
+}
+
+type testLease struct {
+	lock *testLock
+	id   int
+}
+
+func (l *testLease) Lock(_ context.Context) error {
+	l.lock.mu.Lock()
+	defer l.lock.mu.Unlock()
+	if l.lock.owner != 0 && l.lock.owner != l.id {
+		return storage.ErrLockNotAcquired
+	}
+	l.lock.owner = l.id
+	return nil
+}
+
+func (l *testLease) Unlock(_ context.Context) error {
+	l.lock.mu.Lock()
+	defer l.lock.mu.Unlock()
+	if l.lock.owner == l.id {
+		l.lock.owner = 0
+	}
+	return nil
+}
+
+func (l *testLease) Held(_ context.Context) (bool, error) {
+	l.lock.mu.Lock()
+	defer l.lock.mu.Unlock()
+	return l.lock.owner == l.id, nil
+}
+
+func TestSchedulerStandby(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := schedule.NewSchedulerDaemon(ctx, fxtest.NewLifecycle(t))
+
+	lock := &testLock{}
+	for id := 1; id <= 2; id++ {
+		s.Submit(&schedule.JobConfig{
+			Job: newTestJob(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			Name:              t.Name(),
+			Locker:            &testLease{lock: lock, id: id},
+			Standby:           true,
+			LockRetryInterval: 10 * time.Millisecond,
+		})
+		// ensure the first job takes the lock
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	jobs := s.Jobs()
+	assert.True(t, jobs[0].Running)
+	assert.Equal(t, schedule.LockHeld, jobs[0].Lock)
+	assert.True(t, jobs[1].Running)
+	assert.Equal(t, schedule.LockStandby, jobs[1].Lock)
+
+	// the first job is stopped and waits for the lock again when it loses the lock to the second job
+	lock.take(2)
+	time.Sleep(100 * time.Millisecond)
+	jobs = s.Jobs()
+	assert.Equal(t, schedule.LockStandby, jobs[0].Lock)
+	assert.Equal(t, schedule.LockHeld, jobs[1].Lock)
+
+	// the first job takes over when the second job is stopped and releases the lock
+	assert.NoError(t, s.StopJob(2))
+	time.Sleep(100 * time.Millisecond)
+	jobs = s.Jobs()
+	assert.Equal(t, schedule.LockHeld, jobs[0].Lock)
+	assert.False(t, jobs[1].Running)
+	assert.Empty(t, jobs[1].Lock)
+}
@@ -0,0 +1,26 @@
+package v1
+
+func init() {
+	patches.Register(
+		47,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.visor_reverted_tipsets (
+			height bigint NOT NULL,
+			tip_set text NOT NULL,
+			state_root text NOT NULL,
+			reporter text NOT NULL,
+			reverted_at timestamp with time zone NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.visor_reverted_tipsets ADD CONSTRAINT visor_reverted_tipsets_pk PRIMARY KEY (height, tip_set, reporter, reverted_at);
+
+		CREATE INDEX IF NOT EXISTS visor_reverted_tipsets_height_idx ON {{ .SchemaName | default "public"}}.visor_reverted_tipsets USING btree (height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.visor_reverted_tipsets IS 'Tipsets that were reverted from the chain by a reorg after they had been indexed. Data persisted for the tipset is removed from the database when it is reverted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_reverted_tipsets.height IS 'Epoch of the reverted tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_reverted_tipsets.tip_set IS 'Key of the reverted tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_reverted_tipsets.state_root IS 'CID of the parent state root of the reverted tipset, data persisted with this state root and height was removed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_reverted_tipsets.reporter IS 'Name of the job that observed the revert.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_reverted_tipsets.reverted_at IS 'Time the revert was observed.';
+		`,
+	)
+}
@@ -0,0 +1,22 @@
+package v1
+
+func init() {
+	patches.Register(
+		48,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.visor_job_checkpoints (
+			job_name text NOT NULL,
+			job_type text NOT NULL,
+			height bigint NOT NULL,
+			updated_at timestamp with time zone NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.visor_job_checkpoints ADD CONSTRAINT visor_job_checkpoints_pk PRIMARY KEY (job_name);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.visor_job_checkpoints IS 'Progress of walk, fill and index jobs, used to resume a job after a restart.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_job_checkpoints.job_name IS 'Name of the job.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_job_checkpoints.job_type IS 'Type of the job, for example walk or fill.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_job_checkpoints.height IS 'Last epoch completed by the job.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_job_checkpoints.updated_at IS 'Time the checkpoint was last updated.';
+		`,
+	)
+}
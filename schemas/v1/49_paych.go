@@ -0,0 +1,52 @@
+package v1
+
+func init() {
+	patches.Register(
+		49,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.paych_states (
+			height bigint NOT NULL,
+			state_root text NOT NULL,
+			address text NOT NULL,
+			"from" text NOT NULL,
+			"to" text NOT NULL,
+			to_send numeric NOT NULL,
+			settling_at bigint NOT NULL,
+			lane_count bigint NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.paych_states ADD CONSTRAINT paych_states_pk PRIMARY KEY (height, state_root, address);
+
+		CREATE INDEX IF NOT EXISTS paych_states_height_idx ON {{ .SchemaName | default "public"}}.paych_states USING btree (height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.paych_states IS 'Payment channel actor state, recorded each time the state of a payment channel changes.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.height IS 'Epoch at which the payment channel state was recorded.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.state_root IS 'CID of the parent state root at which the payment channel state was recorded.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.address IS 'Address of the payment channel actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states."from" IS 'Address of the channel owner, who has funded the channel.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states."to" IS 'Address of the recipient of payouts from the channel.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.to_send IS 'Amount of attoFIL successfully redeemed through the channel, paid out on Collect.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.settling_at IS 'Epoch at which the channel can be collected, zero if the channel is not settling.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_states.lane_count IS 'Number of lanes in the channel.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.paych_lane_states (
+			height bigint NOT NULL,
+			state_root text NOT NULL,
+			address text NOT NULL,
+			lane bigint NOT NULL,
+			redeemed numeric NOT NULL,
+			nonce bigint NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.paych_lane_states ADD CONSTRAINT paych_lane_states_pk PRIMARY KEY (height, state_root, address, lane);
+
+		CREATE INDEX IF NOT EXISTS paych_lane_states_height_idx ON {{ .SchemaName | default "public"}}.paych_lane_states USING btree (height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.paych_lane_states IS 'Payment channel lane states, recorded when a lane is added or changed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.height IS 'Epoch at which the lane state was recorded.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.state_root IS 'CID of the parent state root at which the lane state was recorded.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.address IS 'Address of the payment channel actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.lane IS 'Index of the lane.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.redeemed IS 'Total amount of attoFIL redeemed by vouchers in the lane.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.paych_lane_states.nonce IS 'Nonce of the last voucher redeemed in the lane.';
+		`,
+	)
+}
@@ -0,0 +1,12 @@
+package v1
+
+func init() {
+	patches.Register(
+		50,
+		`
+		ALTER TABLE {{ .SchemaName | default "public"}}.visor_processing_reports ADD COLUMN IF NOT EXISTS attempts integer NOT NULL DEFAULT 1;
+
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_processing_reports.attempts IS 'Number of times the task was run before producing the report, greater than one when the task was retried after failing.';
+		`,
+	)
+}
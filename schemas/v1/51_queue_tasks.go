@@ -0,0 +1,39 @@
+package v1
+
+func init() {
+	patches.Register(
+		51,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.visor_queue_tasks (
+			id bigserial NOT NULL,
+			queue text NOT NULL,
+			type text NOT NULL,
+			payload bytea NOT NULL,
+			state text NOT NULL,
+			retried integer NOT NULL,
+			max_retry integer NOT NULL,
+			last_error text,
+			created_at timestamp with time zone NOT NULL,
+			run_at timestamp with time zone NOT NULL,
+			locked_at timestamp with time zone,
+			last_failed_at timestamp with time zone
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.visor_queue_tasks ADD CONSTRAINT visor_queue_tasks_pk PRIMARY KEY (id);
+		CREATE INDEX IF NOT EXISTS visor_queue_tasks_queue_state_idx ON {{ .SchemaName | default "public"}}.visor_queue_tasks USING btree (queue, state, run_at);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.visor_queue_tasks IS 'Tipset tasks enqueued on postgres backed distributed indexer queues.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.id IS 'Identifier of the task.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.queue IS 'Queue the task was enqueued on, one of watch, walk, index or fill.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.type IS 'Type of the task, either tipset:index or tipset:gapfill.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.payload IS 'JSON encoded tipset and tasks to process.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.state IS 'State of the task, one of pending, active or archived.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.retried IS 'Number of times the task has been retried.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.max_retry IS 'Number of times the task may be retried before it is archived.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.last_error IS 'Error returned by the last failed attempt to process the task.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.created_at IS 'Time the task was enqueued.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.run_at IS 'Earliest time the task may be processed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.locked_at IS 'Time the lease of the worker processing the task was last renewed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_queue_tasks.last_failed_at IS 'Time of the last failed attempt to process the task.';
+		`,
+	)
+}
@@ -0,0 +1,33 @@
+package v1
+
+func init() {
+	patches.Register(
+		52,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.actor_balance_changes (
+			height bigint NOT NULL,
+			address text NOT NULL,
+			state_root text NOT NULL,
+			old_balance numeric NOT NULL,
+			new_balance numeric NOT NULL,
+			balance_delta numeric NOT NULL,
+			message_cids text[],
+			causes text[]
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.actor_balance_changes ADD CONSTRAINT actor_balance_changes_pk PRIMARY KEY (height, address, state_root);
+
+		CREATE INDEX IF NOT EXISTS actor_balance_changes_height_idx ON {{ .SchemaName | default "public"}}.actor_balance_changes USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS actor_balance_changes_address_idx ON {{ .SchemaName | default "public"}}.actor_balance_changes USING btree (address);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.actor_balance_changes IS 'Balance of each actor before and after every epoch in which its balance changed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.height IS 'Epoch at which the balance changed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.address IS 'ID address of the actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.state_root IS 'CID of the parent state root containing the new balance.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.old_balance IS 'Balance of the actor in attoFIL before the change.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.new_balance IS 'Balance of the actor in attoFIL after the change.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.balance_delta IS 'Difference between new_balance and old_balance in attoFIL.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.message_cids IS 'CIDs of the messages, including implicit messages, that transferred value to or from the actor, or whose gas fees it paid or received.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.actor_balance_changes.causes IS 'Flows the balance changed through: message for value transfers, gas for gas fees paid by a sender or received by the reward and burnt funds actors, reward for payments by the reward actor, burn for other value sent to the burnt funds actor such as penalties and vesting for withdrawals from a miner actor.';
+		`,
+	)
+}
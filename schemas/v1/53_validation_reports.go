@@ -0,0 +1,35 @@
+package v1
+
+func init() {
+	patches.Register(
+		53,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.visor_validation_reports (
+			height bigint NOT NULL,
+			check_name text NOT NULL,
+			subject text NOT NULL,
+			state_root text NOT NULL,
+			status text NOT NULL,
+			expected text,
+			actual text,
+			message text,
+			reporter text NOT NULL,
+			validated_at timestamp with time zone NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.visor_validation_reports ADD CONSTRAINT visor_validation_reports_pk PRIMARY KEY (height, check_name, subject, reporter);
+		CREATE INDEX IF NOT EXISTS visor_validation_reports_height_idx ON {{ .SchemaName | default "public"}}.visor_validation_reports USING btree (height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.visor_validation_reports IS 'Discrepancies found by validate jobs between persisted data and chain state or the data of other tables.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.height IS 'Epoch of the tipset that was validated.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.check_name IS 'Name of the check that found the discrepancy, one of burnt_fil, messages or chain_powers.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.subject IS 'Value that was checked within the height, such as a column name or miner address.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.state_root IS 'CID of the parent state root of the tipset that was validated.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.status IS 'DISCREPANCY when persisted data did not match, ERROR when the check could not be completed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.expected IS 'Value derived from chain state or another table.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.actual IS 'Value that was persisted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.message IS 'Description of the discrepancy or error.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.reporter IS 'Name of the validate job that found the discrepancy.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_validation_reports.validated_at IS 'Time the height was validated.';
+		`,
+	)
+}
@@ -0,0 +1,51 @@
+package v1
+
+func init() {
+	patches.Register(
+		54,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.miner_sector_lifecycle (
+			height bigint NOT NULL,
+			miner_id text NOT NULL,
+			sector_id bigint NOT NULL,
+			state_root text NOT NULL,
+			state text NOT NULL,
+			previous_state text,
+			cause text NOT NULL,
+			entered_epoch bigint NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.miner_sector_lifecycle ADD CONSTRAINT miner_sector_lifecycle_pk PRIMARY KEY (height, miner_id, sector_id, state_root, state);
+
+		CREATE INDEX IF NOT EXISTS miner_sector_lifecycle_height_idx ON {{ .SchemaName | default "public"}}.miner_sector_lifecycle USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS miner_sector_lifecycle_sector_idx ON {{ .SchemaName | default "public"}}.miner_sector_lifecycle USING btree (miner_id, sector_id, height, entered_epoch);
+
+		-- the epoch each state was exited is the epoch the sector entered its next state, which is derived when queried
+		-- so that it does not depend on the order transitions are persisted in.
+		CREATE OR REPLACE VIEW {{ .SchemaName | default "public"}}.miner_sector_lifecycle_view AS
+		SELECT
+			l.height,
+			l.miner_id,
+			l.sector_id,
+			l.state_root,
+			l.state,
+			l.previous_state,
+			l.cause,
+			l.entered_epoch,
+			lead(l.entered_epoch) OVER (PARTITION BY l.miner_id, l.sector_id ORDER BY l.height, l.entered_epoch) AS exited_epoch
+		FROM {{ .SchemaName | default "public"}}.miner_sector_lifecycle l;
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.miner_sector_lifecycle IS 'Lifecycle state transitions of sectors, one row for each state a sector entered.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.height IS 'Epoch at which the transition was observed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.miner_id IS 'Address of the miner who owns the sector.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.sector_id IS 'Numeric identifier of the sector.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.state_root IS 'CID of the parent state root at this epoch.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.state IS 'Lifecycle state the sector entered. One of: PRECOMMITTED, PRECOMMIT_EXPIRED, ACTIVE, FAULTY, RECOVERING, TERMINATED.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.previous_state IS 'Lifecycle state the sector exited, NULL if the sector was not known before it entered state.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.cause IS 'Event that caused the transition, as recorded in miner_sector_events.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle.entered_epoch IS 'Epoch at which the sector entered state. May be earlier than height for sectors whose miner is first observed with the sector already in state.';
+
+		COMMENT ON VIEW {{ .SchemaName | default "public"}}.miner_sector_lifecycle_view IS 'Lifecycle state transitions of sectors with the epoch at which the sector exited each state.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_sector_lifecycle_view.exited_epoch IS 'Epoch at which the sector exited state, NULL while the sector remains in state.';
+		`,
+	)
+}
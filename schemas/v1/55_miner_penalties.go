@@ -0,0 +1,29 @@
+package v1
+
+func init() {
+	patches.Register(
+		55,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.miner_penalties (
+			height bigint NOT NULL,
+			miner_id text NOT NULL,
+			state_root text NOT NULL,
+			penalty_type text NOT NULL,
+			amount numeric NOT NULL,
+			message_cids text[]
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.miner_penalties ADD CONSTRAINT miner_penalties_pk PRIMARY KEY (height, miner_id, state_root, penalty_type);
+
+		CREATE INDEX IF NOT EXISTS miner_penalties_height_idx ON {{ .SchemaName | default "public"}}.miner_penalties USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS miner_penalties_miner_id_idx ON {{ .SchemaName | default "public"}}.miner_penalties USING btree (miner_id, penalty_type);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.miner_penalties IS 'Penalties paid by miners at each epoch, by type of penalty.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.height IS 'Epoch at which the messages in whose execution the penalty was paid were executed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.miner_id IS 'Address of the miner who paid the penalty.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.state_root IS 'CID of the parent state root at which the messages were executed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.penalty_type IS 'Type of the penalty. One of: FAULT_FEE, PRECOMMIT_EXPIRY, TERMINATION_FEE, POST_DISPUTE, CONSENSUS_FAULT, FEE_DEBT_REPAYMENT, FEE_DEBT_INCURRED, DAILY_FEE, NETWORK_FEE, OTHER.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.amount IS 'Amount of the penalty in attoFIL.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_penalties.message_cids IS 'CIDs of the messages, including implicit messages, in whose execution the penalty was paid.';
+		`,
+	)
+}
@@ -0,0 +1,39 @@
+package v1
+
+func init() {
+	patches.Register(
+		56,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.method_gas_stats (
+			height bigint NOT NULL,
+			state_root text NOT NULL,
+			actor_family text NOT NULL,
+			method bigint NOT NULL,
+			method_name text,
+			message_count bigint NOT NULL,
+			gas_used_p50 bigint NOT NULL,
+			gas_used_p90 bigint NOT NULL,
+			gas_used_p99 bigint NOT NULL,
+			base_fee_burn numeric NOT NULL,
+			over_estimation_burn numeric NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.method_gas_stats ADD CONSTRAINT method_gas_stats_pk PRIMARY KEY (height, state_root, actor_family, method);
+
+		CREATE INDEX IF NOT EXISTS method_gas_stats_height_idx ON {{ .SchemaName | default "public"}}.method_gas_stats USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS method_gas_stats_method_idx ON {{ .SchemaName | default "public"}}.method_gas_stats USING btree (actor_family, method, height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.method_gas_stats IS 'Gas used by the messages executed at each epoch, by actor family and method.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.height IS 'Epoch at which the messages were executed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.state_root IS 'CID of the parent state root at which the messages were executed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.actor_family IS 'Family of the actor the messages were sent to, for example storageminer.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.method IS 'Method number called by the messages.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.method_name IS 'Name of the method, empty if it could not be determined.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.message_count IS 'Number of messages.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.gas_used_p50 IS 'Median of the gas used by the messages.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.gas_used_p90 IS '90th percentile of the gas used by the messages.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.gas_used_p99 IS '99th percentile of the gas used by the messages.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.base_fee_burn IS 'Total base fee burnt by the messages in attoFIL.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.method_gas_stats.over_estimation_burn IS 'Total overestimation burn of the messages in attoFIL.';
+		`,
+	)
+}
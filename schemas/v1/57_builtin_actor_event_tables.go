@@ -0,0 +1,152 @@
+package v1
+
+func init() {
+	patches.Register(
+		57,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.event_deal_lifecycle (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			emitter text NOT NULL,
+			event_type text NOT NULL,
+			deal_id bigint NOT NULL,
+			client text NOT NULL,
+			provider text NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.event_deal_lifecycle ADD CONSTRAINT event_deal_lifecycle_pk PRIMARY KEY (height, message_cid, event_idx);
+
+		CREATE INDEX IF NOT EXISTS event_deal_lifecycle_height_idx ON {{ .SchemaName | default "public"}}.event_deal_lifecycle USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS event_deal_lifecycle_deal_id_idx ON {{ .SchemaName | default "public"}}.event_deal_lifecycle USING btree (deal_id);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.event_deal_lifecycle IS 'Deal lifecycle events emitted by the market actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.emitter IS 'Address of the actor that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.event_type IS 'Type of the event, one of deal-published, deal-activated, deal-terminated or deal-completed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.deal_id IS 'Identifier of the deal.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.client IS 'Address of the client of the deal.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_deal_lifecycle.provider IS 'Address of the provider of the deal.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.event_verifier_balance (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			emitter text NOT NULL,
+			verifier text NOT NULL,
+			balance numeric NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.event_verifier_balance ADD CONSTRAINT event_verifier_balance_pk PRIMARY KEY (height, message_cid, event_idx);
+
+		CREATE INDEX IF NOT EXISTS event_verifier_balance_height_idx ON {{ .SchemaName | default "public"}}.event_verifier_balance USING btree (height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.event_verifier_balance IS 'Verifier balance events emitted by the verified registry actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.emitter IS 'Address of the actor that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.verifier IS 'Address of the verifier.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifier_balance.balance IS 'Datacap the verifier may allocate after the change, in bytes.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.event_verifreg_allocation (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			emitter text NOT NULL,
+			event_type text NOT NULL,
+			allocation_id bigint NOT NULL,
+			client text NOT NULL,
+			provider text NOT NULL,
+			piece_cid text,
+			piece_size bigint,
+			term_min bigint,
+			term_max bigint,
+			expiration bigint
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.event_verifreg_allocation ADD CONSTRAINT event_verifreg_allocation_pk PRIMARY KEY (height, message_cid, event_idx);
+
+		CREATE INDEX IF NOT EXISTS event_verifreg_allocation_height_idx ON {{ .SchemaName | default "public"}}.event_verifreg_allocation USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS event_verifreg_allocation_allocation_id_idx ON {{ .SchemaName | default "public"}}.event_verifreg_allocation USING btree (allocation_id);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.event_verifreg_allocation IS 'Allocation events emitted by the verified registry actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.emitter IS 'Address of the actor that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.event_type IS 'Type of the event, allocation or allocation-removed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.allocation_id IS 'Identifier of the allocation.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.client IS 'Address of the client of the allocation.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.provider IS 'Address of the provider the allocation is made to.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.piece_cid IS 'CID of the piece.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.piece_size IS 'Size of the piece in bytes.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.term_min IS 'Minimum number of epochs the piece must be stored for.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.term_max IS 'Maximum number of epochs the piece may be stored for.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_allocation.expiration IS 'Epoch by which the piece must be committed to a sector.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.event_verifreg_claim (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			emitter text NOT NULL,
+			event_type text NOT NULL,
+			claim_id bigint NOT NULL,
+			client text NOT NULL,
+			provider text NOT NULL,
+			piece_cid text,
+			piece_size bigint,
+			term_min bigint,
+			term_max bigint,
+			term_start bigint,
+			sector bigint
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.event_verifreg_claim ADD CONSTRAINT event_verifreg_claim_pk PRIMARY KEY (height, message_cid, event_idx);
+
+		CREATE INDEX IF NOT EXISTS event_verifreg_claim_height_idx ON {{ .SchemaName | default "public"}}.event_verifreg_claim USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS event_verifreg_claim_claim_id_idx ON {{ .SchemaName | default "public"}}.event_verifreg_claim USING btree (claim_id);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.event_verifreg_claim IS 'Claim events emitted by the verified registry actor.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.emitter IS 'Address of the actor that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.event_type IS 'Type of the event, claim, claim-updated or claim-removed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.claim_id IS 'Identifier of the claim.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.client IS 'Address of the client of the claim.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.provider IS 'Address of the provider storing the piece.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.piece_cid IS 'CID of the piece.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.piece_size IS 'Size of the piece in bytes.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.term_min IS 'Minimum number of epochs the piece must be stored for.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.term_max IS 'Maximum number of epochs the piece may be stored for.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.term_start IS 'Epoch at which the piece was committed.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_verifreg_claim.sector IS 'Number of the sector the piece is stored in.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.event_sector_lifecycle (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			miner_id text NOT NULL,
+			event_type text NOT NULL,
+			sector_number bigint NOT NULL,
+			unsealed_cid text,
+			piece_cids text[],
+			piece_sizes bigint[]
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.event_sector_lifecycle ADD CONSTRAINT event_sector_lifecycle_pk PRIMARY KEY (height, message_cid, event_idx);
+
+		CREATE INDEX IF NOT EXISTS event_sector_lifecycle_height_idx ON {{ .SchemaName | default "public"}}.event_sector_lifecycle USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS event_sector_lifecycle_sector_idx ON {{ .SchemaName | default "public"}}.event_sector_lifecycle USING btree (miner_id, sector_number);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.event_sector_lifecycle IS 'Sector lifecycle events emitted by miner actors.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.miner_id IS 'Address of the miner that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.event_type IS 'Type of the event, one of sector-precommitted, sector-activated, sector-updated or sector-terminated.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.sector_number IS 'Number of the sector.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.unsealed_cid IS 'CID of the unsealed data of the sector, null for sectors without data and for events other than sector-activated and sector-updated.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.piece_cids IS 'CIDs of the pieces in the sector, in the order they were emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.event_sector_lifecycle.piece_sizes IS 'Sizes of the pieces in the sector in bytes, in the same order as piece_cids.';
+		`,
+	)
+}
@@ -0,0 +1,43 @@
+package v1
+
+func init() {
+	patches.Register(
+		58,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.contract_events (
+			height bigint NOT NULL,
+			message_cid text NOT NULL,
+			event_idx bigint NOT NULL,
+			filter text NOT NULL,
+			emitter text NOT NULL,
+			emitter_eth_address text,
+			topic0 text,
+			topic1 text,
+			topic2 text,
+			topic3 text,
+			data text,
+			event_name text,
+			decoded_args jsonb
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.contract_events ADD CONSTRAINT contract_events_pk PRIMARY KEY (height, message_cid, event_idx, filter);
+
+		CREATE INDEX IF NOT EXISTS contract_events_height_idx ON {{ .SchemaName | default "public"}}.contract_events USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS contract_events_emitter_idx ON {{ .SchemaName | default "public"}}.contract_events USING btree (emitter, topic0, height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.contract_events IS 'Events emitted by FEVM contracts that matched a contract event filter of the lily config.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.height IS 'Epoch at which the event was emitted.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.message_cid IS 'CID of the message that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.event_idx IS 'Index of the event among the events emitted in the tipset.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.filter IS 'Name of the filter the event matched.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.emitter IS 'Address of the contract that emitted the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.emitter_eth_address IS 'Address of the contract in ETH.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.topic0 IS 'First topic of the event, the keccak256 hash of the event signature for non-anonymous events.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.topic1 IS 'Second topic of the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.topic2 IS 'Third topic of the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.topic3 IS 'Fourth topic of the event.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.data IS 'Data of the event in hex.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.event_name IS 'Name of the event, empty if it could not be decoded with the ABI of the filter.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.contract_events.decoded_args IS 'Arguments of the event by name, empty if it could not be decoded with the ABI of the filter.';
+		`,
+	)
+}
@@ -0,0 +1,64 @@
+package v1
+
+func init() {
+	patches.Register(
+		59,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.fevm_token_transfers (
+			height bigint NOT NULL,
+			transaction_hash text NOT NULL,
+			log_index bigint NOT NULL,
+			message text NOT NULL,
+			token_address text NOT NULL,
+			token_standard text NOT NULL,
+			event_type text NOT NULL,
+			"from" text NOT NULL,
+			"to" text NOT NULL,
+			amount numeric,
+			token_id numeric
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.fevm_token_transfers ADD CONSTRAINT fevm_token_transfers_pk PRIMARY KEY (height, transaction_hash, log_index);
+
+		CREATE INDEX IF NOT EXISTS fevm_token_transfers_height_idx ON {{ .SchemaName | default "public"}}.fevm_token_transfers USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS fevm_token_transfers_token_idx ON {{ .SchemaName | default "public"}}.fevm_token_transfers USING btree (token_address, height DESC);
+		CREATE INDEX IF NOT EXISTS fevm_token_transfers_from_idx ON {{ .SchemaName | default "public"}}.fevm_token_transfers USING hash ("from");
+		CREATE INDEX IF NOT EXISTS fevm_token_transfers_to_idx ON {{ .SchemaName | default "public"}}.fevm_token_transfers USING hash ("to");
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.fevm_token_transfers IS 'Transfer and Approval logs of ERC-20 and ERC-721 token contracts.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.height IS 'Height message was executed at.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.transaction_hash IS 'Hash of the transaction that emitted the log.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.log_index IS 'Index of the log among the logs emitted by the transaction.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.message IS 'Message CID of the transaction.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.token_address IS 'ETH address of the token contract.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.token_standard IS 'Standard of the token, ERC20 or ERC721, as determined by the number of topics of the log.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.event_type IS 'Type of the log, TRANSFER or APPROVAL.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers."from" IS 'ETH address tokens are transferred from, or of the owner for approvals.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers."to" IS 'ETH address tokens are transferred to, or of the approved spender for approvals.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.amount IS 'Amount of tokens transferred or approved, ERC-20 only.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_token_transfers.token_id IS 'Identifier of the token transferred or approved, ERC-721 only.';
+
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.fevm_tokens (
+			height bigint NOT NULL,
+			token_address text NOT NULL,
+			actor_id text NOT NULL,
+			token_standard text NOT NULL,
+			name text,
+			symbol text,
+			decimals smallint
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.fevm_tokens ADD CONSTRAINT fevm_tokens_pk PRIMARY KEY (height, token_address);
+
+		CREATE INDEX IF NOT EXISTS fevm_tokens_height_idx ON {{ .SchemaName | default "public"}}.fevm_tokens USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS fevm_tokens_token_address_idx ON {{ .SchemaName | default "public"}}.fevm_tokens USING hash (token_address);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.fevm_tokens IS 'Contracts identified as tokens when they were first seen emitting a Transfer or Approval log.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.height IS 'Height at which the token was identified.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.token_address IS 'ETH address of the token contract.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.actor_id IS 'Filecoin address of the token contract.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.token_standard IS 'Standard of the token, ERC20 or ERC721.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.name IS 'Name returned by the name() method of the contract, null if it has none.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.symbol IS 'Symbol returned by the symbol() method of the contract, null if it has none.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_tokens.decimals IS 'Decimals returned by the decimals() method of the contract, null if it has none.';
+		`,
+	)
+}
@@ -0,0 +1,29 @@
+package v1
+
+func init() {
+	patches.Register(
+		60,
+		`
+		CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.fevm_storage_changes (
+			height bigint NOT NULL,
+			actor_id text NOT NULL,
+			eth_address text NOT NULL,
+			slot text NOT NULL,
+			old_value text NOT NULL,
+			new_value text NOT NULL
+		);
+		ALTER TABLE ONLY {{ .SchemaName | default "public"}}.fevm_storage_changes ADD CONSTRAINT fevm_storage_changes_pk PRIMARY KEY (height, actor_id, slot);
+
+		CREATE INDEX IF NOT EXISTS fevm_storage_changes_height_idx ON {{ .SchemaName | default "public"}}.fevm_storage_changes USING btree (height DESC);
+		CREATE INDEX IF NOT EXISTS fevm_storage_changes_actor_idx ON {{ .SchemaName | default "public"}}.fevm_storage_changes USING btree (actor_id, slot, height DESC);
+
+		COMMENT ON TABLE {{ .SchemaName | default "public"}}.fevm_storage_changes IS 'Changes to the storage slots of EVM actors.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.height IS 'Height of the state the change is found in.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.actor_id IS 'Actor address.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.eth_address IS 'Actor Address in ETH.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.slot IS 'Storage slot, as a 32 byte hex string.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.old_value IS 'Value of the slot before the change, as a 32 byte hex string. Slots that are not set hold zero.';
+		COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fevm_storage_changes.new_value IS 'Value of the slot after the change, as a 32 byte hex string. Slots that are not set hold zero.';
+		`,
+	)
+}
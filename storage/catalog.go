@@ -2,8 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/filecoin-project/lily/config"
 	"github.com/filecoin-project/lily/model"
@@ -18,6 +22,7 @@ type Connector interface {
 func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 	c := &Catalog{
 		storages: make(map[string]model.Storage),
+		routes:   make(map[string]config.RoutingStorageConf),
 	}
 
 	for name, sc := range cfg.Postgresql {
@@ -61,26 +66,236 @@ func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 			}
 			c.storages[name] = db
 
+		case "Parquet":
+			log.Debugw("registering storage", "name", name, "type", "parquet")
+
+			opts := DefaultParquetStorageOptions()
+			opts.FilePattern = sc.FilePattern
+			opts.HeightRange = sc.HeightRange
+
+			db, err := NewParquetStorageLatest(sc.Path, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create parquet storage %q: %w", name, err)
+			}
+			c.storages[name] = db
+
 		default:
 			return nil, fmt.Errorf("unsupported format %q for storage %q", sc.Format, name)
 		}
 
 	}
 
+	for name, sc := range cfg.Stream {
+		if _, exists := c.storages[name]; exists {
+			return nil, fmt.Errorf("duplicate storage name: %q", name)
+		}
+
+		var url string
+		if sc.URLEnv != "" {
+			url = os.Getenv(sc.URLEnv)
+		} else {
+			url = sc.URL
+		}
+
+		var transport StreamTransport
+		switch sc.Transport {
+		case "Redis":
+			log.Debugw("registering storage", "name", name, "type", "stream", "transport", "redis")
+
+			t, err := NewRedisStreamTransport(url, sc.MaxLen)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create redis stream transport for storage %q: %w", name, err)
+			}
+			transport = t
+		default:
+			return nil, fmt.Errorf("unsupported transport %q for storage %q", sc.Transport, name)
+		}
+
+		opts := DefaultStreamStorageOptions()
+		if sc.Encoding != "" {
+			opts.Encoding = sc.Encoding
+		}
+		opts.TopicPattern = sc.TopicPattern
+
+		db, err := NewStreamStorageLatest(transport, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stream storage %q: %w", name, err)
+		}
+		c.storages[name] = db
+	}
+
+	for name, sc := range cfg.Routing {
+		if _, exists := c.storages[name]; exists {
+			return nil, fmt.Errorf("duplicate storage name: %q", name)
+		}
+		log.Debugw("registering storage", "name", name, "type", "routing")
+
+		for _, target := range routingTargets(sc) {
+			names, _, err := ParseStorageList(target)
+			if err != nil {
+				return nil, fmt.Errorf("routing storage %q: %w", name, err)
+			}
+			for _, n := range names {
+				if _, exists := c.storages[n]; !exists {
+					return nil, fmt.Errorf("routing storage %q: unknown storage %q", name, n)
+				}
+			}
+		}
+		for table := range sc.Routes {
+			if !isModelTable(table) {
+				return nil, fmt.Errorf("routing storage %q: unknown table %q", name, table)
+			}
+		}
+
+		c.routes[name] = sc
+	}
+
 	return c, nil
 }
 
 // A Catalog holds a list of pre-configured storage systems and can open them when requested.
 type Catalog struct {
 	storages map[string]model.Storage
+	routes   map[string]config.RoutingStorageConf
 }
 
-// Connect returns a storage that is ready for use. If name is empty, a null storage will be returned
+// Connect returns a storage that is ready for use. If name is empty, a null storage will be returned. If name is a
+// list of storages separated by StorageListSeparator a CompositeStorage writing to each of them will be returned, see
+// ParseStorageList.
 func (c *Catalog) Connect(ctx context.Context, name string, md Metadata) (model.Storage, error) {
 	if name == "" {
 		return &NullStorage{}, nil
 	}
 
+	if strings.Contains(name, StorageListSeparator) || strings.Contains(name, storagePolicySeparator) {
+		return c.connectComposite(ctx, name, md)
+	}
+
+	return c.connect(ctx, name, md)
+}
+
+func (c *Catalog) connectComposite(ctx context.Context, list string, md Metadata) (model.Storage, error) {
+	names, policies, err := ParseStorageList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations := make([]Destination, 0, len(names))
+	for _, name := range names {
+		s, err := c.connect(ctx, name, md)
+		if err != nil {
+			return nil, fmt.Errorf("connect storage %q: %w", name, err)
+		}
+		destinations = append(destinations, Destination{
+			Name:    name,
+			Storage: s,
+			Policy:  policies[name],
+		})
+	}
+	return NewCompositeStorage(destinations...), nil
+}
+
+func (c *Catalog) connectRouting(ctx context.Context, sc config.RoutingStorageConf, md Metadata) (model.Storage, error) {
+	storages := map[string]model.Storage{}
+	for _, target := range routingTargets(sc) {
+		if _, ok := storages[target]; ok {
+			continue
+		}
+		s, err := c.Connect(ctx, target, md)
+		if err != nil {
+			return nil, fmt.Errorf("connect storage %q: %w", target, err)
+		}
+		storages[target] = s
+	}
+	return NewRoutingStorage(storages, sc.Routes, sc.Default)
+}
+
+// errLocksNotSupported is returned by Catalog.lock for storages that do not provide locks.
+var errLocksNotSupported = errors.New("storage does not support locks")
+
+// Lock returns the lock identified by lockName in the named storage. Lily processes using the same storage contend for
+// locks with the same name. A postgres storage provides a SessionLock and a CSV or Parquet storage provides a FileLock
+// in its output directory. The lock of a list of storages is taken in the first storage of the list that provides
+// locks, and the lock of a routing storage in its default storage or, if that does not provide locks, the first of
+// the storages it routes tables to that does.
+func (c *Catalog) Lock(ctx context.Context, storageName string, lockName string) (Lock, error) {
+	if lockName == "" || strings.ContainsAny(lockName, `/\`) {
+		return nil, fmt.Errorf("invalid lock name: %q", lockName)
+	}
+	l, err := c.lock(ctx, storageName, lockName)
+	if errors.Is(err, errLocksNotSupported) {
+		return nil, fmt.Errorf("storage %q does not support locks", storageName)
+	}
+	return l, err
+}
+
+func (c *Catalog) lock(ctx context.Context, storageName string, lockName string) (Lock, error) {
+	var candidates []string
+	if strings.Contains(storageName, StorageListSeparator) || strings.Contains(storageName, storagePolicySeparator) {
+		names, _, err := ParseStorageList(storageName)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("empty storage list: %q", storageName)
+		}
+		candidates = names
+	} else if sc, ok := c.routes[storageName]; ok {
+		candidates = []string{sc.Default}
+		var targets []string
+		for _, target := range sc.Routes {
+			targets = append(targets, target)
+		}
+		// every process must choose the same storage.
+		sort.Strings(targets)
+		candidates = append(candidates, targets...)
+	}
+	if len(candidates) > 0 {
+		for _, name := range candidates {
+			l, err := c.lock(ctx, name, lockName)
+			if errors.Is(err, errLocksNotSupported) {
+				continue
+			}
+			return l, err
+		}
+		return nil, errLocksNotSupported
+	}
+
+	s, exists := c.storages[storageName]
+	if !exists {
+		return nil, fmt.Errorf("unknown storage: %q", storageName)
+	}
+	switch st := s.(type) {
+	case *Database:
+		if !st.IsConnected(ctx) {
+			if err := st.Connect(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return NewSessionLock(st.db, NamedAdvisoryLock(lockName)), nil
+	case *CSVStorage:
+		return NewFileLock(filepath.Join(st.path, ".lily-"+lockName+".lock")), nil
+	case *ParquetStorage:
+		return NewFileLock(filepath.Join(st.path, ".lily-"+lockName+".lock")), nil
+	default:
+		return nil, errLocksNotSupported
+	}
+}
+
+// routingTargets returns the names of the storages a routing storage writes to.
+func routingTargets(sc config.RoutingStorageConf) []string {
+	targets := []string{sc.Default}
+	for _, target := range sc.Routes {
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func (c *Catalog) connect(ctx context.Context, name string, md Metadata) (model.Storage, error) {
+	if sc, ok := c.routes[name]; ok {
+		return c.connectRouting(ctx, sc, md)
+	}
+
 	s, exists := c.storages[name]
 	if !exists {
 		return nil, fmt.Errorf("unknown storage: %q", name)
@@ -106,6 +321,30 @@ func (c *Catalog) Connect(ctx context.Context, name string, md Metadata) (model.
 	return s, nil
 }
 
+// Close closes all storages in the catalog that hold open resources, such as connections or partially written files.
+func (c *Catalog) Close(ctx context.Context) error {
+	var errs []error
+	for name, s := range c.storages {
+		// Storages that need connecting only need closing if they were connected
+		if cs, ok := s.(Connector); ok && !cs.IsConnected(ctx) {
+			continue
+		}
+
+		cs, ok := s.(Closer)
+		if !ok {
+			continue
+		}
+		if err := cs.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("close storage %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type Closer interface {
+	Close(context.Context) error
+}
+
 type StorageWithMetadata interface {
 	// WithMetadata returns a storage based configured with the supplied metadata
 	WithMetadata(Metadata) model.Storage
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/model"
+)
+
+func TestCatalogLock(t *testing.T) {
+	ctx := context.Background()
+
+	parquet, err := NewParquetStorageLatest(t.TempDir(), DefaultParquetStorageOptions())
+	require.NoError(t, err)
+	c := &Catalog{
+		storages: map[string]model.Storage{
+			"mem":     NewMemStorageLatest(),
+			"parquet": parquet,
+		},
+		routes: map[string]config.RoutingStorageConf{
+			"routing": {Default: "mem", Routes: map[string]string{"test_models": "parquet"}},
+		},
+	}
+
+	held, err := c.Lock(ctx, "parquet", "leader")
+	require.NoError(t, err)
+	require.NoError(t, held.Lock(ctx))
+	defer func() { require.NoError(t, held.Unlock(ctx)) }()
+
+	// lists and routing storages lock in the first of their storages that provides locks
+	for _, name := range []string{"parquet", "mem,parquet:best-effort", "routing"} {
+		l, err := c.Lock(ctx, name, "leader")
+		require.NoError(t, err, name)
+		require.ErrorIs(t, l.Lock(ctx), ErrLockNotAcquired, name)
+	}
+
+	_, err = c.Lock(ctx, "mem", "leader")
+	require.Error(t, err)
+	_, err = c.Lock(ctx, "parquet", "../leader")
+	require.Error(t, err)
+}
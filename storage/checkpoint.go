@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+// A Checkpointer is a storage that can record the progress of a job so that it may be resumed after a restart.
+type Checkpointer interface {
+	// LoadCheckpoint returns the checkpoint saved for the named job, or nil if there is none.
+	LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error)
+	// SaveCheckpoint saves a checkpoint, replacing any existing checkpoint for the same job.
+	SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error
+}
+
+// CheckpointerOf returns s as a Checkpointer if it supports checkpoints. A CompositeStorage supports checkpoints when
+// one of its destinations does and a RoutingStorage when its default storage does.
+func CheckpointerOf(s model.Storage) (Checkpointer, bool) {
+	switch st := s.(type) {
+	case *CompositeStorage:
+		if !st.supportsCheckpoints() {
+			return nil, false
+		}
+	case *RoutingStorage:
+		if _, ok := CheckpointerOf(st.storages[st.fallback]); !ok {
+			return nil, false
+		}
+	}
+	cp, ok := s.(Checkpointer)
+	return cp, ok
+}
+
+var (
+	_ Checkpointer = (*Database)(nil)
+	_ Checkpointer = (*CSVStorage)(nil)
+	_ Checkpointer = (*ParquetStorage)(nil)
+)
+
+func (d *Database) LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	cp := &visor.JobCheckpoint{}
+	if err := d.AsORM().ModelContext(ctx, cp).
+		Where("job_name = ?", jobName).
+		Select(); err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying job checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func (d *Database) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	if _, err := d.AsORM().ModelContext(ctx, cp).
+		OnConflict("(job_name) DO UPDATE").
+		Set("job_type = EXCLUDED.job_type, height = EXCLUDED.height, updated_at = EXCLUDED.updated_at").
+		Insert(); err != nil {
+		return fmt.Errorf("saving job checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *CSVStorage) LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	return NewFileCheckpointer(c.path).LoadCheckpoint(ctx, jobName)
+}
+
+func (c *CSVStorage) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	return NewFileCheckpointer(c.path).SaveCheckpoint(ctx, cp)
+}
+
+func (c *ParquetStorage) LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	return NewFileCheckpointer(c.path).LoadCheckpoint(ctx, jobName)
+}
+
+func (c *ParquetStorage) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	return NewFileCheckpointer(c.path).SaveCheckpoint(ctx, cp)
+}
+
+// FileCheckpointer keeps job checkpoints as json files named after the job in a directory.
+type FileCheckpointer struct {
+	dir string
+}
+
+var _ Checkpointer = (*FileCheckpointer)(nil)
+
+func NewFileCheckpointer(dir string) *FileCheckpointer {
+	return &FileCheckpointer{dir: dir}
+}
+
+func (f *FileCheckpointer) filename(jobName string) string {
+	return filepath.Join(f.dir, jobName+".checkpoint.json")
+}
+
+func (f *FileCheckpointer) LoadCheckpoint(_ context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	data, err := os.ReadFile(f.filename(jobName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	cp := &visor.JobCheckpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("decode checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// SaveCheckpoint writes the checkpoint to a temporary file which is then renamed so that an existing checkpoint is
+// never left partially written.
+func (f *FileCheckpointer) SaveCheckpoint(_ context.Context, cp *visor.JobCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	filename := f.filename(cp.JobName)
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
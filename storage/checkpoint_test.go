@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+func TestCSVCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	st, err := NewCSVStorage(dir, model.Version{Major: 1}, DefaultCSVStorageOptions())
+	require.NoError(t, err)
+
+	cp, err := st.LoadCheckpoint(ctx, "walk_1")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, height := range []int64{20, 19} {
+		err = st.SaveCheckpoint(ctx, &visor.JobCheckpoint{JobName: "walk_1", JobType: "walk", Height: height, UpdatedAt: now})
+		require.NoError(t, err)
+	}
+
+	cp, err = st.LoadCheckpoint(ctx, "walk_1")
+	require.NoError(t, err)
+	require.NotNil(t, cp)
+	assert.EqualValues(t, 19, cp.Height)
+	assert.Equal(t, "walk", cp.JobType)
+	assert.True(t, now.Equal(cp.UpdatedAt))
+
+	// checkpoints are kept per job
+	cp, err = st.LoadCheckpoint(ctx, "walk_2")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
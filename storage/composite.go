@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+// A FailurePolicy determines how a CompositeStorage handles a failure to persist to one of its destinations.
+type FailurePolicy string
+
+const (
+	// FailAll fails the persist operation of the composite storage when the destination fails.
+	FailAll FailurePolicy = "fail-all"
+	// BestEffort reports a failure of the destination in the log and the persist_failure metric without failing the
+	// persist operation of the composite storage.
+	BestEffort FailurePolicy = "best-effort"
+)
+
+// StorageListSeparator separates the names of the destinations of a composite storage, for example
+// "Database1,CSV1:best-effort".
+const StorageListSeparator = ","
+
+// storagePolicySeparator separates the name of a destination from its failure policy.
+const storagePolicySeparator = ":"
+
+// ParseFailurePolicy returns the FailurePolicy named by s.
+func ParseFailurePolicy(s string) (FailurePolicy, error) {
+	switch p := FailurePolicy(s); p {
+	case FailAll, BestEffort:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown failure policy %q, expected %q or %q", s, FailAll, BestEffort)
+	}
+}
+
+// ParseStorageList parses a list of storage names in the form "name[:policy],...". Destinations without a policy
+// use FailAll.
+func ParseStorageList(list string) ([]string, map[string]FailurePolicy, error) {
+	var names []string
+	policies := map[string]FailurePolicy{}
+	for _, entry := range strings.Split(list, StorageListSeparator) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, policy := entry, FailAll
+		if i := strings.Index(entry, storagePolicySeparator); i >= 0 {
+			p, err := ParseFailurePolicy(entry[i+len(storagePolicySeparator):])
+			if err != nil {
+				return nil, nil, fmt.Errorf("storage %q: %w", entry, err)
+			}
+			name, policy = entry[:i], p
+		}
+		if _, dup := policies[name]; dup {
+			return nil, nil, fmt.Errorf("duplicate storage name: %q", name)
+		}
+		names = append(names, name)
+		policies[name] = policy
+	}
+	return names, policies, nil
+}
+
+// A Destination is a storage written to by a CompositeStorage.
+type Destination struct {
+	Name    string
+	Storage model.Storage
+	Policy  FailurePolicy
+}
+
+// CompositeStorage fans out persist operations to several storages concurrently. Metrics recorded while persisting
+// to a destination are tagged with its name.
+type CompositeStorage struct {
+	destinations []Destination
+}
+
+var (
+	_ model.Storage = (*CompositeStorage)(nil)
+	_ Reverter      = (*CompositeStorage)(nil)
+	_ Checkpointer  = (*CompositeStorage)(nil)
+)
+
+func NewCompositeStorage(destinations ...Destination) *CompositeStorage {
+	return &CompositeStorage{destinations: destinations}
+}
+
+// PersistBatch persists the models to every destination. It returns the errors of destinations with the FailAll
+// policy, the batch may have been persisted to the other destinations.
+func (c *CompositeStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	return c.each(ctx, "persist", func(ctx context.Context, d Destination) error {
+		return d.Storage.PersistBatch(ctx, ps...)
+	})
+}
+
+// RevertTipSet reverts the tipset in every destination that supports reverting data.
+func (c *CompositeStorage) RevertTipSet(ctx context.Context, height int64, stateRoot string) error {
+	return c.each(ctx, "revert", func(ctx context.Context, d Destination) error {
+		r, ok := d.Storage.(Reverter)
+		if !ok {
+			return nil
+		}
+		return r.RevertTipSet(ctx, height, stateRoot)
+	})
+}
+
+// LoadCheckpoint loads the checkpoint from the first destination with the FailAll policy that supports checkpoints, or
+// the first destination that supports checkpoints if none of them have that policy.
+func (c *CompositeStorage) LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	var (
+		from   Checkpointer
+		name   string
+		policy FailurePolicy
+	)
+	for _, d := range c.destinations {
+		st, ok := CheckpointerOf(d.Storage)
+		if !ok {
+			continue
+		}
+		if from == nil || (policy != FailAll && d.Policy == FailAll) {
+			from, name, policy = st, d.Name, d.Policy
+		}
+	}
+	if from == nil {
+		return nil, fmt.Errorf("no storage supports checkpoints")
+	}
+	cp, err := from.LoadCheckpoint(ctx, jobName)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint from storage %q: %w", name, err)
+	}
+	return cp, nil
+}
+
+// SaveCheckpoint saves the checkpoint to every destination that supports checkpoints.
+func (c *CompositeStorage) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	return c.each(ctx, "save checkpoint", func(ctx context.Context, d Destination) error {
+		st, ok := CheckpointerOf(d.Storage)
+		if !ok {
+			return nil
+		}
+		return st.SaveCheckpoint(ctx, cp)
+	})
+}
+
+// supportsCheckpoints reports whether any destination supports checkpoints.
+func (c *CompositeStorage) supportsCheckpoints() bool {
+	for _, d := range c.destinations {
+		if _, ok := CheckpointerOf(d.Storage); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CompositeStorage) each(ctx context.Context, op string, fn func(context.Context, Destination) error) error {
+	errs := make([]error, len(c.destinations))
+
+	var wg sync.WaitGroup
+	for i, d := range c.destinations {
+		i, d := i, d
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, _ := tag.New(ctx, tag.Upsert(metrics.Storage, d.Name))
+			err := fn(ctx, d)
+			if err == nil {
+				return
+			}
+			if d.Policy == BestEffort {
+				stats.Record(ctx, metrics.PersistFailure.M(1))
+				log.Errorw("failed to "+op+" to best-effort storage", "storage", d.Name, "error", err)
+				return
+			}
+			errs[i] = fmt.Errorf("%s to storage %q: %w", op, d.Name, err)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+type failingStorage struct{}
+
+func (failingStorage) PersistBatch(_ context.Context, _ ...model.Persistable) error {
+	return errors.New("unavailable")
+}
+
+func TestParseStorageList(t *testing.T) {
+	names, policies, err := ParseStorageList("Database1, CSV1:best-effort,Stream1:fail-all")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Database1", "CSV1", "Stream1"}, names)
+	assert.Equal(t, map[string]FailurePolicy{"Database1": FailAll, "CSV1": BestEffort, "Stream1": FailAll}, policies)
+
+	_, _, err = ParseStorageList("Database1,CSV1:sometimes")
+	assert.Error(t, err)
+
+	_, _, err = ParseStorageList("Database1,Database1:best-effort")
+	assert.Error(t, err)
+}
+
+func TestCompositeStoragePersist(t *testing.T) {
+	ctx := context.Background()
+	tm := &TestModel{Height: 42, Block: "blocka", Message: "msg1"}
+
+	t.Run("fail-all", func(t *testing.T) {
+		mem := NewMemStorageLatest()
+		c := NewCompositeStorage(
+			Destination{Name: "mem", Storage: mem, Policy: FailAll},
+			Destination{Name: "failing", Storage: failingStorage{}, Policy: FailAll},
+		)
+		err := c.PersistBatch(ctx, tm)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"failing"`)
+		// the batch is still persisted to the destinations that succeeded
+		assert.Len(t, mem.Data["test_models"], 1)
+	})
+
+	t.Run("best-effort", func(t *testing.T) {
+		mem := NewMemStorageLatest()
+		c := NewCompositeStorage(
+			Destination{Name: "mem", Storage: mem, Policy: FailAll},
+			Destination{Name: "failing", Storage: failingStorage{}, Policy: BestEffort},
+		)
+		require.NoError(t, c.PersistBatch(ctx, tm))
+		assert.Len(t, mem.Data["test_models"], 1)
+	})
+}
+
+func TestCompositeStorageCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	cp := &visor.JobCheckpoint{JobName: "walk_1", JobType: "walk", Height: 20, UpdatedAt: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)}
+
+	newCSV := func(t *testing.T) *CSVStorage {
+		st, err := NewCSVStorageLatest(t.TempDir(), DefaultCSVStorageOptions())
+		require.NoError(t, err)
+		return st
+	}
+
+	t.Run("saved to every destination", func(t *testing.T) {
+		optional, required := newCSV(t), newCSV(t)
+		c := NewCompositeStorage(
+			Destination{Name: "mem", Storage: NewMemStorageLatest(), Policy: FailAll},
+			Destination{Name: "optional", Storage: optional, Policy: BestEffort},
+			Destination{Name: "required", Storage: required, Policy: FailAll},
+		)
+		got, ok := CheckpointerOf(c)
+		require.True(t, ok)
+		require.NoError(t, got.SaveCheckpoint(ctx, cp))
+
+		for _, st := range []*CSVStorage{optional, required} {
+			saved, err := st.LoadCheckpoint(ctx, "walk_1")
+			require.NoError(t, err)
+			require.NotNil(t, saved)
+			assert.EqualValues(t, 20, saved.Height)
+		}
+	})
+
+	t.Run("loaded from first required destination", func(t *testing.T) {
+		optional, required := newCSV(t), newCSV(t)
+		require.NoError(t, optional.SaveCheckpoint(ctx, &visor.JobCheckpoint{JobName: "walk_1", JobType: "walk", Height: 30}))
+		require.NoError(t, required.SaveCheckpoint(ctx, cp))
+		c := NewCompositeStorage(
+			Destination{Name: "optional", Storage: optional, Policy: BestEffort},
+			Destination{Name: "required", Storage: required, Policy: FailAll},
+		)
+		saved, err := c.LoadCheckpoint(ctx, "walk_1")
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.EqualValues(t, 20, saved.Height)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		c := NewCompositeStorage(
+			Destination{Name: "mem", Storage: NewMemStorageLatest(), Policy: FailAll},
+			Destination{Name: "failing", Storage: failingStorage{}, Policy: BestEffort},
+		)
+		_, ok := CheckpointerOf(c)
+		assert.False(t, ok)
+	})
+}
@@ -39,7 +39,7 @@ func getCSVModelTable(v interface{}, version model.Version) table {
 	q := orm.NewQuery(nil, v)
 	tm := q.TableModel()
 	m := tm.Table()
-	name := stripQuotes(m.SQLNameForSelects)
+	name := StripQuotes(m.SQLNameForSelects)
 
 	csvModelTablesMu.Lock()
 	defer csvModelTablesMu.Unlock()
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// A FileLock is an exclusive lock on a file. Where the operating system supports advisory file locks the lock is held
+// by an open file descriptor, so that it is released by the operating system when the process holding it exits, and
+// the file is left in place when the lock is released. Elsewhere the lock is held by creating the file, which is
+// removed when the lock is released and must be removed by hand if the process holding the lock exits without
+// releasing it.
+type FileLock struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+var _ Lock = (*FileLock)(nil)
+
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path}
+}
+
+func (l *FileLock) Lock(_ context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f != nil {
+		return fmt.Errorf("lock already held")
+	}
+
+	f, err := lockFile(l.path)
+	if err != nil {
+		return err
+	}
+
+	// record the holder of the lock to help operators find it.
+	if err := f.Truncate(0); err == nil {
+		hostname, _ := os.Hostname()
+		_, _ = f.WriteAt([]byte(hostname+" "+strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	l.f = f
+	return nil
+}
+
+func (l *FileLock) Unlock(_ context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+
+	err := unlockFile(l.f)
+	l.f = nil
+	if err != nil {
+		return fmt.Errorf("unlock file: %w", err)
+	}
+	return nil
+}
+
+// Held reports whether the lock is held. A file lock cannot be lost while it is held.
+func (l *FileLock) Held(_ context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f != nil, nil
+}
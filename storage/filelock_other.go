@@ -0,0 +1,30 @@
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// lockFile creates the file at path, failing if it already exists.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, ErrLockNotAcquired
+		}
+		return nil, fmt.Errorf("create lock file: %w", err)
+	}
+	return f, nil
+}
+
+// unlockFile closes f and removes the file created by lockFile.
+func unlockFile(f *os.File) error {
+	err := f.Close()
+	if rerr := os.Remove(f.Name()); rerr != nil && err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	leader := NewFileLock(path)
+	standby := NewFileLock(path)
+
+	require.NoError(t, leader.Lock(ctx))
+	held, err := leader.Held(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	require.ErrorIs(t, standby.Lock(ctx), ErrLockNotAcquired)
+	held, err = standby.Held(ctx)
+	require.NoError(t, err)
+	require.False(t, held)
+
+	require.NoError(t, leader.Unlock(ctx))
+	require.NoError(t, standby.Lock(ctx))
+	require.ErrorIs(t, leader.Lock(ctx), ErrLockNotAcquired)
+	require.NoError(t, standby.Unlock(ctx))
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/types"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+// CSVLoadOptions describe the csv files read by LoadCSV. They must match the options of the CSVStorage that wrote them.
+type CSVLoadOptions struct {
+	CSVStorageOptions
+
+	// JobName replaces the {jobname} token of FilePattern. When empty the files of every job are loaded.
+	JobName string
+
+	// Tables limits loading to the named tables. When empty every table is loaded.
+	Tables []string
+}
+
+// LoadCSV loads the csv files written by a CSVStorage to dir into the database. Rows are upserted on the primary key
+// of their table, rows that conflict are left unchanged when the table has no columns outside its primary key. When a
+// file holds more than one row with the same primary key the last one is loaded. Each
+// file is loaded in its own transaction. The database schema must be compatible with the models of this version of
+// lily, which are also used to write csv files, and the headers of the files are checked against the columns of
+// their table. It returns the number of rows loaded into each table.
+func (d *Database) LoadCSV(ctx context.Context, dir string, opts CSVLoadOptions) (map[string]int64, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	if err := d.VerifyCurrentSchema(ctx); err != nil {
+		return nil, fmt.Errorf("verify schema: %w", err)
+	}
+	if opts.FilePattern == "" {
+		opts.FilePattern = DefaultFilePattern
+	}
+
+	type versionable interface {
+		AsVersion(model.Version) (interface{}, bool)
+	}
+
+	models := map[string]interface{}{}
+	for _, m := range Models {
+		if vm, ok := m.(versionable); ok {
+			vm, ok := vm.AsVersion(d.version)
+			if !ok {
+				return nil, fmt.Errorf("model %T does not support version %s", m, d.version)
+			}
+			m = vm
+		}
+		models[getCSVModelTable(m, d.version).name] = m
+	}
+
+	tables := opts.Tables
+	if len(tables) == 0 {
+		for name := range models {
+			tables = append(tables, name)
+		}
+		sort.Strings(tables)
+	}
+	for _, name := range tables {
+		if _, ok := models[name]; !ok {
+			return nil, fmt.Errorf("unknown table: %s", name)
+		}
+	}
+
+	jobName := opts.JobName
+	if jobName == "" {
+		jobName = "*"
+	}
+
+	loaded := map[string]int64{}
+	for _, name := range tables {
+		r := strings.NewReplacer(
+			FilePatternTokenTable, name,
+			FilePatternTokenJobName, jobName,
+		)
+		paths, err := filepath.Glob(filepath.Join(dir, r.Replace(opts.FilePattern)))
+		if err != nil {
+			return nil, fmt.Errorf("find files of table %s: %w", name, err)
+		}
+		for _, path := range paths {
+			n, err := d.loadCSVFile(ctx, models[name], path, opts.OmitHeader)
+			if err != nil {
+				return loaded, fmt.Errorf("load %s: %w", path, err)
+			}
+			log.Infow("loaded csv file", "table", name, "file", path, "rows", n)
+			loaded[name] += n
+		}
+	}
+	return loaded, nil
+}
+
+// loadCSVFile copies the rows of the csv file at path into a temporary table and upserts them into the table of m.
+func (d *Database) loadCSVFile(ctx context.Context, m interface{}, path string, omitHeader bool) (int64, error) {
+	t := getCSVModelTable(m, d.version)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = len(t.columns)
+	if !omitHeader {
+		header, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		} else if err != nil {
+			return 0, fmt.Errorf("read header: %w", err)
+		}
+		for i := range header {
+			if header[i] != t.columns[i] {
+				return 0, fmt.Errorf("header column %d is %q, expected %q for table %s at schema version %s", i, header[i], t.columns[i], t.name, d.version)
+			}
+		}
+	}
+
+	columns := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = `"` + c + `"`
+	}
+	cols := types.Safe(strings.Join(columns, ", "))
+	tmp := pg.Ident("lily_load_" + t.name)
+
+	var loaded int64
+	err = d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if _, err := tx.ExecContext(ctx, `CREATE TEMPORARY TABLE ? (LIKE ? INCLUDING DEFAULTS) ON COMMIT DROP`, tmp, pg.Ident(t.name)); err != nil {
+			return fmt.Errorf("create temporary table: %w", err)
+		}
+		// numbers the rows in the order they are copied from the file.
+		if _, err := tx.ExecContext(ctx, `ALTER TABLE ? ADD COLUMN lily_load_row bigserial`, tmp); err != nil {
+			return fmt.Errorf("create temporary table: %w", err)
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(copyCSVRecords(cr, csv.NewWriter(pw), t.types))
+		}()
+		if _, err := tx.CopyFrom(pr, `COPY ? (?) FROM STDIN WITH (FORMAT csv, NULL 'NULL')`, tmp, cols); err != nil {
+			_ = pr.CloseWithError(err)
+			return fmt.Errorf("copy rows: %w", err)
+		}
+
+		// a file may hold the same row more than once if it was appended to by repeated runs, only one row per
+		// primary key may be upserted by a statement so the last one written is kept.
+		conflict, upsert := GenerateUpsertStrings(m)
+		pks := strings.TrimSuffix(conflict, " DO UPDATE")
+		action := types.Safe("DO NOTHING")
+		if upsert != "" {
+			action = types.Safe("DO UPDATE SET " + upsert)
+		}
+		res, err := tx.ExecContext(ctx, `INSERT INTO ? (?) SELECT DISTINCT ON ? ? FROM ? ORDER BY ?, lily_load_row DESC ON CONFLICT ? ?`,
+			pg.Ident(t.name), cols, types.Safe(pks), cols, tmp, types.Safe(strings.Trim(pks, "()")), types.Safe(pks), action,
+		)
+		if err != nil {
+			return fmt.Errorf("upsert rows: %w", err)
+		}
+		loaded = int64(res.RowsAffected())
+		return nil
+	})
+	return loaded, err
+}
+
+// copyCSVRecords writes the records read from r to w, converting values written by CSVBatch to the input format
+// expected by postgres where they differ.
+func copyCSVRecords(r *csv.Reader, w *csv.Writer, columnTypes []string) error {
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		for i, v := range record {
+			// arrays are written using go's formatting of slices: [a b c]
+			if strings.HasSuffix(columnTypes[i], "[]") && strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+				record[i] = "{" + strings.Join(strings.Fields(v[1:len(v)-1]), ",") + "}"
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
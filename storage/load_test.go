@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model/blocks"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+func TestCopyCSVRecords(t *testing.T) {
+	in := "42,NULL,[bafy1 bafy2],[1 2 3],\"{\"\"a\"\":1}\"\n" +
+		"43,,[],[],null\n"
+
+	r := csv.NewReader(strings.NewReader(in))
+	var out bytes.Buffer
+	err := copyCSVRecords(r, csv.NewWriter(&out), []string{"bigint", "text", "text[]", "bigint[]", "jsonb"})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"42,NULL,\"{bafy1,bafy2}\",\"{1,2,3}\",\"{\"\"a\"\":1}\"\n"+
+			"43,,{},{},null\n",
+		out.String())
+}
+
+func TestLoadCSV(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	_, err = db.Exec(`TRUNCATE TABLE block_headers, drand_block_entries`)
+	require.NoError(t, err, "truncating tables")
+
+	d, err := NewDatabaseFromDB(ctx, db, "public")
+	require.NoError(t, err)
+
+	header := func(height int64, cid, miner string) *blocks.BlockHeader {
+		return &blocks.BlockHeader{
+			Height:          height,
+			Cid:             cid,
+			Miner:           miner,
+			ParentWeight:    "1",
+			ParentBaseFee:   "100",
+			ParentStateRoot: "root",
+		}
+	}
+
+	// rows already in the database that conflict with the files
+	_, err = db.Model(header(1, "blocka", "f01000")).Insert()
+	require.NoError(t, err)
+	_, err = db.Model(&blocks.DrandBlockEntrie{Round: 1, Block: "blocka"}).Insert()
+	require.NoError(t, err)
+
+	// two runs appending to the same files, the second repeating a row and changing another
+	dir := t.TempDir()
+	st, err := NewCSVStorageLatest(dir, DefaultCSVStorageOptions())
+	require.NoError(t, err)
+	require.NoError(t, st.PersistBatch(ctx,
+		blocks.BlockHeaders{header(1, "blocka", "f01001"), header(2, "blockb", "f01002")},
+		&blocks.DrandBlockEntrie{Round: 1, Block: "blocka"},
+		&blocks.DrandBlockEntrie{Round: 2, Block: "blockb"},
+	))
+	require.NoError(t, st.PersistBatch(ctx,
+		blocks.BlockHeaders{header(2, "blockb", "f01002"), header(3, "blockc", "f01003"), header(1, "blocka", "f01004")},
+		&blocks.DrandBlockEntrie{Round: 2, Block: "blockb"},
+	))
+
+	loaded, err := d.LoadCSV(ctx, dir, CSVLoadOptions{
+		CSVStorageOptions: DefaultCSVStorageOptions(),
+		Tables:            []string{"block_headers", "drand_block_entries"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"block_headers":       3, // blocka updated, blockb and blockc inserted
+		"drand_block_entries": 1, // round 1 left unchanged, round 2 inserted
+	}, loaded)
+
+	// tables with columns outside their primary key are updated with the last row written for each key
+	var headers []*blocks.BlockHeader
+	require.NoError(t, db.Model(&headers).Order("height").Select())
+	assert.Equal(t, []*blocks.BlockHeader{
+		header(1, "blocka", "f01004"),
+		header(2, "blockb", "f01002"),
+		header(3, "blockc", "f01003"),
+	}, headers)
+
+	// tables whose columns are all in their primary key are left unchanged on conflict
+	var entries []*blocks.DrandBlockEntrie
+	require.NoError(t, db.Model(&entries).Order("round").Select())
+	assert.Equal(t, []*blocks.DrandBlockEntrie{
+		{Round: 1, Block: "blocka"},
+		{Round: 2, Block: "blockb"},
+	}, entries)
+
+	// loading the files again changes nothing
+	loaded, err = d.LoadCSV(ctx, dir, CSVLoadOptions{
+		CSVStorageOptions: DefaultCSVStorageOptions(),
+		Tables:            []string{"drand_block_entries"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"drand_block_entries": 0}, loaded)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"sync"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 var ErrLockNotAcquired = errors.New("lock not acquired")
@@ -17,7 +20,7 @@ var ErrLockNotReleased = errors.New("lock not released")
 type AdvisoryLock int64
 
 // LockShared tries to acquire a session scoped exclusive advisory lock.
-func (l AdvisoryLock) LockExclusive(ctx context.Context, db *pg.DB) error {
+func (l AdvisoryLock) LockExclusive(ctx context.Context, db orm.DB) error {
 	var acquired bool
 	_, err := db.QueryOneContext(ctx, pg.Scan(&acquired), `SELECT pg_try_advisory_lock(?);`, int64(l))
 	if err != nil {
@@ -30,7 +33,7 @@ func (l AdvisoryLock) LockExclusive(ctx context.Context, db *pg.DB) error {
 }
 
 // UnlockExclusive releases an exclusive advisory lock.
-func (l AdvisoryLock) UnlockExclusive(ctx context.Context, db *pg.DB) error {
+func (l AdvisoryLock) UnlockExclusive(ctx context.Context, db orm.DB) error {
 	var released bool
 	_, err := db.QueryOneContext(ctx, pg.Scan(&released), `SELECT pg_advisory_unlock(?);`, int64(l))
 	if err != nil {
@@ -43,7 +46,7 @@ func (l AdvisoryLock) UnlockExclusive(ctx context.Context, db *pg.DB) error {
 }
 
 // LockShared tries to acquire a session scoped shared advisory lock.
-func (l AdvisoryLock) LockShared(ctx context.Context, db *pg.DB) error {
+func (l AdvisoryLock) LockShared(ctx context.Context, db orm.DB) error {
 	var acquired bool
 	_, err := db.QueryOneContext(ctx, pg.Scan(&acquired), `SELECT pg_try_advisory_lock_shared(?);`, int64(l))
 	if err != nil {
@@ -56,7 +59,7 @@ func (l AdvisoryLock) LockShared(ctx context.Context, db *pg.DB) error {
 }
 
 // UnlockShared releases a shared advisory lock.
-func (l AdvisoryLock) UnlockShared(ctx context.Context, db *pg.DB) error {
+func (l AdvisoryLock) UnlockShared(ctx context.Context, db orm.DB) error {
 	var released bool
 	_, err := db.QueryOneContext(ctx, pg.Scan(&released), `SELECT pg_advisory_unlock_shared(?);`, int64(l))
 	if err != nil {
@@ -67,3 +70,89 @@ func (l AdvisoryLock) UnlockShared(ctx context.Context, db *pg.DB) error {
 	}
 	return nil
 }
+
+// NamedAdvisoryLock returns the AdvisoryLock identified by name.
+func NamedAdvisoryLock(name string) AdvisoryLock {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("lily/" + name))
+	return AdvisoryLock(h.Sum64())
+}
+
+// A Lock is an exclusive lock that lily processes using the same storage contend for.
+type Lock interface {
+	// Lock tries to acquire the lock, returning ErrLockNotAcquired if it is held by another process.
+	Lock(context.Context) error
+	// Unlock releases the lock.
+	Unlock(context.Context) error
+	// Held reports whether the lock is still held.
+	Held(context.Context) (bool, error)
+}
+
+// A SessionLock is an exclusive AdvisoryLock held by a dedicated database connection. The lock is released by
+// Postgres when the connection is closed, including when the process holding it exits.
+type SessionLock struct {
+	db   *pg.DB
+	lock AdvisoryLock
+
+	mu   sync.Mutex
+	conn *pg.Conn
+}
+
+var _ Lock = (*SessionLock)(nil)
+
+func NewSessionLock(db *pg.DB, lock AdvisoryLock) *SessionLock {
+	return &SessionLock{db: db, lock: lock}
+}
+
+func (l *SessionLock) Lock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != nil {
+		return fmt.Errorf("lock already held")
+	}
+
+	conn := l.db.Conn()
+	if err := l.lock.LockExclusive(ctx, conn); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	l.conn = conn
+	return nil
+}
+
+// Unlock releases the lock and closes the connection holding it, which releases the lock even if unlocking fails.
+func (l *SessionLock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+
+	err := l.lock.UnlockExclusive(ctx, l.conn)
+	if cerr := l.conn.Close(); cerr != nil && err == nil {
+		err = fmt.Errorf("closing lock connection: %w", cerr)
+	}
+	l.conn = nil
+	return err
+}
+
+// Held reports whether the session of the connection that took the lock still holds it.
+func (l *SessionLock) Held(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return false, nil
+	}
+
+	// bigint advisory lock keys are split into the classid and objid columns, with an objsubid of 1.
+	var held bool
+	_, err := l.conn.QueryOneContext(ctx, pg.Scan(&held), `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid() AND objsubid = 1
+			AND classid::bigint = (?::bigint >> 32) & 4294967295 AND objid::bigint = ?::bigint & 4294967295
+	);`, int64(l.lock), int64(l.lock))
+	if err != nil {
+		return false, fmt.Errorf("checking lock: %w", err)
+	}
+	return held, nil
+}
@@ -50,7 +50,7 @@ func (j *MemStorage) PersistModel(ctx context.Context, m interface{}) error {
 		q := orm.NewQuery(nil, m)
 		tm := q.TableModel()
 		n := tm.Table()
-		name := stripQuotes(n.SQLNameForSelects)
+		name := StripQuotes(n.SQLNameForSelects)
 		j.DataMu.Lock()
 		j.Data[name] = append(j.Data[name], m)
 		j.DataMu.Unlock()
@@ -0,0 +1,458 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+const (
+	FilePatternTokenFromHeight = "{from}"
+	FilePatternTokenToHeight   = "{to}"
+
+	DefaultParquetFilePattern = FilePatternTokenTable + "-" + FilePatternTokenFromHeight + "-" + FilePatternTokenToHeight + ".parquet"
+
+	// DefaultParquetHeightRange is the number of epochs written to a single parquet file before it is rolled over,
+	// this is one day of epochs.
+	DefaultParquetHeightRange = 2880
+)
+
+var (
+	// Cache of parquet schemas derived from model tables
+	parquetSchemasMu sync.Mutex
+	parquetSchemas   = map[tableWithVersion]*parquetSchema{}
+)
+
+// A parquetSchema is the parquet representation of a model table along with the index of each model column in the
+// parquet schema.
+type parquetSchema struct {
+	schema  *parquet.Schema
+	indexes []int // parquet column index of each column in the table, in table column order
+	height  int   // index in the table of the height column, -1 if the table has no height column
+}
+
+func getParquetSchema(t table, version model.Version) (*parquetSchema, error) {
+	parquetSchemasMu.Lock()
+	defer parquetSchemasMu.Unlock()
+
+	nv := tableWithVersion{
+		name:    t.name,
+		version: version,
+	}
+
+	if ps, ok := parquetSchemas[nv]; ok {
+		return ps, nil
+	}
+
+	group := parquet.Group{}
+	for i, col := range t.columns {
+		group[col] = parquet.Optional(parquetNodeForSQLType(t.types[i]))
+	}
+
+	ps := &parquetSchema{
+		schema:  parquet.NewSchema(t.name, group),
+		indexes: make([]int, len(t.columns)),
+		height:  -1,
+	}
+	for i, col := range t.columns {
+		leaf, ok := ps.schema.Lookup(col)
+		if !ok {
+			return nil, fmt.Errorf("column %s missing from parquet schema of table %s", col, t.name)
+		}
+		ps.indexes[i] = leaf.ColumnIndex
+		if col == "height" {
+			ps.height = i
+		}
+	}
+	parquetSchemas[nv] = ps
+
+	return ps, nil
+}
+
+// parquetNodeForSQLType returns the parquet column type used to hold values of the supplied postgres type. Types
+// without a natural parquet equivalent, such as numeric and enums, are held as strings.
+func parquetNodeForSQLType(sqlType string) parquet.Node {
+	switch sqlType {
+	case "bigint", "integer", "smallint":
+		return parquet.Int(64)
+	case "boolean":
+		return parquet.Leaf(parquet.BooleanType)
+	case "double precision", "real":
+		return parquet.Leaf(parquet.DoubleType)
+	case "timestamptz", "timestamp":
+		return parquet.Timestamp(parquet.Microsecond)
+	case "bytea":
+		return parquet.Leaf(parquet.ByteArrayType)
+	case "json", "jsonb":
+		return parquet.JSON()
+	default:
+		return parquet.String()
+	}
+}
+
+type ParquetStorage struct {
+	path     string
+	version  model.Version // schema version
+	opts     ParquetStorageOptions
+	metadata Metadata
+	files    *parquetFileSet // shared by all copies of the storage made by WithMetadata
+}
+
+var _ StorageWithMetadata = (*ParquetStorage)(nil)
+
+type ParquetStorageOptions struct {
+	FilePattern string
+	HeightRange int64 // number of epochs written to each file before rolling over to a new one
+}
+
+func DefaultParquetStorageOptions() ParquetStorageOptions {
+	return ParquetStorageOptions{
+		FilePattern: DefaultParquetFilePattern,
+		HeightRange: DefaultParquetHeightRange,
+	}
+}
+
+func NewParquetStorage(path string, version model.Version, opts ParquetStorageOptions) (*ParquetStorage, error) {
+	// Ensure we always have a file pattern
+	if opts.FilePattern == "" {
+		opts.FilePattern = DefaultParquetFilePattern
+	}
+	// Ensure files for different height ranges are given different names
+	if !strings.Contains(opts.FilePattern, FilePatternTokenFromHeight) {
+		ext := filepath.Ext(opts.FilePattern)
+		opts.FilePattern = strings.TrimSuffix(opts.FilePattern, ext) + "-" + FilePatternTokenFromHeight + "-" + FilePatternTokenToHeight + ext
+	}
+	if opts.HeightRange <= 0 {
+		opts.HeightRange = DefaultParquetHeightRange
+	}
+
+	return &ParquetStorage{
+		path:    path,
+		version: version,
+		opts:    opts,
+		files: &parquetFileSet{
+			files: map[string]*parquetFile{},
+		},
+	}, nil
+}
+
+func NewParquetStorageLatest(path string, opts ParquetStorageOptions) (*ParquetStorage, error) {
+	return NewParquetStorage(path, LatestSchemaVersion(), opts)
+}
+
+func (p *ParquetStorage) WithMetadata(md Metadata) model.Storage {
+	p2 := *p
+	p2.metadata = md
+	return &p2
+}
+
+// PersistBatch persists a batch of models to parquet files. Rows are written to the file covering the height range
+// they belong to, files are finalized when rows for a different height range are written to the same table or when
+// the storage is closed. A parquet file cannot be read until it has been finalized.
+func (p *ParquetStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	batch := &ParquetBatch{
+		data:    map[string][]parquetRow{},
+		version: p.version,
+	}
+
+	for _, m := range ps {
+		if err := m.Persist(ctx, batch, p.version); err != nil {
+			return err
+		}
+	}
+
+	for name, rows := range batch.data {
+		if len(rows) == 0 {
+			continue
+		}
+		t, ok := getCSVModelTableByName(name, p.version)
+		if !ok {
+			log.Errorf("unknown table name: %s", name)
+			continue
+		}
+
+		pschema, err := getParquetSchema(t, p.version)
+		if err != nil {
+			return err
+		}
+
+		// group rows by the height range they belong to
+		ranges := map[int64][]parquet.Row{}
+		for _, r := range rows {
+			var lo int64
+			if pschema.height >= 0 && !r[pschema.height].IsNull() {
+				height := r[pschema.height].Int64()
+				lo = height - height%p.opts.HeightRange
+			}
+			ranges[lo] = append(ranges[lo], r.levelled(pschema))
+		}
+
+		los := make([]int64, 0, len(ranges))
+		for lo := range ranges {
+			los = append(los, lo)
+		}
+		sort.Slice(los, func(i, j int) bool { return los[i] < los[j] })
+
+		r := strings.NewReplacer(
+			FilePatternTokenTable, name,
+			FilePatternTokenJobName, p.metadata.JobName,
+		)
+		localname := r.Replace(p.opts.FilePattern)
+
+		for _, lo := range los {
+			if err := p.files.write(localname, p.path, lo, lo+p.opts.HeightRange-1, pschema.schema, ranges[lo]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close finalizes all parquet files that are currently being written.
+func (p *ParquetStorage) Close(_ context.Context) error {
+	return p.files.closeAll()
+}
+
+// ModelHeaders returns the column headers used for parquet output of the type of model held in v
+func (p *ParquetStorage) ModelHeaders(v interface{}) ([]string, error) {
+	t := getCSVModelTable(v, p.version)
+
+	return t.columns, nil
+}
+
+// A parquetFileSet holds the parquet files that are being written, keyed by their file pattern with the height range
+// tokens unexpanded. Only one file per key is open at a time.
+type parquetFileSet struct {
+	mu    sync.Mutex
+	files map[string]*parquetFile
+}
+
+type parquetFile struct {
+	f      *os.File
+	w      *parquet.Writer
+	lo     int64 // lowest height of the range covered by the file
+	schema *parquet.Schema
+}
+
+func (s *parquetFileSet) write(localname, dir string, lo, hi int64, schema *parquet.Schema, rows []parquet.Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pf, ok := s.files[localname]
+	if ok && (pf.lo != lo || pf.schema != schema) {
+		// rolling over to a new height range
+		delete(s.files, localname)
+		if err := pf.close(); err != nil {
+			return err
+		}
+		ok = false
+	}
+
+	if !ok {
+		r := strings.NewReplacer(
+			FilePatternTokenFromHeight, strconv.FormatInt(lo, 10),
+			FilePatternTokenToHeight, strconv.FormatInt(hi, 10),
+		)
+		f, err := createParquetFile(filepath.Join(dir, r.Replace(localname)))
+		if err != nil {
+			return err
+		}
+		pf = &parquetFile{
+			f:      f,
+			w:      parquet.NewWriter(f, schema, parquet.Compression(&parquet.Snappy)),
+			lo:     lo,
+			schema: schema,
+		}
+		s.files[localname] = pf
+	}
+
+	if _, err := pf.w.WriteRows(rows); err != nil {
+		return fmt.Errorf("write parquet rows to %q: %w", pf.f.Name(), err)
+	}
+	return nil
+}
+
+func (s *parquetFileSet) closeAll() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for name, pf := range s.files {
+		delete(s.files, name)
+		if err := pf.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (pf *parquetFile) close() error {
+	if err := pf.w.Close(); err != nil {
+		_ = pf.f.Close() // nolint: errcheck
+		return fmt.Errorf("finalize parquet file %q: %w", pf.f.Name(), err)
+	}
+	if err := pf.f.Sync(); err != nil {
+		log.Errorw("failed to sync parquet file", "error", err, "filename", pf.f.Name())
+	}
+	return pf.f.Close()
+}
+
+// createParquetFile creates a new file with the supplied name. Parquet files cannot be appended to, so if the file
+// already exists, because the range it covers has been revisited, a numbered part file is created alongside it.
+func createParquetFile(filename string) (*os.File, error) {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	for part := 0; ; part++ {
+		name := filename
+		if part > 0 {
+			name = fmt.Sprintf("%s.part%d%s", base, part, ext)
+		}
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			return f, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("create file %q: %w", name, err)
+		}
+	}
+}
+
+// A parquetRow holds the values of a model in table column order.
+type parquetRow []parquet.Value
+
+// levelled returns the row with its values ordered and levelled according to the parquet schema.
+func (r parquetRow) levelled(ps *parquetSchema) parquet.Row {
+	out := make(parquet.Row, len(r))
+	for i, v := range r {
+		idx := ps.indexes[i]
+		if v.IsNull() {
+			out[idx] = v.Level(0, 0, idx)
+			continue
+		}
+		out[idx] = v.Level(0, 1, idx)
+	}
+	return out
+}
+
+type ParquetBatch struct {
+	data    map[string][]parquetRow
+	version model.Version // schema version used when persisting the batch
+}
+
+func (p *ParquetBatch) PersistModel(ctx context.Context, m interface{}) error {
+	if len(Models) == 0 {
+		return nil
+	}
+
+	value := reflect.ValueOf(m)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := p.PersistModel(ctx, value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		// Get the table for this type
+		t := getCSVModelTable(m, p.version)
+
+		// Build the row
+		row := make(parquetRow, len(t.fields))
+		for i, f := range t.fields {
+			v, err := parquetValue(value.FieldByName(f), t.types[i])
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", t.name, t.columns[i], err)
+			}
+			row[i] = v
+		}
+		p.data[t.name] = append(p.data[t.name], row)
+		return nil
+	default:
+		return ErrMarshalUnsupportedType
+	}
+}
+
+// parquetValue converts a model field to a value of the parquet column type used for sqlType.
+func parquetValue(fv reflect.Value, sqlType string) (parquet.Value, error) {
+	fk := fv.Kind()
+	if (fk == reflect.Slice || fk == reflect.Map || fk == reflect.Ptr || fk == reflect.Chan || fk == reflect.Func || fk == reflect.Interface) && fv.IsNil() {
+		return parquet.NullValue(), nil
+	}
+
+	ft := fv.Type()
+
+	// Special formatting for known types
+	if ft.PkgPath() == "time" && ft.Name() == "Time" {
+		return parquet.Int64Value(fv.Interface().(time.Time).UnixMicro()), nil
+	}
+
+	switch sqlType {
+	case "bigint", "integer", "smallint":
+		switch fk {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return parquet.Int64Value(fv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return parquet.Int64Value(int64(fv.Uint())), nil
+		}
+	case "boolean":
+		if fk == reflect.Bool {
+			return parquet.BooleanValue(fv.Bool()), nil
+		}
+	case "double precision", "real":
+		if fk == reflect.Float32 || fk == reflect.Float64 {
+			return parquet.DoubleValue(fv.Float()), nil
+		}
+	case "bytea":
+		if fk == reflect.Slice && ft.Elem().Kind() == reflect.Uint8 {
+			return parquet.ByteArrayValue(fv.Bytes()), nil
+		}
+	case "json", "jsonb":
+		// Strings marked as json type are assumed to already be encoded
+		if fk == reflect.String {
+			return parquet.ByteArrayValue([]byte(fv.String())), nil
+		}
+		v, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.ByteArrayValue(v), nil
+	default:
+		// values such as big.Int are written in their string form, as the CSV writer does.
+		if fk != reflect.Interface && fv.CanInterface() {
+			if sv, ok := fv.Interface().(fmt.Stringer); ok {
+				return parquet.ByteArrayValue([]byte(sv.String())), nil
+			}
+		}
+		switch fk {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+			v, err := json.Marshal(fv.Interface())
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.ByteArrayValue(v), nil
+		default:
+			return parquet.ByteArrayValue([]byte(fmt.Sprint(fv))), nil
+		}
+	}
+
+	return parquet.Value{}, fmt.Errorf("%w: %s as %s", ErrMarshalUnsupportedType, ft, sqlType)
+}
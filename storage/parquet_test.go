@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lily/model"
+)
+
+type NumericModel struct {
+	Height int64   `pg:",pk,notnull,use_zero"`
+	Value  big.Int `pg:"type:numeric,notnull"`
+}
+
+func (nm *NumericModel) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	return s.PersistModel(ctx, nm)
+}
+
+func readParquetRows(t *testing.T, filename string) (*parquet.Schema, []parquet.Row) {
+	f, err := os.Open(filename)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	r := parquet.NewReader(f)
+	defer r.Close() // nolint: errcheck
+
+	var rows []parquet.Row
+	buf := make([]parquet.Row, 16)
+	for {
+		n, err := r.ReadRows(buf)
+		for _, row := range buf[:n] {
+			rows = append(rows, row.Clone())
+		}
+		if err != nil {
+			break
+		}
+	}
+	return r.Schema(), rows
+}
+
+func TestParquetPersist(t *testing.T) {
+	tms := []model.Persistable{
+		&TestModel{
+			Height:  42,
+			Block:   "blocka",
+			Message: "msg1",
+		},
+		&TestModel{
+			Height:  43,
+			Block:   "blockb",
+			Message: "msg2",
+		},
+	}
+
+	dir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	st, err := NewParquetStorage(dir, model.Version{Major: 1}, DefaultParquetStorageOptions())
+	require.NoError(t, err)
+
+	err = st.PersistBatch(context.Background(), tms...)
+	require.NoError(t, err)
+
+	err = st.Close(context.Background())
+	require.NoError(t, err)
+
+	schema, rows := readParquetRows(t, filepath.Join(dir, "test_models-0-2879.parquet"))
+	require.Len(t, rows, 2)
+
+	height, ok := schema.Lookup("height")
+	require.True(t, ok)
+	block, ok := schema.Lookup("block")
+	require.True(t, ok)
+
+	assert.Equal(t, parquet.Int64Type.Kind(), height.Node.Type().Kind())
+	assert.EqualValues(t, 42, rows[0][height.ColumnIndex].Int64())
+	assert.EqualValues(t, "blocka", rows[0][block.ColumnIndex].String())
+	assert.EqualValues(t, 43, rows[1][height.ColumnIndex].Int64())
+	assert.EqualValues(t, "blockb", rows[1][block.ColumnIndex].String())
+}
+
+func TestParquetPersistRollover(t *testing.T) {
+	dir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	opts := DefaultParquetStorageOptions()
+	opts.FilePattern = "{jobname}-{table}.parquet"
+	opts.HeightRange = 10
+
+	st, err := NewParquetStorage(dir, model.Version{Major: 1}, opts)
+	require.NoError(t, err)
+	strg := st.WithMetadata(Metadata{JobName: "job"})
+
+	for _, height := range []int64{8, 9, 10, 11, 25} {
+		err = strg.PersistBatch(context.Background(), &TestModel{Height: height, Block: "blocka", Message: "msg1"})
+		require.NoError(t, err)
+	}
+
+	// files for ranges that have been rolled over are readable before the storage is closed
+	_, rows := readParquetRows(t, filepath.Join(dir, "job-test_models-0-9.parquet"))
+	assert.Len(t, rows, 2)
+	_, rows = readParquetRows(t, filepath.Join(dir, "job-test_models-10-19.parquet"))
+	assert.Len(t, rows, 2)
+
+	err = st.Close(context.Background())
+	require.NoError(t, err)
+
+	_, rows = readParquetRows(t, filepath.Join(dir, "job-test_models-20-29.parquet"))
+	assert.Len(t, rows, 1)
+
+	// revisiting a range that has already been written creates a new part file
+	err = strg.PersistBatch(context.Background(), &TestModel{Height: 5, Block: "blocka", Message: "msg1"})
+	require.NoError(t, err)
+	err = st.Close(context.Background())
+	require.NoError(t, err)
+
+	_, rows = readParquetRows(t, filepath.Join(dir, "job-test_models-0-9.part1.parquet"))
+	assert.Len(t, rows, 1)
+}
+
+func TestParquetPersistTypes(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tms := []model.Persistable{
+		&TimeModel{Height: 42, Processed: now},
+		&JSONModel{Height: 42, Value: `{"foo":"bar"}`},
+		&StringSliceModel{Height: 42, Addresses: []string{"a", "b"}},
+		&StringSliceModel{Height: 43},
+		&NumericModel{Height: 42, Value: big.MustFromString("123456789012345678901234567890")},
+	}
+
+	dir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	st, err := NewParquetStorage(dir, model.Version{Major: 1}, DefaultParquetStorageOptions())
+	require.NoError(t, err)
+
+	err = st.PersistBatch(context.Background(), tms...)
+	require.NoError(t, err)
+	err = st.Close(context.Background())
+	require.NoError(t, err)
+
+	schema, rows := readParquetRows(t, filepath.Join(dir, "time_models-0-2879.parquet"))
+	require.Len(t, rows, 1)
+	processed, ok := schema.Lookup("processed")
+	require.True(t, ok)
+	assert.EqualValues(t, now.UnixMicro(), rows[0][processed.ColumnIndex].Int64())
+
+	schema, rows = readParquetRows(t, filepath.Join(dir, "json_models-0-2879.parquet"))
+	require.Len(t, rows, 1)
+	value, ok := schema.Lookup("value")
+	require.True(t, ok)
+	assert.EqualValues(t, `{"foo":"bar"}`, rows[0][value.ColumnIndex].String())
+
+	schema, rows = readParquetRows(t, filepath.Join(dir, "string_slice_models-0-2879.parquet"))
+	require.Len(t, rows, 2)
+	addresses, ok := schema.Lookup("addresses")
+	require.True(t, ok)
+	assert.EqualValues(t, `["a","b"]`, rows[0][addresses.ColumnIndex].String())
+	assert.True(t, rows[1][addresses.ColumnIndex].IsNull())
+
+	schema, rows = readParquetRows(t, filepath.Join(dir, "numeric_models-0-2879.parquet"))
+	require.Len(t, rows, 1)
+	value, ok = schema.Lookup("value")
+	require.True(t, ok)
+	assert.EqualValues(t, "123456789012345678901234567890", rows[0][value.ColumnIndex].String())
+}
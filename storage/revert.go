@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+// A Reverter is a storage that can remove data that was persisted for a tipset that has since been reverted from the chain.
+type Reverter interface {
+	// RevertTipSet removes all data persisted at height that was derived from the state identified by stateRoot.
+	RevertTipSet(ctx context.Context, height int64, stateRoot string) error
+}
+
+var _ Reverter = (*Database)(nil)
+
+// RevertTipSet deletes rows from every model table that is keyed by height and state root, and from the tables of
+// models that are reverted by height, in a single transaction. Other tables without a state root, such as message
+// tables keyed by cid, are left untouched.
+func (d *Database) RevertTipSet(ctx context.Context, height int64, stateRoot string) error {
+	byStateRoot, byHeight, err := revertableTables(Models, d.version)
+	if err != nil {
+		return err
+	}
+
+	return d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		for _, tbl := range byStateRoot {
+			res, err := tx.ExecContext(ctx, `DELETE FROM ? WHERE height = ? AND state_root = ?`, tbl.SQLName, height, stateRoot)
+			if err != nil {
+				return fmt.Errorf("reverting %s: %w", StripQuotes(tbl.SQLName), err)
+			}
+			if n := res.RowsAffected(); n > 0 {
+				log.Debugw("reverted rows", "table", StripQuotes(tbl.SQLName), "height", height, "state_root", stateRoot, "rows", n)
+			}
+		}
+		for _, tbl := range byHeight {
+			res, err := tx.ExecContext(ctx, `DELETE FROM ? WHERE height = ?`, tbl.SQLName, height)
+			if err != nil {
+				return fmt.Errorf("reverting %s: %w", StripQuotes(tbl.SQLName), err)
+			}
+			if n := res.RowsAffected(); n > 0 {
+				log.Debugw("reverted rows", "table", StripQuotes(tbl.SQLName), "height", height, "rows", n)
+			}
+		}
+		return nil
+	})
+}
+
+// revertableTables returns the tables of models, at the given schema version, that have both a height and a
+// state_root column, and those of models implementing model.HeightReverted that have a height column.
+func revertableTables(models []interface{}, version model.Version) (byStateRoot []*orm.Table, byHeight []*orm.Table, err error) {
+	type versionable interface {
+		AsVersion(model.Version) (interface{}, bool)
+	}
+
+	seen := map[string]bool{}
+	for _, m := range models {
+		if vm, ok := m.(versionable); ok {
+			vm, ok := vm.AsVersion(version)
+			if !ok {
+				return nil, nil, fmt.Errorf("model %T does not support version %s", m, version)
+			}
+			m = vm
+		}
+
+		tbl := pg.Model(m).TableModel().Table()
+		if seen[string(tbl.SQLName)] {
+			continue
+		}
+		if _, ok := tbl.FieldsMap["height"]; !ok {
+			continue
+		}
+		if _, ok := tbl.FieldsMap["state_root"]; !ok {
+			if _, ok := m.(model.HeightReverted); ok {
+				seen[string(tbl.SQLName)] = true
+				byHeight = append(byHeight, tbl)
+			}
+			continue
+		}
+		seen[string(tbl.SQLName)] = true
+		byStateRoot = append(byStateRoot, tbl)
+	}
+	return byStateRoot, byHeight, nil
+}
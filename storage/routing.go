@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+// RoutingStorage writes each model to the storage routed to by the name of its table in the database schema. Models of
+// tables without a route, such as gap reports, are written to the default storage.
+type RoutingStorage struct {
+	routes   map[string]string        // table name to storage name
+	storages map[string]model.Storage // storage name to storage
+	fallback string
+}
+
+var (
+	_ model.Storage = (*RoutingStorage)(nil)
+	_ Reverter      = (*RoutingStorage)(nil)
+	_ Checkpointer  = (*RoutingStorage)(nil)
+)
+
+// NewRoutingStorage returns a RoutingStorage that writes the results of the tasks in routes to the storage they are
+// mapped to and all others to the storage named fallback. Every name in routes and fallback must be a key of storages.
+func NewRoutingStorage(storages map[string]model.Storage, routes map[string]string, fallback string) (*RoutingStorage, error) {
+	if _, ok := storages[fallback]; !ok {
+		return nil, fmt.Errorf("unknown default storage: %q", fallback)
+	}
+	for task, name := range routes {
+		if _, ok := storages[name]; !ok {
+			return nil, fmt.Errorf("unknown storage %q for table %q", name, task)
+		}
+	}
+	return &RoutingStorage{
+		routes:   routes,
+		storages: storages,
+		fallback: fallback,
+	}, nil
+}
+
+// PersistBatch persists each model of the batch to the storage routed to by its table. Every storage is given the
+// batch so that it persists the models using its own schema version.
+func (r *RoutingStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	var errs []error
+	for name, s := range r.storages {
+		name := name
+		routed := &routedPersistable{ps: ps, keep: func(table string) bool { return r.route(table) == name }}
+		ctx, _ := tag.New(ctx, tag.Upsert(metrics.Storage, name))
+		if err := s.PersistBatch(ctx, routed); err != nil {
+			errs = append(errs, fmt.Errorf("persist to storage %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RevertTipSet reverts the tipset in every storage that supports reverting data.
+func (r *RoutingStorage) RevertTipSet(ctx context.Context, height int64, stateRoot string) error {
+	var errs []error
+	for name, s := range r.storages {
+		rv, ok := s.(Reverter)
+		if !ok {
+			continue
+		}
+		if err := rv.RevertTipSet(ctx, height, stateRoot); err != nil {
+			errs = append(errs, fmt.Errorf("revert storage %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadCheckpoint loads the checkpoint from the default storage.
+func (r *RoutingStorage) LoadCheckpoint(ctx context.Context, jobName string) (*visor.JobCheckpoint, error) {
+	cp, ok := CheckpointerOf(r.storages[r.fallback])
+	if !ok {
+		return nil, fmt.Errorf("default storage %q does not support checkpoints", r.fallback)
+	}
+	return cp.LoadCheckpoint(ctx, jobName)
+}
+
+// SaveCheckpoint saves the checkpoint to the default storage.
+func (r *RoutingStorage) SaveCheckpoint(ctx context.Context, cp *visor.JobCheckpoint) error {
+	st, ok := CheckpointerOf(r.storages[r.fallback])
+	if !ok {
+		return fmt.Errorf("default storage %q does not support checkpoints", r.fallback)
+	}
+	return st.SaveCheckpoint(ctx, cp)
+}
+
+// route returns the name of the storage the models of table are written to.
+func (r *RoutingStorage) route(table string) string {
+	if name, ok := r.routes[table]; ok {
+		return name
+	}
+	return r.fallback
+}
+
+// isModelTable reports whether name is the table of one of the models lily persists.
+func isModelTable(name string) bool {
+	for _, m := range Models {
+		if StripQuotes(pg.Model(m).TableModel().Table().SQLName) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// A routedPersistable persists the models of its persistables whose tables keep accepts.
+type routedPersistable struct {
+	ps   []model.Persistable
+	keep func(table string) bool
+}
+
+func (rp *routedPersistable) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	batch := &routedBatch{batch: s, keep: rp.keep}
+	for _, p := range rp.ps {
+		if p == nil {
+			continue
+		}
+		if err := p.Persist(ctx, batch, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A routedBatch passes the models whose tables keep accepts to batch.
+type routedBatch struct {
+	batch model.StorageBatch
+	keep  func(table string) bool
+}
+
+func (b *routedBatch) PersistModel(ctx context.Context, m interface{}) error {
+	typ := reflect.TypeOf(m)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+		if typ.Kind() == reflect.Interface {
+			// the models of the list may be of different tables.
+			value := reflect.Indirect(reflect.ValueOf(m))
+			for i := 0; i < value.Len(); i++ {
+				if err := b.PersistModel(ctx, value.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+	}
+	if typ.Kind() != reflect.Struct {
+		return ErrMarshalUnsupportedType
+	}
+
+	table := StripQuotes(orm.GetTable(typ).SQLName)
+	if !b.keep(table) {
+		return nil
+	}
+	return b.batch.PersistModel(ctx, m)
+}
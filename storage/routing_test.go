@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/blocks"
+	"github.com/filecoin-project/lily/model/visor"
+)
+
+func TestRoutingStoragePersist(t *testing.T) {
+	hot, cold := NewMemStorageLatest(), NewMemStorageLatest()
+	r, err := NewRoutingStorage(map[string]model.Storage{"hot": hot, "cold": cold}, map[string]string{"test_models": "cold"}, "hot")
+	require.NoError(t, err)
+
+	// the task tag of ctx is ignored, models are routed by their table
+	ctx, err := tag.New(context.Background(), tag.Upsert(metrics.TaskType, "chain_economics"))
+	require.NoError(t, err)
+
+	require.NoError(t, r.PersistBatch(ctx, model.PersistableList{
+		&TestModel{Height: 42, Block: "blocka", Message: "msg1"},
+		&blocks.DrandBlockEntrie{Round: 1, Block: "blocka"},
+		visor.ProcessingReportList{{Height: 42, Task: "test_models"}},
+	}))
+
+	assert.Len(t, cold.Data["test_models"], 1)
+	assert.Len(t, cold.Data["drand_block_entries"], 0)
+	assert.Len(t, cold.Data["visor_processing_reports"], 0)
+
+	assert.Len(t, hot.Data["test_models"], 0)
+	assert.Len(t, hot.Data["drand_block_entries"], 1)
+	assert.Len(t, hot.Data["visor_processing_reports"], 1)
+}
+
+func TestRoutingStorageCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	csv, err := NewCSVStorageLatest(t.TempDir(), DefaultCSVStorageOptions())
+	require.NoError(t, err)
+	r, err := NewRoutingStorage(map[string]model.Storage{"csv": csv, "mem": NewMemStorageLatest()}, map[string]string{"test_models": "mem"}, "csv")
+	require.NoError(t, err)
+
+	// checkpoints are kept in the default storage
+	cp, ok := CheckpointerOf(r)
+	require.True(t, ok)
+	require.NoError(t, cp.SaveCheckpoint(ctx, &visor.JobCheckpoint{JobName: "walk_1", JobType: "walk", Height: 20}))
+	saved, err := csv.LoadCheckpoint(ctx, "walk_1")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.EqualValues(t, 20, saved.Height)
+
+	r, err = NewRoutingStorage(map[string]model.Storage{"csv": csv, "mem": NewMemStorageLatest()}, map[string]string{"test_models": "csv"}, "mem")
+	require.NoError(t, err)
+	_, ok = CheckpointerOf(r)
+	assert.False(t, ok)
+}
+
+func TestNewRoutingStorageUnknownStorage(t *testing.T) {
+	_, err := NewRoutingStorage(map[string]model.Storage{"hot": NewMemStorageLatest()}, map[string]string{"vm_messages": "cold"}, "hot")
+	assert.Error(t, err)
+
+	_, err = NewRoutingStorage(map[string]model.Storage{"hot": NewMemStorageLatest()}, nil, "cold")
+	assert.Error(t, err)
+}
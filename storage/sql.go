@@ -24,6 +24,7 @@ import (
 	"github.com/filecoin-project/lily/model/actors/market"
 	"github.com/filecoin-project/lily/model/actors/miner"
 	"github.com/filecoin-project/lily/model/actors/multisig"
+	"github.com/filecoin-project/lily/model/actors/paych"
 	"github.com/filecoin-project/lily/model/actors/power"
 	"github.com/filecoin-project/lily/model/actors/reward"
 	"github.com/filecoin-project/lily/model/actors/verifreg"
@@ -54,6 +55,8 @@ var Models = []interface{}{
 	(*miner.MinerPreCommitInfo)(nil),
 	(*miner.MinerPreCommitInfoV9)(nil),
 	(*miner.MinerSectorEvent)(nil),
+	(*miner.MinerSectorLifecycle)(nil),
+	(*miner.MinerPenalty)(nil),
 	(*miner.MinerCurrentDeadlineInfo)(nil),
 	(*miner.MinerFeeDebt)(nil),
 	(*miner.MinerLockedFund)(nil),
@@ -67,6 +70,7 @@ var Models = []interface{}{
 	(*messages.BlockMessage)(nil),
 	(*messages.Receipt)(nil),
 	(*messages.MessageGasEconomy)(nil),
+	(*messages.MethodGasStats)(nil),
 	(*messages.ParsedMessage)(nil),
 	(*messages.InternalMessage)(nil),
 	(*messages.InternalParsedMessage)(nil),
@@ -77,6 +81,9 @@ var Models = []interface{}{
 
 	(*multisig.MultisigTransaction)(nil),
 
+	(*paych.PaychState)(nil),
+	(*paych.PaychLaneState)(nil),
+
 	(*power.ChainPower)(nil),
 	(*power.PowerActorClaim)(nil),
 
@@ -84,6 +91,7 @@ var Models = []interface{}{
 
 	(*common.Actor)(nil),
 	(*common.ActorState)(nil),
+	(*common.ActorBalanceChange)(nil),
 
 	(*init_.IDAddress)(nil),
 
@@ -105,9 +113,18 @@ var Models = []interface{}{
 	(*fevm.FEVMTransaction)(nil),
 	(*fevm.FEVMContract)(nil),
 	(*fevm.FEVMTrace)(nil),
+	(*fevm.ContractEvent)(nil),
+	(*fevm.FEVMTokenTransfer)(nil),
+	(*fevm.FEVMToken)(nil),
+	(*fevm.FEVMStorageChange)(nil),
 	(*actordumps.FEVMActorDump)(nil),
 	(*actordumps.MinerActorDump)(nil),
 	(*builtinactor.BuiltInActorEvent)(nil),
+	(*builtinactor.EventDealLifecycle)(nil),
+	(*builtinactor.EventVerifierBalance)(nil),
+	(*builtinactor.EventVerifregAllocation)(nil),
+	(*builtinactor.EventVerifregClaim)(nil),
+	(*builtinactor.EventSectorLifecycle)(nil),
 	(*miner.MinerCronFee)(nil),
 }
 
@@ -340,7 +357,7 @@ func verifyCurrentSchema(ctx context.Context, db *pg.DB, cfg schemas.Config) err
 }
 
 func verifyModel(ctx context.Context, db *pg.DB, schemaName string, m *orm.Table) error {
-	tableName := stripQuotes(m.SQLNameForSelects)
+	tableName := StripQuotes(m.SQLNameForSelects)
 
 	exists, err := tableExists(ctx, db, schemaName, tableName)
 	if err != nil {
@@ -398,7 +415,8 @@ func tableExists(ctx context.Context, db *pg.DB, schemaName string, tableName st
 	return exists, nil
 }
 
-func stripQuotes(s types.Safe) string {
+// StripQuotes returns a table or column name quoted by go-pg without its quotes.
+func StripQuotes(s types.Safe) string {
 	return strings.Trim(string(s), `"`)
 }
 
@@ -14,6 +14,7 @@ import (
 
 	"github.com/filecoin-project/lily/model"
 	"github.com/filecoin-project/lily/model/actors/miner"
+	"github.com/filecoin-project/lily/model/messages"
 	"github.com/filecoin-project/lily/schemas"
 	"github.com/filecoin-project/lily/testutil"
 )
@@ -179,6 +180,85 @@ func TestUpsertSQLGeneration(t *testing.T) {
 	assert.Equal(t, testModel.ExpectedUpsertStatement(), upsert)
 }
 
+type heightRevertedModel struct {
+	//lint:ignore U1000 tableName is a convention used by go-pg
+	tableName struct{} `pg:"height_reverted_model"` // nolint: structcheck
+	Height    int64    `pg:",pk,notnull,use_zero"`
+	Cid       string   `pg:",pk,notnull"`
+}
+
+func (*heightRevertedModel) RevertedByHeight() {}
+
+func TestRevertableTables(t *testing.T) {
+	models := append([]interface{}{(*heightRevertedModel)(nil)}, Models...)
+	byStateRoot, byHeight, err := revertableTables(models, LatestSchemaVersion())
+	require.NoError(t, err)
+
+	names := map[string]bool{}
+	for _, tbl := range byStateRoot {
+		names[StripQuotes(tbl.SQLName)] = true
+	}
+	heightNames := map[string]bool{}
+	for _, tbl := range byHeight {
+		heightNames[StripQuotes(tbl.SQLName)] = true
+	}
+
+	// tables keyed by height and state root are reverted
+	assert.True(t, names["actors"])
+	assert.True(t, names["miner_infos"])
+	// tables of models implementing model.HeightReverted are reverted by height
+	assert.True(t, heightNames["height_reverted_model"])
+	assert.True(t, heightNames["messages"])
+	assert.True(t, heightNames["miner_cron_fees"])
+	assert.True(t, heightNames["fevm_receipts"])
+	// other tables without a state root are not
+	assert.False(t, names["block_headers"] || heightNames["block_headers"])
+}
+
+func TestDatabaseRevertTipSet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	_, err = db.Exec(`TRUNCATE TABLE messages`)
+	require.NoError(t, err, "truncating messages")
+
+	d, err := NewDatabaseFromDB(ctx, db, "public")
+	require.NoError(t, err)
+
+	msg := func(height int64, cid string) *messages.Message {
+		return &messages.Message{
+			Height:     height,
+			Cid:        cid,
+			From:       "f01000",
+			To:         "f01001",
+			Value:      "1",
+			GasFeeCap:  "1",
+			GasPremium: "1",
+		}
+	}
+	require.NoError(t, d.PersistBatch(ctx, messages.Messages{
+		msg(10, "msg1"),
+		msg(11, "msg2"),
+		msg(11, "msg3"),
+	}))
+
+	require.NoError(t, d.RevertTipSet(ctx, 11, "root11"))
+
+	var cids []string
+	_, err = db.Query(&cids, `SELECT cid FROM messages ORDER BY cid`)
+	require.NoError(t, err)
+	// the messages of the reverted tipset are removed, those of other heights are kept
+	assert.Equal(t, []string{"msg1"}, cids)
+}
+
 func TestDatabasePersistWithVersion(t *testing.T) {
 	if testing.Short() {
 		t.Skip("short testing requested")
@@ -348,3 +428,77 @@ func TestDatabasePersistWithUnsupportedVersion(t *testing.T) {
 	err = d.PersistBatch(ctx, vm)
 	require.NoErrorf(t, err, "persisting versioned model: %v", err)
 }
+
+func TestMinerSectorLifecycleView(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	_, err = db.Exec(`TRUNCATE TABLE miner_sector_lifecycle`)
+	require.NoError(t, err, "truncating miner_sector_lifecycle")
+
+	d, err := NewDatabaseFromDB(ctx, db, "public")
+	require.NoError(t, err)
+
+	transition := func(height int64, sector uint64, state string, entered int64) *miner.MinerSectorLifecycle {
+		return &miner.MinerSectorLifecycle{
+			Height:       height,
+			MinerID:      "f01000",
+			SectorID:     sector,
+			StateRoot:    fmt.Sprintf("root%d", height),
+			State:        state,
+			Cause:        "cause",
+			EnteredEpoch: entered,
+		}
+	}
+
+	// transitions persisted out of order, as concurrent walks do
+	require.NoError(t, d.PersistBatch(ctx, miner.MinerSectorLifecycleList{
+		transition(30, 1, miner.SectorStateFaulty, 30),
+		transition(25, 2, miner.SectorStateFaulty, 25),
+	}))
+	require.NoError(t, d.PersistBatch(ctx, miner.MinerSectorLifecycleList{
+		transition(10, 1, miner.SectorStatePreCommitted, 10),
+		// the sector was already active when its miner was first observed
+		transition(15, 2, miner.SectorStateActive, 5),
+	}))
+	require.NoError(t, d.PersistBatch(ctx, miner.MinerSectorLifecycleList{
+		transition(20, 1, miner.SectorStateActive, 20),
+	}))
+
+	type row struct {
+		SectorID    uint64
+		State       string
+		ExitedEpoch *int64
+	}
+	var rows []row
+	_, err = db.Query(&rows, `SELECT sector_id, state, exited_epoch FROM miner_sector_lifecycle_view ORDER BY sector_id, height`)
+	require.NoError(t, err)
+
+	epoch := func(e int64) *int64 { return &e }
+	assert.Equal(t, []row{
+		{SectorID: 1, State: miner.SectorStatePreCommitted, ExitedEpoch: epoch(20)},
+		{SectorID: 1, State: miner.SectorStateActive, ExitedEpoch: epoch(30)},
+		{SectorID: 1, State: miner.SectorStateFaulty},
+		{SectorID: 2, State: miner.SectorStateActive, ExitedEpoch: epoch(25)},
+		{SectorID: 2, State: miner.SectorStateFaulty},
+	}, rows)
+
+	// a reverted transition reopens the state before it
+	_, err = db.Exec(`DELETE FROM miner_sector_lifecycle WHERE height = 30`)
+	require.NoError(t, err)
+	rows = nil
+	_, err = db.Query(&rows, `SELECT sector_id, state, exited_epoch FROM miner_sector_lifecycle_view WHERE sector_id = 1 ORDER BY height`)
+	require.NoError(t, err)
+	assert.Equal(t, []row{
+		{SectorID: 1, State: miner.SectorStatePreCommitted, ExitedEpoch: epoch(20)},
+		{SectorID: 1, State: miner.SectorStateActive},
+	}, rows)
+}
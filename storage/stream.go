@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+const (
+	StreamEncodingJSON = "JSON"
+	StreamEncodingCBOR = "CBOR"
+
+	DefaultTopicPattern = FilePatternTokenTable
+)
+
+// A StreamMessage is a single encoded model published to a topic.
+type StreamMessage struct {
+	Topic string
+	Key   []byte // height and state root of the model, when the model has them
+	Value []byte // the encoded model
+}
+
+// A StreamTransport publishes messages to a message bus such as Kafka, NATS or Redis streams.
+type StreamTransport interface {
+	// Publish publishes all messages, in order.
+	Publish(ctx context.Context, msgs ...StreamMessage) error
+	Close(ctx context.Context) error
+}
+
+type StreamStorageOptions struct {
+	Encoding     string
+	TopicPattern string
+}
+
+func DefaultStreamStorageOptions() StreamStorageOptions {
+	return StreamStorageOptions{
+		Encoding:     StreamEncodingJSON,
+		TopicPattern: DefaultTopicPattern,
+	}
+}
+
+// StreamStorage publishes each model it is asked to persist as a message on a topic per table. Messages are keyed
+// by the height and state root of the model so that consumers can group the data produced for a tipset.
+type StreamStorage struct {
+	transport StreamTransport
+	version   model.Version // schema version
+	opts      StreamStorageOptions
+	metadata  Metadata
+}
+
+var (
+	_ StorageWithMetadata = (*StreamStorage)(nil)
+	_ Closer              = (*StreamStorage)(nil)
+)
+
+func NewStreamStorage(transport StreamTransport, version model.Version, opts StreamStorageOptions) (*StreamStorage, error) {
+	if opts.TopicPattern == "" {
+		opts.TopicPattern = DefaultTopicPattern
+	}
+	switch opts.Encoding {
+	case "":
+		opts.Encoding = StreamEncodingJSON
+	case StreamEncodingJSON, StreamEncodingCBOR:
+	default:
+		return nil, fmt.Errorf("unsupported stream encoding %q", opts.Encoding)
+	}
+
+	return &StreamStorage{
+		transport: transport,
+		version:   version,
+		opts:      opts,
+	}, nil
+}
+
+func NewStreamStorageLatest(transport StreamTransport, opts StreamStorageOptions) (*StreamStorage, error) {
+	return NewStreamStorage(transport, LatestSchemaVersion(), opts)
+}
+
+func (s *StreamStorage) WithMetadata(md Metadata) model.Storage {
+	s2 := *s
+	s2.metadata = md
+	return &s2
+}
+
+// PersistBatch encodes the batch of models and publishes them to the transport.
+func (s *StreamStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	batch := &StreamBatch{
+		version:  s.version,
+		encoding: s.opts.Encoding,
+		topic: strings.NewReplacer(
+			FilePatternTokenJobName, s.metadata.JobName,
+		).Replace(s.opts.TopicPattern),
+	}
+
+	for _, p := range ps {
+		if err := p.Persist(ctx, batch, s.version); err != nil {
+			return err
+		}
+	}
+
+	if len(batch.msgs) == 0 {
+		return nil
+	}
+
+	return s.transport.Publish(ctx, batch.msgs...)
+}
+
+// Close closes the underlying transport.
+func (s *StreamStorage) Close(ctx context.Context) error {
+	return s.transport.Close(ctx)
+}
+
+type StreamBatch struct {
+	msgs     []StreamMessage
+	topic    string        // topic pattern with all tokens except the table name replaced
+	encoding string        // encoding used for message values
+	version  model.Version // schema version used when persisting the batch
+}
+
+func (b *StreamBatch) PersistModel(ctx context.Context, m interface{}) error {
+	value := reflect.ValueOf(m)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := b.PersistModel(ctx, value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		t := getCSVModelTable(m, b.version)
+
+		rec := make(map[string]interface{}, len(t.fields))
+		var key []string
+		for i, f := range t.fields {
+			fv := value.FieldByName(f)
+			fk := fv.Kind()
+			if (fk == reflect.Slice || fk == reflect.Map || fk == reflect.Ptr || fk == reflect.Chan || fk == reflect.Func || fk == reflect.Interface) && fv.IsNil() {
+				rec[t.columns[i]] = nil
+				continue
+			}
+
+			v := fv.Interface()
+			switch t.columns[i] {
+			case "height":
+				key = append([]string{strconv.FormatInt(fv.Int(), 10)}, key...)
+			case "state_root":
+				key = append(key, fv.String())
+			}
+
+			// Strings marked as json type are assumed to already be encoded, embed them as json so they are
+			// not encoded a second time.
+			if b.encoding == StreamEncodingJSON && fk == reflect.String && (t.types[i] == "json" || t.types[i] == "jsonb") && json.Valid([]byte(fv.String())) {
+				v = json.RawMessage(fv.String())
+			}
+
+			rec[t.columns[i]] = v
+		}
+
+		data, err := b.encode(rec)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", t.name, err)
+		}
+
+		b.msgs = append(b.msgs, StreamMessage{
+			Topic: strings.ReplaceAll(b.topic, FilePatternTokenTable, t.name),
+			Key:   []byte(strings.Join(key, "/")),
+			Value: data,
+		})
+		return nil
+	default:
+		return ErrMarshalUnsupportedType
+	}
+}
+
+func (b *StreamBatch) encode(rec map[string]interface{}) ([]byte, error) {
+	if b.encoding == StreamEncodingCBOR {
+		return cbor.Marshal(rec)
+	}
+	return json.Marshal(rec)
+}
+
+// MemoryStreamTransport is an in-process transport that retains all published messages. It is intended for testing.
+type MemoryStreamTransport struct {
+	mu     sync.Mutex
+	topics map[string][]StreamMessage
+}
+
+var _ StreamTransport = (*MemoryStreamTransport)(nil)
+
+func NewMemoryStreamTransport() *MemoryStreamTransport {
+	return &MemoryStreamTransport{
+		topics: map[string][]StreamMessage{},
+	}
+}
+
+func (t *MemoryStreamTransport) Publish(_ context.Context, msgs ...StreamMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, msg := range msgs {
+		t.topics[msg.Topic] = append(t.topics[msg.Topic], msg)
+	}
+	return nil
+}
+
+func (t *MemoryStreamTransport) Close(_ context.Context) error {
+	return nil
+}
+
+// Messages returns the messages published to topic in the order they were published.
+func (t *MemoryStreamTransport) Messages(topic string) []StreamMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]StreamMessage, len(t.topics[topic]))
+	copy(out, t.topics[topic])
+	return out
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStreamTransport publishes messages to redis streams, using one stream per topic. Each entry holds the
+// message key and value in the fields "key" and "value".
+type RedisStreamTransport struct {
+	client *redis.Client
+	maxLen int64
+}
+
+var _ StreamTransport = (*RedisStreamTransport)(nil)
+
+// NewRedisStreamTransport returns a transport that publishes to the redis server at url. If maxLen is greater than zero
+// each stream is trimmed to approximately that many entries.
+func NewRedisStreamTransport(url string, maxLen int64) (*RedisStreamTransport, error) {
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis URL: %w", err)
+	}
+
+	return &RedisStreamTransport{
+		client: redis.NewClient(opt),
+		maxLen: maxLen,
+	}, nil
+}
+
+func (t *RedisStreamTransport) Publish(ctx context.Context, msgs ...StreamMessage) error {
+	pipe := t.client.Pipeline()
+	for _, msg := range msgs {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: msg.Topic,
+			MaxLen: t.maxLen,
+			Approx: t.maxLen > 0,
+			Values: map[string]interface{}{
+				"key":   msg.Key,
+				"value": msg.Value,
+			},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("publish to redis: %w", err)
+	}
+	return nil
+}
+
+func (t *RedisStreamTransport) Close(_ context.Context) error {
+	return t.client.Close()
+}
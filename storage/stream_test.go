@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+func TestStreamPersist(t *testing.T) {
+	tms := []model.Persistable{
+		&TestModel{
+			Height:  42,
+			Block:   "blocka",
+			Message: "msg1",
+		},
+		&JSONModel{
+			Height: 43,
+			Value:  `{"foo":"bar"}`,
+		},
+	}
+
+	transport := NewMemoryStreamTransport()
+	opts := DefaultStreamStorageOptions()
+	opts.TopicPattern = "{jobname}.{table}"
+
+	st, err := NewStreamStorage(transport, model.Version{Major: 1}, opts)
+	require.NoError(t, err)
+	strg := st.WithMetadata(Metadata{JobName: "job"})
+
+	err = strg.PersistBatch(context.Background(), tms...)
+	require.NoError(t, err)
+
+	msgs := transport.Messages("job.test_models")
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "42", string(msgs[0].Key))
+	assert.JSONEq(t, `{"height":42,"block":"blocka","message":"msg1"}`, string(msgs[0].Value))
+
+	msgs = transport.Messages("job.json_models")
+	require.Len(t, msgs, 1)
+	assert.JSONEq(t, `{"height":43,"value":{"foo":"bar"}}`, string(msgs[0].Value))
+}
+
+func TestStreamPersistCBOR(t *testing.T) {
+	transport := NewMemoryStreamTransport()
+	opts := DefaultStreamStorageOptions()
+	opts.Encoding = StreamEncodingCBOR
+
+	st, err := NewStreamStorage(transport, model.Version{Major: 1}, opts)
+	require.NoError(t, err)
+
+	err = st.PersistBatch(context.Background(), &StringSliceModel{Height: 42, Addresses: []string{"a", "b"}})
+	require.NoError(t, err)
+
+	msgs := transport.Messages("string_slice_models")
+	require.Len(t, msgs, 1)
+
+	var rec struct {
+		Height    int64    `cbor:"height"`
+		Addresses []string `cbor:"addresses"`
+	}
+	require.NoError(t, cbor.Unmarshal(msgs[0].Value, &rec))
+	assert.EqualValues(t, 42, rec.Height)
+	assert.Equal(t, []string{"a", "b"}, rec.Addresses)
+}
+
+func TestStreamKey(t *testing.T) {
+	type KeyedModel struct {
+		tableName struct{} `pg:"keyed_models"` // nolint: structcheck
+		StateRoot string   `pg:",pk,notnull"`
+		Height    int64    `pg:",pk,use_zero"`
+	}
+
+	b := &StreamBatch{topic: DefaultTopicPattern, encoding: StreamEncodingJSON}
+	require.NoError(t, b.PersistModel(context.Background(), &KeyedModel{Height: 10, StateRoot: "bafy"}))
+	require.Len(t, b.msgs, 1)
+	assert.Equal(t, "10/bafy", string(b.msgs[0].Key))
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(b.msgs[0].Value, &rec))
+	assert.EqualValues(t, "bafy", rec["state_root"])
+}
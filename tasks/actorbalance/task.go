@@ -0,0 +1,274 @@
+package actorbalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	lilybuiltin "github.com/filecoin-project/lily/chain/actors/builtin"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actors/common"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/actorbalance")
+
+// Flows through which the balance of an actor changes, recorded in the causes of a balance change.
+const (
+	// FlowMessage is value transferred by a message or by a call made during its execution.
+	FlowMessage = "message"
+	// FlowGas is the gas paid by the sender of a message, and its miner tip and burnt gas fees received by the reward
+	// and burnt funds actors. These do not appear in the execution trace of the message.
+	FlowGas = "gas"
+	// FlowReward is value paid by the reward actor, such as block rewards and the gas rewards of a block.
+	FlowReward = "reward"
+	// FlowBurn is value other than gas fees sent to the burnt funds actor, such as penalties.
+	FlowBurn = "burn"
+	// FlowVesting is value withdrawn from the balance of a miner actor, which includes its vested funds.
+	FlowVesting = "vesting"
+)
+
+// Causes are the messages and flows that may have changed the balance of an address.
+type Causes struct {
+	// Messages are the CIDs of the messages, in execution order.
+	Messages []cid.Cid
+	// Flows are the flows the messages changed the balance through, in lexical order.
+	Flows []string
+}
+
+func (c *Causes) add(msg cid.Cid, flow string) {
+	if n := len(c.Messages); n == 0 || c.Messages[n-1] != msg {
+		c.Messages = append(c.Messages, msg)
+	}
+	c.addFlows(flow)
+}
+
+func (c *Causes) addFlows(flows ...string) {
+	for _, f := range flows {
+		i := sort.SearchStrings(c.Flows, f)
+		if i < len(c.Flows) && c.Flows[i] == f {
+			continue
+		}
+		c.Flows = append(c.Flows, "")
+		copy(c.Flows[i+1:], c.Flows[i:])
+		c.Flows[i] = f
+	}
+}
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+// ProcessTipSets emits a row for each actor whose balance changed between the parent state of executed and the parent
+// state of current. Each row lists the messages executed in executed that moved funds to or from the actor and the flows
+// they moved them through.
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "actor_balance_changes"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	changes, err := t.node.ActorStateChanges(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting actor state changes: %w", err)
+		return nil, report, nil
+	}
+
+	mex, err := t.node.MessageExecutions(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting messages executions for tipset: %w", err)
+		return nil, report, nil
+	}
+	causes := MessageCauses(mex)
+
+	if err := t.node.SetIdRobustAddressMap(ctx, current.Key()); err != nil {
+		log.Warnw("failed to load robust addresses, messages sent from robust addresses may be missing", "height", current.Height(), "error", err)
+	}
+
+	out := make(common.ActorBalanceChangeList, 0, len(changes))
+	var errs []error
+	for addr, change := range changes {
+		oldBalance, newBalance, err := t.balances(ctx, addr, change, executed)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("actor %s: %w", addr, err))
+			continue
+		}
+		if oldBalance.Equals(newBalance) {
+			continue
+		}
+
+		cause := Causes{}
+		if c, ok := causes[addr]; ok {
+			cause = *c
+		}
+		if robust, err := t.node.LookupRobustAddress(ctx, addr, current.Key()); err == nil && robust != addr {
+			if c, ok := causes[robust]; ok {
+				cause.Messages = tasks.MergeCids(cause.Messages, c.Messages)
+				cause.Flows = append([]string(nil), cause.Flows...)
+				cause.addFlows(c.Flows...)
+			}
+		}
+		var msgCids []string
+		for _, c := range cause.Messages {
+			msgCids = append(msgCids, c.String())
+		}
+
+		out = append(out, &common.ActorBalanceChange{
+			Height:       int64(current.Height()),
+			Address:      addr.String(),
+			StateRoot:    current.ParentState().String(),
+			OldBalance:   oldBalance.String(),
+			NewBalance:   newBalance.String(),
+			BalanceDelta: big.Sub(newBalance, oldBalance).String(),
+			MessageCids:  msgCids,
+			Causes:       cause.Flows,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+	return out, report, nil
+}
+
+// balances returns the balance of an actor before and after a change.
+func (t *Task) balances(ctx context.Context, addr address.Address, change tasks.ActorStateChange, executed *types.TipSet) (abi.TokenAmount, abi.TokenAmount, error) {
+	switch change.ChangeType {
+	case tasks.ChangeTypeAdd:
+		return big.Zero(), change.Actor.Balance, nil
+	case tasks.ChangeTypeRemove:
+		return change.Actor.Balance, big.Zero(), nil
+	}
+
+	// the actor as it was in the parent state of executed, which is the state the change was made to.
+	prev, err := t.node.Actor(ctx, addr, executed.Key())
+	if err != nil {
+		if change.ChangeType == tasks.ChangeTypeUnknown && errors.Is(err, types.ErrActorNotFound) {
+			// the slow state diff does not tell us if the actor was added, it was if it did not exist before.
+			return big.Zero(), change.Actor.Balance, nil
+		}
+		return abi.TokenAmount{}, abi.TokenAmount{}, fmt.Errorf("loading previous actor state: %w", err)
+	}
+	return prev.Balance, change.Actor.Balance, nil
+}
+
+// MessageCauses returns the messages that may have changed the balance of each address, in execution order, and the
+// flows they changed it through. A message is attributed to the addresses that sent or received value anywhere in its
+// execution trace, including block rewards, penalties sent to the burnt funds actor and withdrawals from miner actors.
+// A non-implicit message is also attributed to its sender, which pays for its gas, and to the reward and burnt funds
+// actors when they receive its miner tip and burnt gas fees. Addresses are recorded as they appear in the trace, so an
+// actor may be referred to by both its ID and robust address.
+func MessageCauses(mex []*lens.MessageExecution) map[address.Address]*Causes {
+	out := map[address.Address]*Causes{}
+	for _, m := range mex {
+		add := func(addr address.Address, flow string) {
+			if addr == address.Undef {
+				return
+			}
+			c, ok := out[addr]
+			if !ok {
+				c = &Causes{}
+				out[addr] = c
+			}
+			c.add(m.Cid, flow)
+		}
+
+		if !m.Implicit && m.Message != nil {
+			add(m.Message.From, FlowGas)
+			if m.Ret != nil && m.Ret.GasCosts != nil {
+				if positive(m.Ret.GasCosts.MinerTip) {
+					add(builtin.RewardActorAddr, FlowGas)
+				}
+				if positive(big.Add(m.Ret.GasCosts.BaseFeeBurn, m.Ret.GasCosts.OverEstimationBurn)) {
+					add(builtin.BurntFundsActorAddr, FlowGas)
+				}
+			}
+		}
+		if m.Ret == nil {
+			// without a trace fall back to the message itself.
+			if m.Message != nil && positive(m.Message.Value) {
+				add(m.Message.From, FlowMessage)
+				add(m.Message.To, FlowMessage)
+			}
+			continue
+		}
+		walkTrace(m.Ret.ExecutionTrace, address.Undef, false, add)
+	}
+	return out
+}
+
+// walkTrace calls add with the sender and recipient of every call in the trace that transferred value, and the flow of
+// the transfer. caller is the ID address of the actor that made the call, if known, and withdrawal is true when the
+// caller is a miner actor withdrawing from its balance.
+func walkTrace(trace types.ExecutionTrace, caller address.Address, withdrawal bool, add func(address.Address, string)) {
+	var invoked address.Address
+	if trace.InvokedActor != nil {
+		var err error
+		invoked, err = address.NewIDAddress(uint64(trace.InvokedActor.Id))
+		if err != nil {
+			invoked = address.Undef
+		}
+	}
+
+	if positive(trace.Msg.Value) {
+		var flow string
+		switch {
+		case invoked == builtin.BurntFundsActorAddr || trace.Msg.To == builtin.BurntFundsActorAddr:
+			flow = FlowBurn
+		case caller == builtin.RewardActorAddr || trace.Msg.From == builtin.RewardActorAddr:
+			flow = FlowReward
+		case withdrawal:
+			flow = FlowVesting
+		default:
+			flow = FlowMessage
+		}
+		add(trace.Msg.From, flow)
+		add(trace.Msg.To, flow)
+		add(caller, flow)
+		add(invoked, flow)
+	}
+
+	withdrawing := (trace.Msg.Method == builtin.MethodsMiner.WithdrawBalance || trace.Msg.Method == builtin.MethodsMiner.WithdrawBalanceExported) &&
+		trace.InvokedActor != nil &&
+		lilybuiltin.ActorFamily(lilybuiltin.ActorNameByCode(trace.InvokedActor.State.Code)) == "storageminer"
+	for _, sub := range trace.Subcalls {
+		walkTrace(sub, invoked, withdrawing, add)
+	}
+}
+
+func positive(v abi.TokenAmount) bool {
+	return !v.Nil() && v.GreaterThan(big.Zero())
+}
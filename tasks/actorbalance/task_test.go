@@ -0,0 +1,212 @@
+package actorbalance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/tasks"
+	"github.com/filecoin-project/lily/testutil"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+func mustAddr(t *testing.T, s string) address.Address {
+	addr, err := address.NewFromString(s)
+	require.NoError(t, err)
+	return addr
+}
+
+func mustCid(t *testing.T, s string) cid.Cid {
+	c, err := cid.Decode(s)
+	require.NoError(t, err)
+	return c
+}
+
+func TestMessageCauses(t *testing.T) {
+	sender := mustAddr(t, "f1ys5qqiciehcml3sp764ymbbytfn3qoar5fo3iwy")
+	senderID := mustAddr(t, "f01000")
+	recipient := mustAddr(t, "f01001")
+	burnt := mustAddr(t, "f099")
+	reward := mustAddr(t, "f02")
+	miner := mustAddr(t, "f01002")
+	owner := mustAddr(t, "f01003")
+
+	transfer := mustCid(t, "bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+	noValue := mustCid(t, "bafy2bzacebq7g5yokwvvnmx4hh2jmijxaxjbx6rlhkuzjvjssdxkqzgkuyb6u")
+	award := mustCid(t, "bafy2bzacecxfmolh2ojedyqtbnpnkoy7fp3rtrrszv4myk3usngwcrroqu2ki")
+	withdraw := testutil.RandomCid()
+
+	mex := []*lens.MessageExecution{
+		{
+			// a transfer that also pays a penalty to the burnt funds actor
+			Cid:     transfer,
+			Message: &types.Message{From: sender, To: recipient, Value: abi.NewTokenAmount(10)},
+			Ret: &vm.ApplyRet{
+				ExecutionTrace: types.ExecutionTrace{
+					Msg:          types.MessageTrace{From: sender, To: recipient, Value: abi.NewTokenAmount(10)},
+					InvokedActor: &types.ActorTrace{Id: 1001},
+					Subcalls: []types.ExecutionTrace{
+						{
+							Msg:          types.MessageTrace{From: recipient, To: burnt, Value: abi.NewTokenAmount(1)},
+							InvokedActor: &types.ActorTrace{Id: 99},
+						},
+					},
+				},
+				GasCosts: &vm.GasOutputs{
+					BaseFeeBurn:        abi.NewTokenAmount(3),
+					OverEstimationBurn: abi.NewTokenAmount(0),
+					MinerTip:           abi.NewTokenAmount(2),
+				},
+			},
+		},
+		{
+			// a message without value only changes the balance of its sender, who pays for gas
+			Cid:     noValue,
+			Message: &types.Message{From: senderID, To: recipient, Value: abi.NewTokenAmount(0)},
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Msg:          types.MessageTrace{From: senderID, To: recipient, Value: abi.NewTokenAmount(0)},
+				InvokedActor: &types.ActorTrace{Id: 1001},
+			}},
+		},
+		{
+			// an implicit block reward message
+			Cid:      award,
+			Implicit: true,
+			Message:  &types.Message{From: mustAddr(t, "f00"), To: reward, Value: abi.NewTokenAmount(0)},
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Msg:          types.MessageTrace{From: mustAddr(t, "f00"), To: reward, Value: abi.NewTokenAmount(0)},
+				InvokedActor: &types.ActorTrace{Id: 2},
+				Subcalls: []types.ExecutionTrace{
+					{
+						Msg:          types.MessageTrace{From: reward, To: miner, Value: abi.NewTokenAmount(5)},
+						InvokedActor: &types.ActorTrace{Id: 1002},
+					},
+				},
+			}},
+		},
+	}
+
+	mex = append(mex, &lens.MessageExecution{
+		// the owner of a miner withdrawing its balance
+		Cid:     withdraw,
+		Message: &types.Message{From: owner, To: miner, Method: builtin.MethodsMiner.WithdrawBalance},
+		Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+			Msg:          types.MessageTrace{From: owner, To: miner, Method: builtin.MethodsMiner.WithdrawBalance, Value: abi.NewTokenAmount(0)},
+			InvokedActor: &types.ActorTrace{Id: 1002, State: types.Actor{Code: builtin2.StorageMinerActorCodeID}},
+			Subcalls: []types.ExecutionTrace{
+				{
+					Msg:          types.MessageTrace{From: miner, To: owner, Value: abi.NewTokenAmount(7)},
+					InvokedActor: &types.ActorTrace{Id: 1003},
+				},
+			},
+		}},
+	})
+
+	causes := MessageCauses(mex)
+	require.Equal(t, &Causes{Messages: []cid.Cid{transfer}, Flows: []string{FlowGas, FlowMessage}}, causes[sender])
+	require.Equal(t, &Causes{Messages: []cid.Cid{noValue}, Flows: []string{FlowGas}}, causes[senderID])
+	require.Equal(t, &Causes{Messages: []cid.Cid{transfer}, Flows: []string{FlowBurn, FlowMessage}}, causes[recipient])
+	// the burnt funds actor receives the burnt gas fees and the penalty of the transfer
+	require.Equal(t, &Causes{Messages: []cid.Cid{transfer}, Flows: []string{FlowBurn, FlowGas}}, causes[burnt])
+	// the reward actor receives the miner tip of the transfer and pays the block reward
+	require.Equal(t, &Causes{Messages: []cid.Cid{transfer, award}, Flows: []string{FlowGas, FlowReward}}, causes[reward])
+	require.Equal(t, &Causes{Messages: []cid.Cid{award, withdraw}, Flows: []string{FlowReward, FlowVesting}}, causes[miner])
+	require.Equal(t, &Causes{Messages: []cid.Cid{withdraw}, Flows: []string{FlowGas, FlowVesting}}, causes[owner])
+	require.NotContains(t, causes, mustAddr(t, "f00"))
+}
+
+func TestMessageCausesWithdrawExported(t *testing.T) {
+	miner := mustAddr(t, "f01002")
+	owner := mustAddr(t, "f01003")
+	withdraw := testutil.RandomCid()
+
+	// the owner of a miner withdrawing its balance through the exported method
+	causes := MessageCauses([]*lens.MessageExecution{{
+		Cid:     withdraw,
+		Message: &types.Message{From: owner, To: miner, Method: builtin.MethodsMiner.WithdrawBalanceExported},
+		Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+			Msg:          types.MessageTrace{From: owner, To: miner, Method: builtin.MethodsMiner.WithdrawBalanceExported, Value: abi.NewTokenAmount(0)},
+			InvokedActor: &types.ActorTrace{Id: 1002, State: types.Actor{Code: builtin2.StorageMinerActorCodeID}},
+			Subcalls: []types.ExecutionTrace{
+				{
+					Msg:          types.MessageTrace{From: miner, To: owner, Value: abi.NewTokenAmount(7)},
+					InvokedActor: &types.ActorTrace{Id: 1003},
+				},
+			},
+		}},
+	}})
+	require.Equal(t, &Causes{Messages: []cid.Cid{withdraw}, Flows: []string{FlowVesting}}, causes[miner])
+	require.Equal(t, &Causes{Messages: []cid.Cid{withdraw}, Flows: []string{FlowGas, FlowVesting}}, causes[owner])
+}
+
+// actorSource serves the actors of a state from memory, failing with err if set.
+type actorSource struct {
+	tasks.DataSource
+	actors map[address.Address]*types.Actor
+	err    error
+}
+
+func (a *actorSource) Actor(_ context.Context, addr address.Address, _ types.TipSetKey) (*types.Actor, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	act, ok := a.actors[addr]
+	if !ok {
+		return nil, types.ErrActorNotFound
+	}
+	return act, nil
+}
+
+func TestBalances(t *testing.T) {
+	existing := mustAddr(t, "f01000")
+	created := mustAddr(t, "f01001")
+	executed := testutil.MustFakeTipSet(t, 10)
+	src := &actorSource{actors: map[address.Address]*types.Actor{existing: {Balance: abi.NewTokenAmount(5)}}}
+
+	testCases := []struct {
+		name    string
+		src     *actorSource
+		addr    address.Address
+		change  tasks.ChangeType
+		old     abi.TokenAmount
+		wantErr bool
+	}{
+		{name: "add", src: src, addr: created, change: tasks.ChangeTypeAdd, old: big.Zero()},
+		{name: "modify", src: src, addr: existing, change: tasks.ChangeTypeModify, old: abi.NewTokenAmount(5)},
+		{name: "modify missing actor", src: src, addr: created, change: tasks.ChangeTypeModify, wantErr: true},
+		{name: "unknown existing actor", src: src, addr: existing, change: tasks.ChangeTypeUnknown, old: abi.NewTokenAmount(5)},
+		{name: "unknown created actor", src: src, addr: created, change: tasks.ChangeTypeUnknown, old: big.Zero()},
+		{name: "unknown with error", src: &actorSource{err: errors.New("boom")}, addr: existing, change: tasks.ChangeTypeUnknown, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask(tc.src)
+			change := tasks.ActorStateChange{Actor: types.Actor{Balance: abi.NewTokenAmount(8)}, ChangeType: tc.change}
+			oldBalance, newBalance, err := task.balances(context.Background(), tc.addr, change, executed)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.old, oldBalance)
+			require.Equal(t, abi.NewTokenAmount(8), newBalance)
+		})
+	}
+
+	removed := tasks.ActorStateChange{Actor: types.Actor{Balance: abi.NewTokenAmount(3)}, ChangeType: tasks.ChangeTypeRemove}
+	oldBalance, newBalance, err := NewTask(src).balances(context.Background(), existing, removed, executed)
+	require.NoError(t, err)
+	require.Equal(t, abi.NewTokenAmount(3), oldBalance)
+	require.Equal(t, big.Zero(), newBalance)
+}
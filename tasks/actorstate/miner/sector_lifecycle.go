@@ -0,0 +1,343 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/lily/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lily/model"
+	minermodel "github.com/filecoin-project/lily/model/actors/miner"
+	"github.com/filecoin-project/lily/tasks/actorstate"
+	"github.com/filecoin-project/lily/tasks/actorstate/miner/extraction"
+)
+
+// SectorLifecycleExtractor extracts the lifecycle state transitions of a miner's sectors.
+type SectorLifecycleExtractor struct{}
+
+// SectorLifecycleChanges contains the changes to a miner's sectors from which their lifecycle transitions are derived.
+type SectorLifecycleChanges struct {
+	// PreCommitsAdded are the sectors precommitted this epoch.
+	PreCommitsAdded []minertypes.SectorPreCommitOnChainInfo
+	// PreCommitsRemoved are the sectors whose precommit was removed this epoch, either because they were proven or
+	// because the precommit expired.
+	PreCommitsRemoved []abi.SectorNumber
+	// SectorsAdded are the sectors proven this epoch.
+	SectorsAdded []miner.SectorOnChainInfo
+	// Previous are the sector states of the miner before this epoch, nil if the miner has no parent state in which
+	// case every sector is reported as entering its current state.
+	Previous *SectorStates
+	// Current are the sector states of the miner after this epoch.
+	Current *SectorStates
+	// SectorAddedIDs are the sectors reported by sector-activated actor events this epoch.
+	SectorAddedIDs map[uint64]bool
+}
+
+func (SectorLifecycleExtractor) Extract(ctx context.Context, a actorstate.ActorInfo, node actorstate.ActorStateAPI) (model.Persistable, error) {
+	log.Debugw("extract", zap.String("extractor", "SectorLifecycleExtractor"), zap.Inline(a))
+	ctx, span := otel.Tracer("").Start(ctx, "SectorLifecycleExtractor.Extract")
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(a.Attributes()...)
+	}
+
+	extState, err := extraction.LoadMinerStates(ctx, a, node)
+	if err != nil {
+		return nil, fmt.Errorf("creating miner state extraction context: %w", err)
+	}
+
+	changes := &SectorLifecycleChanges{}
+	if extState.ParentState() == nil {
+		// If the miner doesn't have previous state report all of its current sectors and precommits
+		sectors, err := extState.CurrentState().LoadSectors(nil)
+		if err != nil {
+			return nil, fmt.Errorf("loading miner sectors: %w", err)
+		}
+		for _, sector := range sectors {
+			changes.SectorsAdded = append(changes.SectorsAdded, *sector)
+		}
+
+		if err := extState.CurrentState().ForEachPrecommittedSector(func(info minertypes.SectorPreCommitOnChainInfo) error {
+			changes.PreCommitsAdded = append(changes.PreCommitsAdded, info)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		if changes.Current, err = LoadSectorState(ctx, extState.CurrentState()); err != nil {
+			return nil, fmt.Errorf("loading current sector states %w", err)
+		}
+	} else {
+		grp, grpCtx := errgroup.WithContext(ctx)
+		grp.Go(func() error {
+			// collect changes made to miner precommit map (HAMT)
+			if extState.CurrentState().ActorVersion() > actorstypes.Version8 {
+				preCommitChanges, err := node.DiffPreCommits(grpCtx, a.Address, a.Current, a.Executed, extState.ParentState(), extState.CurrentState())
+				if err != nil {
+					return fmt.Errorf("diffing precommits %w", err)
+				}
+				changes.PreCommitsAdded = preCommitChanges.Added
+				for _, rm := range preCommitChanges.Removed {
+					changes.PreCommitsRemoved = append(changes.PreCommitsRemoved, rm.Info.SectorNumber)
+				}
+				return nil
+			}
+
+			preCommitChanges, err := node.DiffPreCommitsV8(grpCtx, a.Address, a.Current, a.Executed, extState.ParentState(), extState.CurrentState())
+			if err != nil {
+				return fmt.Errorf("diffing precommits: %w", err)
+			}
+			for _, add := range preCommitChanges.Added {
+				changes.PreCommitsAdded = append(changes.PreCommitsAdded, minertypes.SectorPreCommitOnChainInfo{
+					Info:             minertypes.SectorPreCommitInfo{SectorNumber: add.Info.SectorNumber},
+					PreCommitDeposit: add.PreCommitDeposit,
+					PreCommitEpoch:   add.PreCommitEpoch,
+				})
+			}
+			for _, rm := range preCommitChanges.Removed {
+				changes.PreCommitsRemoved = append(changes.PreCommitsRemoved, rm.Info.SectorNumber)
+			}
+			return nil
+		})
+		grp.Go(func() error {
+			// collect changes made to miner sector array (AMT)
+			sectorChanges, err := node.DiffSectors(grpCtx, a.Address, a.Current, a.Executed, extState.ParentState(), extState.CurrentState())
+			if err != nil {
+				return fmt.Errorf("diffing sectors %w", err)
+			}
+			changes.SectorsAdded = sectorChanges.Added
+			return nil
+		})
+		grp.Go(func() error {
+			var err error
+			if changes.Previous, err = LoadSectorState(grpCtx, extState.ParentState()); err != nil {
+				return fmt.Errorf("loading previous sector states %w", err)
+			}
+			return nil
+		})
+		grp.Go(func() error {
+			var err error
+			if changes.Current, err = LoadSectorState(grpCtx, extState.CurrentState()); err != nil {
+				return fmt.Errorf("loading current sector states %w", err)
+			}
+			return nil
+		})
+		if err := grp.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes.SectorAddedIDs, err = node.GetSectorAddedFromEvent(ctx, extState.ParentTipSet().Key())
+	if err != nil {
+		// sectors are classified by their deals when their activation events are unavailable.
+		log.Warnw("failed to get sector activation events", "miner", a.Address, "error", err)
+	}
+
+	return ExtractSectorLifecycle(extState, changes)
+}
+
+func (SectorLifecycleExtractor) Transform(_ context.Context, data model.PersistableList) (model.PersistableList, error) {
+	persistableList := make(minermodel.MinerSectorLifecycleList, 0, len(data))
+	for _, d := range data {
+		ml, ok := d.(minermodel.MinerSectorLifecycleList)
+		if !ok {
+			return nil, fmt.Errorf("expected MinerSectorLifecycleList type but got: %T", d)
+		}
+		persistableList = append(persistableList, ml...)
+	}
+	return model.PersistableList{persistableList}, nil
+}
+
+// ExtractSectorLifecycle transforms the sector changes of a miner to a MinerSectorLifecycleList containing a
+// transition for every sector that entered a new lifecycle state.
+func ExtractSectorLifecycle(extState extraction.State, changes *SectorLifecycleChanges) (minermodel.MinerSectorLifecycleList, error) {
+	height := int64(extState.CurrentTipSet().Height())
+	out := minermodel.MinerSectorLifecycleList{}
+	transition := func(sector uint64, state, previous, cause string, entered int64) {
+		out = append(out, &minermodel.MinerSectorLifecycle{
+			Height:        height,
+			MinerID:       extState.Address().String(),
+			SectorID:      sector,
+			StateRoot:     extState.CurrentTipSet().ParentState().String(),
+			State:         state,
+			PreviousState: previous,
+			Cause:         cause,
+			EnteredEpoch:  entered,
+		})
+	}
+
+	for _, pc := range changes.PreCommitsAdded {
+		entered := height
+		if changes.Previous == nil {
+			entered = int64(pc.PreCommitEpoch)
+		}
+		transition(uint64(pc.Info.SectorNumber), minermodel.SectorStatePreCommitted, "", minermodel.PreCommitAdded, entered)
+	}
+
+	proven := map[abi.SectorNumber]bool{}
+	for _, sector := range changes.SectorsAdded {
+		proven[sector.SectorNumber] = true
+	}
+	for _, num := range changes.PreCommitsRemoved {
+		if !proven[num] {
+			transition(uint64(num), minermodel.SectorStatePreCommitExpired, minermodel.SectorStatePreCommitted, minermodel.PreCommitExpired, height)
+		}
+	}
+
+	if changes.Previous == nil {
+		// the miner is observed for the first time, report the current state of each of its sectors.
+		for _, sector := range changes.SectorsAdded {
+			num := uint64(sector.SectorNumber)
+			state, cause, err := currentSectorState(changes.Current, num)
+			if err != nil {
+				return nil, err
+			}
+			if state == "" {
+				continue
+			}
+			entered := height
+			if state == minermodel.SectorStateActive {
+				entered = int64(sector.Activation)
+				cause = sectorAddedCause(sector, changes.SectorAddedIDs)
+			}
+			transition(num, state, "", cause, entered)
+		}
+		return out, nil
+	}
+
+	preCommitted := map[abi.SectorNumber]bool{}
+	for _, num := range changes.PreCommitsRemoved {
+		preCommitted[num] = true
+	}
+	for _, sector := range changes.SectorsAdded {
+		previous := ""
+		if preCommitted[sector.SectorNumber] {
+			previous = minermodel.SectorStatePreCommitted
+		}
+		transition(uint64(sector.SectorNumber), minermodel.SectorStateActive, previous, sectorAddedCause(sector, changes.SectorAddedIDs), height)
+	}
+
+	prev, cur := changes.Previous, changes.Current
+
+	// previous live sectors that are no longer live were terminated or expired.
+	removed, err := bitfield.SubtractBitField(prev.Live, cur.Live)
+	if err != nil {
+		return nil, fmt.Errorf("comparing previous live sectors to current live sectors %w", err)
+	}
+	if err := removed.ForEach(func(u uint64) error {
+		previous, err := previousSectorState(prev, u)
+		if err != nil {
+			return err
+		}
+		cause := minermodel.SectorTerminated
+		if expiration, err := extState.ParentState().GetSectorExpiration(abi.SectorNumber(u)); err == nil {
+			if expiration.OnTime != 0 && expiration.OnTime <= extState.CurrentTipSet().Height() {
+				cause = minermodel.SectorExpired
+			}
+		}
+		transition(u, minermodel.SectorStateTerminated, previous, cause, height)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// sectors that became faulty were active.
+	faulted, err := bitfield.SubtractBitField(cur.Faulty, prev.Faulty)
+	if err != nil {
+		return nil, fmt.Errorf("comparing current faulty sectors to previous faulty sectors %w", err)
+	}
+	if err := faulted.ForEach(func(u uint64) error {
+		transition(u, minermodel.SectorStateFaulty, minermodel.SectorStateActive, minermodel.SectorFaulted, height)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// faulty sectors declared recovering.
+	recovering, err := bitfield.SubtractBitField(cur.Recovering, prev.Recovering)
+	if err != nil {
+		return nil, fmt.Errorf("comparing current recovering sectors to previous recovering sectors %w", err)
+	}
+	if err := recovering.ForEach(func(u uint64) error {
+		transition(u, minermodel.SectorStateRecovering, minermodel.SectorStateFaulty, minermodel.SectorRecovering, height)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// recovering sectors that failed to recover and remain faulty.
+	stillFaulty, err := bitfield.IntersectBitField(prev.Recovering, cur.Faulty)
+	if err != nil {
+		return nil, fmt.Errorf("comparing previous recovering sectors to current faulty sectors %w", err)
+	}
+	failed, err := bitfield.SubtractBitField(stillFaulty, cur.Recovering)
+	if err != nil {
+		return nil, fmt.Errorf("comparing failed recoveries to current recovering sectors %w", err)
+	}
+	if err := failed.ForEach(func(u uint64) error {
+		transition(u, minermodel.SectorStateFaulty, minermodel.SectorStateRecovering, minermodel.SectorFaulted, height)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// previous faulty sectors that are active again recovered.
+	recovered, err := bitfield.IntersectBitField(prev.Faulty, cur.Active)
+	if err != nil {
+		return nil, fmt.Errorf("comparing previous faulty sectors to current active sectors %w", err)
+	}
+	if err := recovered.ForEach(func(u uint64) error {
+		previous, err := previousSectorState(prev, u)
+		if err != nil {
+			return err
+		}
+		transition(u, minermodel.SectorStateActive, previous, minermodel.SectorRecovered, height)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// sectorAddedCause returns the event recorded in miner_sector_events for the addition of sector.
+func sectorAddedCause(sector miner.SectorOnChainInfo, sectorAddedIDs map[uint64]bool) string {
+	if sectorAddedIDs[uint64(sector.SectorNumber)] || len(sector.DeprecatedDealIDs) > 0 {
+		return minermodel.SectorAdded
+	}
+	return minermodel.CommitCapacityAdded
+}
+
+// previousSectorState returns the lifecycle state of a live sector in states.
+func previousSectorState(states *SectorStates, sector uint64) (string, error) {
+	state, _, err := currentSectorState(states, sector)
+	return state, err
+}
+
+// currentSectorState returns the lifecycle state of sector in states and the event that leads to it, or an empty
+// state if the sector is not live.
+func currentSectorState(states *SectorStates, sector uint64) (string, string, error) {
+	if recovering, err := states.Recovering.IsSet(sector); err != nil {
+		return "", "", err
+	} else if recovering {
+		return minermodel.SectorStateRecovering, minermodel.SectorRecovering, nil
+	}
+	if faulty, err := states.Faulty.IsSet(sector); err != nil {
+		return "", "", err
+	} else if faulty {
+		return minermodel.SectorStateFaulty, minermodel.SectorFaulted, nil
+	}
+	if live, err := states.Live.IsSet(sector); err != nil {
+		return "", "", err
+	} else if live {
+		return minermodel.SectorStateActive, minermodel.SectorAdded, nil
+	}
+	return "", "", nil
+}
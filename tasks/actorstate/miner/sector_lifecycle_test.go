@@ -0,0 +1,114 @@
+package miner_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/lily/chain/actors/builtin/miner"
+	minerstatemocks "github.com/filecoin-project/lily/chain/actors/builtin/miner/mocks"
+	minermodel "github.com/filecoin-project/lily/model/actors/miner"
+	minerex "github.com/filecoin-project/lily/tasks/actorstate/miner"
+	"github.com/filecoin-project/lily/tasks/actorstate/miner/extraction/mocks"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+type lifecycleTransition struct {
+	state    string
+	previous string
+	cause    string
+	entered  int64
+}
+
+func lifecycleBySector(result minermodel.MinerSectorLifecycleList) map[uint64]lifecycleTransition {
+	out := map[uint64]lifecycleTransition{}
+	for _, res := range result {
+		out[res.SectorID] = lifecycleTransition{state: res.State, previous: res.PreviousState, cause: res.Cause, entered: res.EnteredEpoch}
+	}
+	return out
+}
+
+func TestExtractSectorLifecycle(t *testing.T) {
+	minerContext := new(mocks.MockMinerState)
+	parentMinerState := new(minerstatemocks.State)
+	ts := testutil.MustFakeTipSet(t, 10)
+	addr := testutil.MustMakeAddress(t, 100)
+	minerContext.On("ParentState").Return(parentMinerState)
+	minerContext.On("CurrentTipSet").Return(ts)
+	minerContext.On("Address").Return(addr)
+	parentMinerState.On("GetSectorExpiration", abi.SectorNumber(5)).Return(&miner.SectorExpiration{OnTime: 0, Early: 0}, nil)
+
+	changes := &minerex.SectorLifecycleChanges{
+		PreCommitsAdded:   []minertypes.SectorPreCommitOnChainInfo{generateFakeSectorPreCommitOnChainInfo(10)},
+		PreCommitsRemoved: []abi.SectorNumber{11, 12},
+		SectorsAdded:      []miner.SectorOnChainInfo{generateFakeSectorOnChainInfo(11, 1)},
+		Previous: &minerex.SectorStates{
+			Live:       bitfield.NewFromSet([]uint64{1, 2, 3, 4, 5}),
+			Active:     bitfield.NewFromSet([]uint64{1, 4, 5}),
+			Faulty:     bitfield.NewFromSet([]uint64{2, 3}),
+			Recovering: bitfield.NewFromSet([]uint64{3}),
+		},
+		Current: &minerex.SectorStates{
+			Live:       bitfield.NewFromSet([]uint64{1, 2, 3, 4}),
+			Active:     bitfield.NewFromSet([]uint64{1, 3}),
+			Faulty:     bitfield.NewFromSet([]uint64{2, 4}),
+			Recovering: bitfield.NewFromSet([]uint64{2}),
+		},
+	}
+
+	result, err := minerex.ExtractSectorLifecycle(minerContext, changes)
+	require.NoError(t, err)
+	for _, res := range result {
+		require.Equal(t, int64(ts.Height()), res.Height)
+		require.Equal(t, addr.String(), res.MinerID)
+		require.Equal(t, ts.ParentState().String(), res.StateRoot)
+	}
+
+	height := int64(ts.Height())
+	require.Equal(t, map[uint64]lifecycleTransition{
+		2:  {state: minermodel.SectorStateRecovering, previous: minermodel.SectorStateFaulty, cause: minermodel.SectorRecovering, entered: height},
+		3:  {state: minermodel.SectorStateActive, previous: minermodel.SectorStateRecovering, cause: minermodel.SectorRecovered, entered: height},
+		4:  {state: minermodel.SectorStateFaulty, previous: minermodel.SectorStateActive, cause: minermodel.SectorFaulted, entered: height},
+		5:  {state: minermodel.SectorStateTerminated, previous: minermodel.SectorStateActive, cause: minermodel.SectorTerminated, entered: height},
+		10: {state: minermodel.SectorStatePreCommitted, previous: "", cause: minermodel.PreCommitAdded, entered: height},
+		11: {state: minermodel.SectorStateActive, previous: minermodel.SectorStatePreCommitted, cause: minermodel.SectorAdded, entered: height},
+		12: {state: minermodel.SectorStatePreCommitExpired, previous: minermodel.SectorStatePreCommitted, cause: minermodel.PreCommitExpired, entered: height},
+	}, lifecycleBySector(result))
+	require.Len(t, result, 7)
+}
+
+func TestExtractSectorLifecycleWithoutParentState(t *testing.T) {
+	minerContext := new(mocks.MockMinerState)
+	ts := testutil.MustFakeTipSet(t, 10)
+	addr := testutil.MustMakeAddress(t, 100)
+	minerContext.On("CurrentTipSet").Return(ts)
+	minerContext.On("Address").Return(addr)
+
+	active := generateFakeSectorOnChainInfo(1)
+	active.Activation = 5
+	precommit := generateFakeSectorPreCommitOnChainInfo(3)
+	precommit.PreCommitEpoch = 7
+
+	changes := &minerex.SectorLifecycleChanges{
+		PreCommitsAdded: []minertypes.SectorPreCommitOnChainInfo{precommit},
+		SectorsAdded:    []miner.SectorOnChainInfo{active, generateFakeSectorOnChainInfo(2)},
+		Current: &minerex.SectorStates{
+			Live:       bitfield.NewFromSet([]uint64{1, 2}),
+			Active:     bitfield.NewFromSet([]uint64{1}),
+			Faulty:     bitfield.NewFromSet([]uint64{2}),
+			Recovering: bitfield.New(),
+		},
+	}
+
+	result, err := minerex.ExtractSectorLifecycle(minerContext, changes)
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]lifecycleTransition{
+		1: {state: minermodel.SectorStateActive, cause: minermodel.CommitCapacityAdded, entered: 5},
+		2: {state: minermodel.SectorStateFaulty, cause: minermodel.SectorFaulted, entered: int64(ts.Height())},
+		3: {state: minermodel.SectorStatePreCommitted, cause: minermodel.PreCommitAdded, entered: 7},
+	}, lifecycleBySector(result))
+	require.Len(t, result, 3)
+}
@@ -0,0 +1,89 @@
+package paych
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/paych"
+	"github.com/filecoin-project/lily/model"
+	paychmodel "github.com/filecoin-project/lily/model/actors/paych"
+	"github.com/filecoin-project/lily/tasks/actorstate"
+)
+
+var _ actorstate.ActorStateExtractor = (*PaychLaneStateExtractor)(nil)
+
+// PaychLaneStateExtractor extracts the lanes of payment channel actors that were added or changed since the previous
+// state. All lanes are extracted when the channel has no previous state.
+type PaychLaneStateExtractor struct{}
+
+func (PaychLaneStateExtractor) Extract(ctx context.Context, a actorstate.ActorInfo, node actorstate.ActorStateAPI) (model.Persistable, error) {
+	log.Debugw("extract", zap.String("extractor", "PaychLaneStateExtractor"), zap.Inline(a))
+	ctx, span := otel.Tracer("").Start(ctx, "PaychLaneStateExtractor.Extract")
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(a.Attributes()...)
+	}
+
+	ec, err := NewPaychExtractionContext(ctx, a, node)
+	if err != nil {
+		return nil, err
+	}
+
+	prevLanes := map[uint64]laneValue{}
+	if ec.HasPreviousState() {
+		prevLanes, err = loadLanes(ec.PrevState)
+		if err != nil {
+			return nil, fmt.Errorf("loading previous payment channel lanes: %w", err)
+		}
+	}
+
+	currLanes, err := loadLanes(ec.CurrState)
+	if err != nil {
+		return nil, fmt.Errorf("loading current payment channel lanes: %w", err)
+	}
+
+	var out paychmodel.PaychLaneStateList
+	for idx, lane := range currLanes {
+		if prev, found := prevLanes[idx]; found && prev.nonce == lane.nonce && prev.redeemed.Equals(lane.redeemed) {
+			continue
+		}
+		out = append(out, &paychmodel.PaychLaneState{
+			Height:    int64(ec.CurrTs.Height()),
+			StateRoot: ec.CurrTs.ParentState().String(),
+			Address:   a.Address.String(),
+			Lane:      idx,
+			Redeemed:  lane.redeemed.String(),
+			Nonce:     lane.nonce,
+		})
+	}
+	return out, nil
+}
+
+type laneValue struct {
+	redeemed big.Int
+	nonce    uint64
+}
+
+func loadLanes(st paych.State) (map[uint64]laneValue, error) {
+	out := map[uint64]laneValue{}
+	if err := st.ForEachLaneState(func(idx uint64, ls paych.LaneState) error {
+		redeemed, err := ls.Redeemed()
+		if err != nil {
+			return err
+		}
+		nonce, err := ls.Nonce()
+		if err != nil {
+			return err
+		}
+		out[idx] = laneValue{redeemed: redeemed, nonce: nonce}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
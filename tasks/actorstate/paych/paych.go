@@ -0,0 +1,120 @@
+package paych
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/filecoin-project/lily/chain/actors/adt"
+	"github.com/filecoin-project/lily/chain/actors/builtin/paych"
+	"github.com/filecoin-project/lily/model"
+	paychmodel "github.com/filecoin-project/lily/model/actors/paych"
+	"github.com/filecoin-project/lily/tasks/actorstate"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/paych")
+
+var _ actorstate.ActorStateExtractor = (*PaychStateExtractor)(nil)
+
+// PaychStateExtractor extracts the state of payment channel actors.
+type PaychStateExtractor struct{}
+
+func (PaychStateExtractor) Extract(ctx context.Context, a actorstate.ActorInfo, node actorstate.ActorStateAPI) (model.Persistable, error) {
+	log.Debugw("extract", zap.String("extractor", "PaychStateExtractor"), zap.Inline(a))
+	ctx, span := otel.Tracer("").Start(ctx, "PaychStateExtractor.Extract")
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(a.Attributes()...)
+	}
+
+	ec, err := NewPaychExtractionContext(ctx, a, node)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := ec.CurrState.From()
+	if err != nil {
+		return nil, fmt.Errorf("loading payment channel from address: %w", err)
+	}
+	to, err := ec.CurrState.To()
+	if err != nil {
+		return nil, fmt.Errorf("loading payment channel to address: %w", err)
+	}
+	toSend, err := ec.CurrState.ToSend()
+	if err != nil {
+		return nil, fmt.Errorf("loading payment channel to send amount: %w", err)
+	}
+	settlingAt, err := ec.CurrState.SettlingAt()
+	if err != nil {
+		return nil, fmt.Errorf("loading payment channel settling epoch: %w", err)
+	}
+	laneCount, err := ec.CurrState.LaneCount()
+	if err != nil {
+		return nil, fmt.Errorf("loading payment channel lane count: %w", err)
+	}
+
+	return &paychmodel.PaychState{
+		Height:     int64(ec.CurrTs.Height()),
+		StateRoot:  ec.CurrTs.ParentState().String(),
+		Address:    a.Address.String(),
+		From:       from.String(),
+		To:         to.String(),
+		ToSend:     toSend.String(),
+		SettlingAt: int64(settlingAt),
+		LaneCount:  laneCount,
+	}, nil
+}
+
+type PaychExtractionContext struct {
+	PrevState paych.State
+
+	CurrState paych.State
+	CurrTs    *types.TipSet
+
+	Store                adt.Store
+	PreviousStatePresent bool
+}
+
+func (p *PaychExtractionContext) HasPreviousState() bool {
+	return p.PreviousStatePresent
+}
+
+func NewPaychExtractionContext(ctx context.Context, a actorstate.ActorInfo, node actorstate.ActorStateAPI) (*PaychExtractionContext, error) {
+	curState, err := paych.Load(node.Store(), &a.Actor)
+	if err != nil {
+		return nil, fmt.Errorf("loading current payment channel state at head %s: %w", a.Actor.Head, err)
+	}
+
+	prevActor, err := node.Actor(ctx, a.Address, a.Executed.Key())
+	if err != nil {
+		// actor doesn't exist yet, may have just been created.
+		if err == types.ErrActorNotFound {
+			return &PaychExtractionContext{
+				CurrState:            curState,
+				CurrTs:               a.Current,
+				Store:                node.Store(),
+				PrevState:            nil,
+				PreviousStatePresent: false,
+			}, nil
+		}
+		return nil, fmt.Errorf("loading previous payment channel %s from parent tipset %s current epoch %d: %w", a.Address, a.Executed.Key(), a.Current.Height(), err)
+	}
+
+	prevState, err := paych.Load(node.Store(), prevActor)
+	if err != nil {
+		return nil, fmt.Errorf("loading previous payment channel actor state: %w", err)
+	}
+
+	return &PaychExtractionContext{
+		PrevState:            prevState,
+		CurrState:            curState,
+		CurrTs:               a.Current,
+		Store:                node.Store(),
+		PreviousStatePresent: true,
+	}, nil
+}
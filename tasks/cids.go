@@ -0,0 +1,25 @@
+package tasks
+
+import (
+	"github.com/ipfs/go-cid"
+)
+
+// MergeCids returns the cids of a followed by those of b that are not in a, in order and without duplicates from b.
+func MergeCids(a, b []cid.Cid) []cid.Cid {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[cid.Cid]struct{}, len(a))
+	out := make([]cid.Cid, 0, len(a)+len(b))
+	for _, c := range a {
+		seen[c] = struct{}{}
+		out = append(out, c)
+	}
+	for _, c := range b {
+		if _, ok := seen[c]; !ok {
+			out = append(out, c)
+			seen[c] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,273 @@
+package contractevent
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// abiEvent is an event of a contract ABI.
+type abiEvent struct {
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	Anonymous bool          `json:"anonymous"`
+	Inputs    []abiArgument `json:"inputs"`
+}
+
+type abiArgument struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed"`
+}
+
+// parseABI returns the events of the JSON contract ABI abi.
+func parseABI(abi string) ([]abiEvent, error) {
+	var entries []abiEvent
+	if err := json.Unmarshal([]byte(abi), &entries); err != nil {
+		return nil, fmt.Errorf("parse abi: %w", err)
+	}
+	var events []abiEvent
+	for _, e := range entries {
+		if e.Type == "event" {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// Signature returns the canonical signature of the event, for example Transfer(address,address,uint256).
+func (e abiEvent) Signature() string {
+	types := make([]string, len(e.Inputs))
+	for i, in := range e.Inputs {
+		types[i] = canonicalType(in.Type)
+	}
+	return e.Name + "(" + strings.Join(types, ",") + ")"
+}
+
+// Topic returns the first topic of the logs of the event, the keccak256 hash of its signature.
+func (e abiEvent) Topic() ethtypes.EthHash {
+	return ethtypes.EthHashFromTxBytes([]byte(e.Signature()))
+}
+
+// canonicalType returns the type used in event signatures for typ, whose uint and int aliases are expanded.
+func canonicalType(typ string) string {
+	base, suffix := typ, ""
+	if i := strings.Index(typ, "["); i >= 0 {
+		base, suffix = typ[:i], typ[i:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	}
+	return base + suffix
+}
+
+// Decode returns the arguments of the event by name, decoded from the topics and data of a log. Arguments without a
+// name are named by their position, arg0, arg1 and so on. Indexed arguments of a dynamic or array type are stored in
+// the topics as the keccak256 hash of their value and are returned as that hash. Fixed-size arrays of a single word type,
+// such as uint256[3], are decoded from the data; arrays of other types are not supported.
+func (e abiEvent) Decode(topics []ethtypes.EthHash, data []byte) (map[string]interface{}, error) {
+	if !e.Anonymous {
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("missing event signature topic")
+		}
+		topics = topics[1:]
+	}
+
+	args := make(map[string]interface{}, len(e.Inputs))
+	head := 0
+	for i, in := range e.Inputs {
+		name := in.Name
+		if name == "" {
+			name = "arg" + strconv.Itoa(i)
+		}
+		typ := canonicalType(in.Type)
+
+		if in.Indexed {
+			if len(topics) == 0 {
+				return nil, fmt.Errorf("missing topic for indexed argument %s", name)
+			}
+			topic := topics[0]
+			topics = topics[1:]
+			if isDynamic(typ) || strings.Contains(typ, "[") {
+				args[name] = topic.String()
+				continue
+			}
+			v, err := decodeWord(typ, topic[:])
+			if err != nil {
+				return nil, fmt.Errorf("decode argument %s: %w", name, err)
+			}
+			args[name] = v
+			continue
+		}
+
+		if elem, n, ok := fixedArray(typ); ok {
+			v, err := decodeFixedArray(elem, n, data, head)
+			if err != nil {
+				return nil, fmt.Errorf("decode argument %s: %w", name, err)
+			}
+			args[name] = v
+			head += 32 * n
+			continue
+		}
+
+		word, err := dataWord(data, head)
+		if err != nil {
+			return nil, fmt.Errorf("decode argument %s: %w", name, err)
+		}
+		head += 32
+		var v interface{}
+		if isDynamic(typ) {
+			v, err = decodeDynamic(typ, data, word)
+		} else {
+			v, err = decodeWord(typ, word)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode argument %s: %w", name, err)
+		}
+		args[name] = v
+	}
+	return args, nil
+}
+
+func isDynamic(typ string) bool {
+	return typ == "string" || typ == "bytes" || strings.HasSuffix(typ, "[]")
+}
+
+// fixedArray returns the element type and length of the fixed-size array type typ, such as uint256[3].
+func fixedArray(typ string) (string, int, bool) {
+	i := strings.LastIndex(typ, "[")
+	if i < 0 || !strings.HasSuffix(typ, "]") {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(typ[i+1 : len(typ)-1])
+	if err != nil {
+		return "", 0, false
+	}
+	return typ[:i], n, true
+}
+
+// decodeFixedArray decodes the n elements of type elem held in place in the head of data at offset.
+func decodeFixedArray(elem string, n int, data []byte, offset int) (interface{}, error) {
+	// elements of a dynamic or array type are referenced by offsets or span several words.
+	if n < 1 || isDynamic(elem) || strings.Contains(elem, "[") {
+		return nil, fmt.Errorf("unsupported type %s[%d]", elem, n)
+	}
+	// the length is read from the ABI, check it is held in full before allocating for it.
+	if n > (len(data)-offset)/32 {
+		return nil, fmt.Errorf("data too short: %d words at %d of %d bytes", n, offset, len(data))
+	}
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		word, err := dataWord(data, offset+32*i)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeWord(elem, word)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// dataWord returns the 32 byte word at offset in data.
+func dataWord(data []byte, offset int) ([]byte, error) {
+	if offset < 0 || offset+32 > len(data) {
+		return nil, fmt.Errorf("data too short: word at %d of %d bytes", offset, len(data))
+	}
+	return data[offset : offset+32], nil
+}
+
+// wordInt returns the word as a non-negative int, used for offsets and lengths.
+func wordInt(word []byte) (int, error) {
+	v := new(big.Int).SetBytes(word)
+	if !v.IsInt64() || v.Int64() > int64(^uint32(0)) {
+		return 0, fmt.Errorf("offset or length out of range: %s", v)
+	}
+	return int(v.Int64()), nil
+}
+
+// decodeDynamic decodes the value of type typ referenced by the head word of an argument in data.
+func decodeDynamic(typ string, data []byte, head []byte) (interface{}, error) {
+	offset, err := wordInt(head)
+	if err != nil {
+		return nil, err
+	}
+	lw, err := dataWord(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	length, err := wordInt(lw)
+	if err != nil {
+		return nil, err
+	}
+	start := offset + 32
+
+	switch {
+	case typ == "string" || typ == "bytes":
+		if start+length > len(data) {
+			return nil, fmt.Errorf("data too short: %d bytes at %d of %d bytes", length, start, len(data))
+		}
+		if typ == "string" {
+			return string(data[start : start+length]), nil
+		}
+		return "0x" + hex.EncodeToString(data[start:start+length]), nil
+	default:
+		elem := strings.TrimSuffix(typ, "[]")
+		if isDynamic(elem) {
+			return nil, fmt.Errorf("unsupported type %s", typ)
+		}
+		// the length is read from the data, check it is held in full before allocating for it.
+		if length > (len(data)-start)/32 {
+			return nil, fmt.Errorf("data too short: %d words at %d of %d bytes", length, start, len(data))
+		}
+		out := make([]interface{}, 0, length)
+		for i := 0; i < length; i++ {
+			word, err := dataWord(data, start+32*i)
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeWord(elem, word)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+}
+
+// decodeWord decodes a value of the static type typ held in a single 32 byte word. Integers are returned as decimal
+// strings since they may not fit in a JSON number.
+func decodeWord(typ string, word []byte) (interface{}, error) {
+	switch {
+	case typ == "address":
+		return "0x" + hex.EncodeToString(word[12:]), nil
+	case typ == "bool":
+		return word[31] != 0, nil
+	case strings.HasPrefix(typ, "uint"):
+		return new(big.Int).SetBytes(word).String(), nil
+	case strings.HasPrefix(typ, "int"):
+		v := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			// two's complement
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return v.String(), nil
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || n < 1 || n > 32 {
+			return nil, fmt.Errorf("unsupported type %s", typ)
+		}
+		return "0x" + hex.EncodeToString(word[:n]), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
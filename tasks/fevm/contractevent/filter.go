@@ -0,0 +1,151 @@
+package contractevent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// A Filter selects the contract events written to the contract_events table, see config.ContractEventFilterConf.
+type Filter struct {
+	Name string
+
+	// emitters holds the emitters of the filter, ids those given as ID addresses. Events are emitted by the robust
+	// address of a contract, if it has one, so ids are resolved for each tipset.
+	emitters map[address.Address]bool
+	ids      []address.Address
+	topics   map[ethtypes.EthHash]bool
+	// events holds the events of the ABI of the filter by their first topic.
+	events map[ethtypes.EthHash]abiEvent
+}
+
+// NewFilters returns the filters of the contract event filter config, ordered by name.
+func NewFilters(conf map[string]config.ContractEventFilterConf) ([]*Filter, error) {
+	out := make([]*Filter, 0, len(conf))
+	for name, fc := range conf {
+		f, err := NewFilter(name, fc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// NewFilter returns the filter configured by conf.
+func NewFilter(name string, conf config.ContractEventFilterConf) (*Filter, error) {
+	f := &Filter{
+		Name:     name,
+		emitters: map[address.Address]bool{},
+		topics:   map[ethtypes.EthHash]bool{},
+		events:   map[ethtypes.EthHash]abiEvent{},
+	}
+	for _, s := range conf.Emitters {
+		addr, err := parseEmitter(s)
+		if err != nil {
+			return nil, fmt.Errorf("contract event filter %s: emitter %q: %w", name, s, err)
+		}
+		if addr.Protocol() == address.ID {
+			f.ids = append(f.ids, addr)
+		}
+		f.emitters[addr] = true
+	}
+	for _, s := range conf.Topic0 {
+		topic, err := parseTopic(s)
+		if err != nil {
+			return nil, fmt.Errorf("contract event filter %s: topic %q: %w", name, s, err)
+		}
+		f.topics[topic] = true
+	}
+	if conf.ABI != "" {
+		abi := conf.ABI
+		if !strings.HasPrefix(strings.TrimSpace(abi), "[") {
+			b, err := os.ReadFile(abi)
+			if err != nil {
+				return nil, fmt.Errorf("contract event filter %s: read abi: %w", name, err)
+			}
+			abi = string(b)
+		}
+		events, err := parseABI(abi)
+		if err != nil {
+			return nil, fmt.Errorf("contract event filter %s: %w", name, err)
+		}
+		for _, e := range events {
+			// anonymous events have no signature topic to identify them by.
+			if !e.Anonymous {
+				f.events[e.Topic()] = e
+			}
+		}
+	}
+	return f, nil
+}
+
+// parseEmitter parses a contract address given as a Filecoin or 0x address.
+func parseEmitter(s string) (address.Address, error) {
+	if strings.HasPrefix(s, "0x") {
+		ea, err := ethtypes.ParseEthAddress(s)
+		if err != nil {
+			return address.Undef, err
+		}
+		return ea.ToFilecoinAddress()
+	}
+	return address.NewFromString(s)
+}
+
+// parseTopic parses a topic given as a 0x prefixed hash or as an event signature, whose hash it returns.
+func parseTopic(s string) (ethtypes.EthHash, error) {
+	if strings.HasPrefix(s, "0x") {
+		return ethtypes.ParseEthHash(s)
+	}
+	if !strings.Contains(s, "(") || !strings.HasSuffix(s, ")") {
+		return ethtypes.EthHash{}, fmt.Errorf("not a 0x prefixed hash or an event signature")
+	}
+	return ethtypes.EthHashFromTxBytes([]byte(strings.ReplaceAll(s, " ", ""))), nil
+}
+
+// emittersAt returns the emitters of the filter at tsk, with those given as ID addresses also resolved to their
+// robust address. It returns nil if the filter matches any emitter.
+func (f *Filter) emittersAt(ctx context.Context, node tasks.DataSource, tsk types.TipSetKey) map[address.Address]bool {
+	if len(f.emitters) == 0 {
+		return nil
+	}
+	if len(f.ids) == 0 {
+		return f.emitters
+	}
+	out := make(map[address.Address]bool, len(f.emitters)+len(f.ids))
+	for addr := range f.emitters {
+		out[addr] = true
+	}
+	for _, id := range f.ids {
+		robust, err := node.LookupRobustAddress(ctx, id, tsk)
+		if err != nil {
+			// the contract may not exist yet.
+			log.Debugw("failed to look up robust address of emitter", "filter", f.Name, "emitter", id, "error", err)
+			continue
+		}
+		out[robust] = true
+	}
+	return out
+}
+
+// matches reports whether a log emitted by emitter with topics matches the filter, given the emitters of the filter
+// returned by emittersAt.
+func (f *Filter) matches(emitters map[address.Address]bool, emitter address.Address, topics []ethtypes.EthHash) bool {
+	if emitters != nil && !emitters[emitter] {
+		return false
+	}
+	if len(f.topics) == 0 {
+		return true
+	}
+	return len(topics) > 0 && f.topics[topics[0]]
+}
@@ -0,0 +1,137 @@
+package contractevent
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/fevm"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var log = logging.Logger("lily/tasks/contractevent")
+
+type Task struct {
+	node    tasks.DataSource
+	filters []*Filter
+}
+
+// NewTask returns a task writing the contract events matching filters.
+func NewTask(node tasks.DataSource, filters []*Filter) *Task {
+	return &Task{
+		node:    node,
+		filters: filters,
+	}
+}
+
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "contract_events"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	if len(t.filters) == 0 {
+		report.StatusInformation = "no contract event filters configured"
+		return fevm.ContractEventList{}, report, nil
+	}
+
+	if _, err := t.node.MessageExecutions(ctx, current, executed); err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting messages executions for tipset: %w", err)
+		return nil, report, nil
+	}
+
+	tsKey := executed.Key()
+	events, err := t.node.GetActorEventsRaw(ctx, &types.ActorEventFilter{TipSetKey: &tsKey})
+	if err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting actor events for tipset: %w", err)
+		return nil, report, nil
+	}
+
+	emitters := make([]map[address.Address]bool, len(t.filters))
+	for i, f := range t.filters {
+		emitters[i] = f.emittersAt(ctx, t.node, tsKey)
+	}
+
+	return t.contractEvents(int64(executed.Height()), events, emitters), report, nil
+}
+
+// contractEvents returns a row for each filter matched by each of the events emitted in the tipset at height, given
+// the emitters of the filters returned by emittersAt.
+func (t *Task) contractEvents(height int64, events []*types.ActorEvent, emitters []map[address.Address]bool) fevm.ContractEventList {
+	out := fevm.ContractEventList{}
+	for evtIdx, event := range events {
+		topics, data, ok := util.EthLogFromEntries(event.Entries)
+		if !ok {
+			continue
+		}
+
+		for i, f := range t.filters {
+			if !f.matches(emitters[i], event.Emitter, topics) {
+				continue
+			}
+
+			obj := &fevm.ContractEvent{
+				Height:     height,
+				MessageCid: event.MsgCid.String(),
+				EventIdx:   int64(evtIdx),
+				Filter:     f.Name,
+				Emitter:    event.Emitter.String(),
+				Data:       "0x" + hex.EncodeToString(data),
+			}
+			if ea, err := ethtypes.EthAddressFromFilecoinAddress(event.Emitter); err == nil {
+				obj.EmitterEthAddress = ea.String()
+			}
+			for idx, topic := range topics {
+				switch idx {
+				case 0:
+					obj.Topic0 = topic.String()
+				case 1:
+					obj.Topic1 = topic.String()
+				case 2:
+					obj.Topic2 = topic.String()
+				case 3:
+					obj.Topic3 = topic.String()
+				}
+			}
+
+			if len(topics) > 0 {
+				if abiEvt, found := f.events[topics[0]]; found {
+					args, err := abiEvt.Decode(topics, data)
+					if err != nil {
+						log.Debugw("failed to decode contract event", "filter", f.Name, "message", event.MsgCid, "event", abiEvt.Signature(), "error", err)
+					} else if b, err := json.Marshal(args); err == nil {
+						obj.EventName = abiEvt.Name
+						obj.DecodedArgs = string(b)
+					}
+				}
+			}
+
+			out = append(out, obj)
+		}
+	}
+	return out
+}
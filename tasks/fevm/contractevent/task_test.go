@@ -0,0 +1,252 @@
+package contractevent
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/config"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+const (
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	erc20ABI      = `[
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}]},
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+	]`
+)
+
+func word(t *testing.T, v *big.Int) []byte {
+	w := make([]byte, 32)
+	if v.Sign() < 0 {
+		v = new(big.Int).Add(v, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return v.FillBytes(w)
+}
+
+func addressTopic(t *testing.T, s string) ethtypes.EthHash {
+	ea, err := ethtypes.ParseEthAddress(s)
+	require.NoError(t, err)
+	var h ethtypes.EthHash
+	copy(h[12:], ea[:])
+	return h
+}
+
+func logEvent(t *testing.T, emitter address.Address, topics []ethtypes.EthHash, data []byte) *types.ActorEvent {
+	msg, err := cid.Decode("bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+	require.NoError(t, err)
+	event := &types.ActorEvent{Emitter: emitter, MsgCid: msg}
+	for i, topic := range topics {
+		event.Entries = append(event.Entries, types.EventEntry{Key: "t" + string(rune('1'+i)), Codec: cid.Raw, Value: topic[:]})
+	}
+	event.Entries = append(event.Entries, types.EventEntry{Key: "d", Codec: cid.Raw, Value: data})
+	return event
+}
+
+func TestContractEvents(t *testing.T) {
+	tokenEth := "0x60e1773636cf5e4a227d9ac24f20feca034ee25a"
+	token, err := parseEmitter(tokenEth)
+	require.NoError(t, err)
+	require.Equal(t, address.Delegated, token.Protocol())
+	other, err := parseEmitter("0x1111111111111111111111111111111111111111")
+	require.NoError(t, err)
+
+	filters, err := NewFilters(map[string]config.ContractEventFilterConf{
+		"token": {Emitters: []string{tokenEth}, Topic0: []string{"Transfer(address, address, uint256)"}, ABI: erc20ABI},
+		"all":   {},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "all", filters[0].Name)
+	require.Equal(t, "token", filters[1].Name)
+
+	transfer, err := ethtypes.ParseEthHash(transferTopic)
+	require.NoError(t, err)
+	from := addressTopic(t, "0x2222222222222222222222222222222222222222")
+	to := addressTopic(t, "0x3333333333333333333333333333333333333333")
+	approval := ethtypes.EthHashFromTxBytes([]byte("Approval(address,address,uint256)"))
+
+	events := []*types.ActorEvent{
+		logEvent(t, token, []ethtypes.EthHash{transfer, from, to}, word(t, big.NewInt(1000))),
+		// emitted by another contract
+		logEvent(t, other, []ethtypes.EthHash{transfer, from, to}, word(t, big.NewInt(2000))),
+		// another event of the token
+		logEvent(t, token, []ethtypes.EthHash{approval, from, to}, word(t, big.NewInt(3000))),
+		// a built-in actor event
+		{Emitter: token, Entries: []types.EventEntry{{Key: "$type", Codec: 0x51, Value: []byte{0x61, 0x61}}}},
+	}
+
+	task := &Task{filters: filters}
+	rows := task.contractEvents(10, events, []map[address.Address]bool{nil, filters[1].emitters})
+	require.Len(t, rows, 4)
+
+	// every log matches the filter without emitters or topics, which has no abi to decode them with.
+	for row, evtIdx := range map[int]int64{0: 0, 2: 1, 3: 2} {
+		require.Equal(t, "all", rows[row].Filter)
+		require.Equal(t, evtIdx, rows[row].EventIdx)
+		require.Empty(t, rows[row].DecodedArgs)
+	}
+
+	row := rows[1]
+	require.Equal(t, "token", row.Filter)
+	require.EqualValues(t, 0, row.EventIdx)
+	require.Equal(t, tokenEth, row.EmitterEthAddress)
+	require.Equal(t, transferTopic, row.Topic0)
+	require.Empty(t, row.Topic3)
+	require.Equal(t, "Transfer", row.EventName)
+	require.JSONEq(t, `{"from":"0x2222222222222222222222222222222222222222","to":"0x3333333333333333333333333333333333333333","value":"1000"}`, row.DecodedArgs)
+}
+
+func TestDecodeDynamic(t *testing.T) {
+	events, err := parseABI(`[{"type":"event","name":"Note","inputs":[
+		{"name":"topic","type":"string","indexed":true},
+		{"name":"","type":"int"},
+		{"name":"text","type":"string"},
+		{"name":"flag","type":"bool"},
+		{"name":"amounts","type":"uint64[]"},
+		{"name":"tag","type":"bytes4"}
+	]}]`)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	note := events[0]
+	require.Equal(t, "Note(string,int256,string,bool,uint64[],bytes4)", note.Signature())
+
+	text := []byte("hello")
+	var data []byte
+	data = append(data, word(t, big.NewInt(-5))...)
+	data = append(data, word(t, big.NewInt(5*32))...) // offset of text
+	data = append(data, word(t, big.NewInt(1))...)
+	data = append(data, word(t, big.NewInt(7*32))...) // offset of amounts
+	tag := make([]byte, 32)
+	copy(tag, []byte{0xde, 0xad, 0xbe, 0xef})
+	data = append(data, tag...)
+	data = append(data, word(t, big.NewInt(int64(len(text))))...)
+	data = append(data, append(text, make([]byte, 32-len(text))...)...)
+	data = append(data, word(t, big.NewInt(2))...)
+	data = append(data, word(t, big.NewInt(8))...)
+	data = append(data, word(t, big.NewInt(9))...)
+
+	topic := ethtypes.EthHashFromTxBytes([]byte("memo"))
+	args, err := note.Decode([]ethtypes.EthHash{note.Topic(), topic}, data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"topic":   topic.String(),
+		"arg1":    "-5",
+		"text":    "hello",
+		"flag":    true,
+		"amounts": []interface{}{"8", "9"},
+		"tag":     "0x" + hex.EncodeToString([]byte{0xde, 0xad, 0xbe, 0xef}),
+	}, args)
+
+	_, err = note.Decode([]ethtypes.EthHash{note.Topic(), topic}, data[:64])
+	require.Error(t, err)
+}
+
+func TestDecodeFixedArray(t *testing.T) {
+	events, err := parseABI(`[{"type":"event","name":"Split","inputs":[
+		{"name":"payees","type":"address[2]","indexed":true},
+		{"name":"shares","type":"uint[3]"},
+		{"name":"memo","type":"string"},
+		{"name":"total","type":"uint256"}
+	]}]`)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	split := events[0]
+	require.Equal(t, "Split(address[2],uint256[3],string,uint256)", split.Signature())
+
+	memo := []byte("rent")
+	var data []byte
+	data = append(data, word(t, big.NewInt(1))...)
+	data = append(data, word(t, big.NewInt(2))...)
+	data = append(data, word(t, big.NewInt(3))...)
+	data = append(data, word(t, big.NewInt(5*32))...) // offset of memo
+	data = append(data, word(t, big.NewInt(6))...)
+	data = append(data, word(t, big.NewInt(int64(len(memo))))...)
+	data = append(data, append(memo, make([]byte, 32-len(memo))...)...)
+
+	topic := ethtypes.EthHashFromTxBytes([]byte("payees"))
+	args, err := split.Decode([]ethtypes.EthHash{split.Topic(), topic}, data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"payees": topic.String(),
+		"shares": []interface{}{"1", "2", "3"},
+		"memo":   "rent",
+		"total":  "6",
+	}, args)
+
+	// the array is held in full in the head
+	_, err = split.Decode([]ethtypes.EthHash{split.Topic(), topic}, data[:64])
+	require.Error(t, err)
+
+	for _, typ := range []string{"string[2]", "uint256[2][2]", "uint256[0]"} {
+		events, err := parseABI(`[{"type":"event","name":"Values","inputs":[{"name":"values","type":"` + typ + `"}]}]`)
+		require.NoError(t, err)
+		_, err = events[0].Decode([]ethtypes.EthHash{events[0].Topic()}, data)
+		require.ErrorContains(t, err, "unsupported type", typ)
+	}
+}
+
+func TestDecodeDynamicMalformed(t *testing.T) {
+	maxLength := new(big.Int).SetUint64(uint64(^uint32(0)))
+
+	testCases := []struct {
+		name string
+		typ  string
+		data [][]byte
+	}{
+		{
+			name: "array length beyond data",
+			typ:  "uint256[]",
+			data: [][]byte{word(t, big.NewInt(32)), word(t, maxLength), word(t, big.NewInt(1))},
+		},
+		{
+			name: "array length one word beyond data",
+			typ:  "uint256[]",
+			data: [][]byte{word(t, big.NewInt(32)), word(t, big.NewInt(2)), word(t, big.NewInt(1))},
+		},
+		{
+			name: "string length beyond data",
+			typ:  "string",
+			data: [][]byte{word(t, big.NewInt(32)), word(t, maxLength)},
+		},
+		{
+			name: "length out of range",
+			typ:  "uint256[]",
+			data: [][]byte{word(t, big.NewInt(32)), word(t, new(big.Int).Add(maxLength, big.NewInt(1)))},
+		},
+		{
+			name: "offset beyond data",
+			typ:  "bytes",
+			data: [][]byte{word(t, maxLength)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := parseABI(`[{"type":"event","name":"Values","inputs":[{"name":"values","type":"` + tc.typ + `"}]}]`)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+
+			var data []byte
+			for _, w := range tc.data {
+				data = append(data, w...)
+			}
+			_, err = events[0].Decode([]ethtypes.EthHash{events[0].Topic()}, data)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewFilterErrors(t *testing.T) {
+	_, err := NewFilter("bad", config.ContractEventFilterConf{Emitters: []string{"nope"}})
+	require.Error(t, err)
+	_, err = NewFilter("bad", config.ContractEventFilterConf{Topic0: []string{"Transfer"}})
+	require.Error(t, err)
+	_, err = NewFilter("bad", config.ContractEventFilterConf{ABI: "/does/not/exist.json"})
+	require.Error(t, err)
+}
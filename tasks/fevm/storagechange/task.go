@@ -0,0 +1,186 @@
+package fevmstoragechange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	evm10 "github.com/filecoin-project/go-state-types/builtin/v10/evm"
+	evm11 "github.com/filecoin-project/go-state-types/builtin/v11/evm"
+	evm12 "github.com/filecoin-project/go-state-types/builtin/v12/evm"
+	evm13 "github.com/filecoin-project/go-state-types/builtin/v13/evm"
+	evm14 "github.com/filecoin-project/go-state-types/builtin/v14/evm"
+	evm15 "github.com/filecoin-project/go-state-types/builtin/v15/evm"
+	evm16 "github.com/filecoin-project/go-state-types/builtin/v16/evm"
+	evm17 "github.com/filecoin-project/go-state-types/builtin/v17/evm"
+	evm18 "github.com/filecoin-project/go-state-types/builtin/v18/evm"
+	"github.com/filecoin-project/lily/chain/actors/adt/diff"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/fevm"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/evm"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var log = logging.Logger("lily/tasks/fevmstoragechange")
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+// ProcessTipSets writes the storage slots changed by the EVM actors added or modified between the states of executed
+// and current.
+func (p *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "fevm_storage_changes"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	actorChanges, err := p.node.ActorStateChanges(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	out := make(fevm.FEVMStorageChangeList, 0)
+	errs := []error{}
+	for addr, change := range actorChanges {
+		actor := change.Actor
+		if change.ChangeType != tasks.ChangeTypeAdd && change.ChangeType != tasks.ChangeTypeModify {
+			continue
+		}
+		if actor.DelegatedAddress == nil || !builtin.IsEvmActor(actor.Code) {
+			continue
+		}
+
+		changes, err := p.storageChanges(ctx, addr, &actor, change.ChangeType, executed)
+		if err != nil {
+			log.Errorf("Error at diffing evm storage: [actor: %v] err: %v", addr, err)
+			errs = append(errs, err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		ethAddress, err := ethtypes.EthAddressFromFilecoinAddress(*actor.DelegatedAddress)
+		if err != nil {
+			log.Errorf("Error at getting eth address: [actor: %v] err: %v", addr, err)
+			errs = append(errs, err)
+			continue
+		}
+		for _, c := range changes {
+			out = append(out, &fevm.FEVMStorageChange{
+				Height:     int64(current.Height()),
+				ActorID:    actor.DelegatedAddress.String(),
+				EthAddress: ethAddress.String(),
+				Slot:       Word(c.Key),
+				OldValue:   Word(c.Before),
+				NewValue:   Word(c.After),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return model.PersistableList{out}, report, nil
+}
+
+// storageChanges diffs the storage of the EVM actor at addr against its storage in the state of executed, the
+// storage of actors that were added or were not EVM actors in that state is treated as empty.
+func (p *Task) storageChanges(ctx context.Context, addr address.Address, actor *types.Actor, changeType tasks.ChangeType, executed *types.TipSet) ([]*diff.KamtChange, error) {
+	curRoot, err := contractStateRoot(p.node.Store(), actor)
+	if err != nil {
+		return nil, err
+	}
+
+	preRoot := cid.Undef
+	if changeType == tasks.ChangeTypeModify {
+		preActor, err := p.node.Actor(ctx, addr, executed.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting actor in parent state: %w", err)
+		}
+		if builtin.IsEvmActor(preActor.Code) {
+			if preActor.Head.Equals(actor.Head) {
+				return nil, nil
+			}
+			preRoot, err = contractStateRoot(p.node.Store(), preActor)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return diff.Kamt(ctx, p.node.Store(), p.node.Store(), preRoot, curRoot)
+}
+
+// contractStateRoot returns the root of the storage KAMT of an EVM actor.
+func contractStateRoot(store adt.Store, actor *types.Actor) (cid.Cid, error) {
+	st, err := evm.Load(store, actor)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("loading evm state: %w", err)
+	}
+	switch s := st.GetState().(type) {
+	case *evm10.State:
+		return s.ContractState, nil
+	case *evm11.State:
+		return s.ContractState, nil
+	case *evm12.State:
+		return s.ContractState, nil
+	case *evm13.State:
+		return s.ContractState, nil
+	case *evm14.State:
+		return s.ContractState, nil
+	case *evm15.State:
+		return s.ContractState, nil
+	case *evm16.State:
+		return s.ContractState, nil
+	case *evm17.State:
+		return s.ContractState, nil
+	case *evm18.State:
+		return s.ContractState, nil
+	default:
+		return cid.Undef, fmt.Errorf("unsupported evm state %T", s)
+	}
+}
+
+// Word returns the 32 byte hex string of a storage slot or value, which are encoded with their leading zeros
+// trimmed. A nil value, of a slot that is not set, is zero.
+func Word(b []byte) string {
+	var w ethtypes.EthHash
+	if len(b) > len(w) {
+		b = b[len(b)-len(w):]
+	}
+	copy(w[len(w)-len(b):], b)
+	return w.String()
+}
@@ -0,0 +1,16 @@
+package fevmstoragechange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWord(t *testing.T) {
+	require.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000000", Word(nil))
+	require.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000102", Word([]byte{1, 2}))
+
+	full := make([]byte, 32)
+	full[0] = 0xff
+	require.Equal(t, "0xff00000000000000000000000000000000000000000000000000000000000000", Word(full))
+}
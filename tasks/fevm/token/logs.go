@@ -0,0 +1,193 @@
+package fevmtoken
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model/fevm"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var (
+	// TransferTopic is the topic of the Transfer(address,address,uint256) event of ERC-20 and ERC-721 tokens.
+	TransferTopic = ethtypes.EthHashFromTxBytes([]byte("Transfer(address,address,uint256)"))
+	// ApprovalTopic is the topic of the Approval(address,address,uint256) event of ERC-20 and ERC-721 tokens.
+	ApprovalTopic = ethtypes.EthHashFromTxBytes([]byte("Approval(address,address,uint256)"))
+)
+
+// DecodeTokenLog returns the token transfer or approval held in l, or false if l is not a Transfer or Approval
+// log of an ERC-20 or ERC-721 token. ERC-20 logs carry the amount in their data and ERC-721 logs the token id as a
+// third indexed topic, the other fields of the returned transfer are left to the caller.
+func DecodeTokenLog(l ethtypes.EthLog) (*fevm.FEVMTokenTransfer, bool) {
+	if len(l.Topics) == 0 {
+		return nil, false
+	}
+
+	var eventType string
+	switch l.Topics[0] {
+	case TransferTopic:
+		eventType = fevm.TokenEventTransfer
+	case ApprovalTopic:
+		eventType = fevm.TokenEventApproval
+	default:
+		return nil, false
+	}
+
+	out := &fevm.FEVMTokenTransfer{
+		LogIndex:     uint64(l.LogIndex),
+		TokenAddress: l.Address.String(),
+		EventType:    eventType,
+	}
+	switch {
+	case len(l.Topics) == 3 && len(l.Data) == 32:
+		out.TokenStandard = fevm.TokenStandardERC20
+		out.Amount = new(big.Int).SetBytes(l.Data).String()
+	case len(l.Topics) == 4 && len(l.Data) == 0:
+		out.TokenStandard = fevm.TokenStandardERC721
+		out.TokenID = new(big.Int).SetBytes(l.Topics[3][:]).String()
+	default:
+		return nil, false
+	}
+
+	from, ok := topicAddress(l.Topics[1])
+	if !ok {
+		return nil, false
+	}
+	to, ok := topicAddress(l.Topics[2])
+	if !ok {
+		return nil, false
+	}
+	out.From = from.String()
+	out.To = to.String()
+	return out, true
+}
+
+// topicAddress returns the address held in an indexed address topic, or false if the topic is not a left padded
+// address.
+func topicAddress(topic ethtypes.EthHash) (ethtypes.EthAddress, bool) {
+	var addr ethtypes.EthAddress
+	pad := len(topic) - len(addr)
+	for _, b := range topic[:pad] {
+		if b != 0 {
+			return addr, false
+		}
+	}
+	copy(addr[:], topic[pad:])
+	return addr, true
+}
+
+// logsCacheSize is the number of tipsets whose token logs are kept by Logs.
+const logsCacheSize = 4
+
+// Logs loads the token transfers and approvals logged by a tipset once for all the tasks sharing it.
+type Logs struct {
+	node tasks.DataSource
+
+	cache *lru.Cache
+	group singleflight.Group
+}
+
+// tipsetLogs are the token transfers and approvals logged by a tipset and the errors met loading them.
+type tipsetLogs struct {
+	transfers fevm.FEVMTokenTransferList
+	errs      []error
+}
+
+// NewLogs returns a Logs loading the token logs of tipsets from node.
+func NewLogs(node tasks.DataSource) *Logs {
+	cache, err := lru.New(logsCacheSize)
+	if err != nil {
+		// only returned for a non-positive size.
+		panic(err)
+	}
+	return &Logs{
+		node:  node,
+		cache: cache,
+	}
+}
+
+// load returns the token transfers and approvals logged by the EVM messages included in ts. The logs of a tipset
+// loaded with errors are not kept, so that processing the tipset again loads them again.
+func (l *Logs) load(ctx context.Context, ts *types.TipSet) (fevm.FEVMTokenTransferList, []error) {
+	key := ts.Key().String()
+	if value, found := l.cache.Get(key); found {
+		return value.(*tipsetLogs).transfers, nil
+	}
+
+	// the load is shared by every task waiting for the logs of ts, so it must not be canceled with the context of the
+	// task that started it.
+	loadCtx := context.WithoutCancel(ctx)
+	ch := l.group.DoChan(key, func() (interface{}, error) {
+		transfers, errs := tokenLogs(loadCtx, l.node, ts)
+		out := &tipsetLogs{transfers: transfers, errs: errs}
+		if len(errs) == 0 {
+			l.cache.Add(key, out)
+		}
+		return out, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, []error{ctx.Err()}
+	case res := <-ch:
+		out := res.Val.(*tipsetLogs)
+		// copy the errors since the caller may append to them.
+		return out.transfers, append([]error(nil), out.errs...)
+	}
+}
+
+// tokenLogs returns the token transfers and approvals logged by the EVM messages included in ts.
+func tokenLogs(ctx context.Context, node tasks.DataSource, ts *types.TipSet) (fevm.FEVMTokenTransferList, []error) {
+	messages, err := node.ChainGetMessagesInTipset(ctx, ts.Key())
+	if err != nil {
+		return nil, []error{fmt.Errorf("getting messages in tipset: %w", err)}
+	}
+
+	var errs []error
+	out := make(fevm.FEVMTokenTransferList, 0)
+	for _, message := range messages {
+		if message.Message == nil {
+			continue
+		}
+		if !util.IsEVMMessage(ctx, node, message.Message, ts.Key()) {
+			continue
+		}
+
+		hash, err := ethtypes.EthHashFromCid(message.Cid)
+		if err != nil {
+			log.Errorf("Error at finding hash: [cid: %v] err: %v", message.Cid, err)
+			errs = append(errs, err)
+			continue
+		}
+
+		receipt, err := node.EthGetTransactionReceipt(ctx, hash)
+		if err != nil {
+			log.Errorf("Error at getting receipt: [hash: %v] err: %v", hash, err)
+			errs = append(errs, err)
+			continue
+		}
+		if receipt == nil {
+			continue
+		}
+
+		for _, l := range receipt.Logs {
+			transfer, ok := DecodeTokenLog(l)
+			if !ok {
+				continue
+			}
+			transfer.Height = int64(ts.Height())
+			transfer.TransactionHash = receipt.TransactionHash.String()
+			transfer.Message = message.Cid.String()
+			out = append(out, transfer)
+		}
+	}
+	return out, errs
+}
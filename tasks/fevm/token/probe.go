@@ -0,0 +1,141 @@
+package fevmtoken
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lily/model/fevm"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var (
+	nameSelector     = selector("name()")
+	symbolSelector   = selector("symbol()")
+	decimalsSelector = selector("decimals()")
+)
+
+// selector returns the 4 byte selector of the method with the signature sig.
+func selector(sig string) []byte {
+	hash := ethtypes.EthHashFromTxBytes([]byte(sig))
+	return hash[:4]
+}
+
+// probeToken calls the name, symbol and decimals methods of the contract at addr, whose filecoin address is to,
+// against the parent state of tsk. ok is false if the contract implements none of them.
+func probeToken(ctx context.Context, node tasks.DataSource, addr ethtypes.EthAddress, to address.Address, tsk types.TipSetKey) (token *fevm.FEVMToken, ok bool, err error) {
+	// calls are sent from the zero address, as eth_call does when no sender is given.
+	from, err := ethtypes.EthAddress{}.ToFilecoinAddress()
+	if err != nil {
+		return nil, false, err
+	}
+
+	token = &fevm.FEVMToken{
+		TokenAddress: addr.String(),
+		ActorID:      to.String(),
+	}
+	if ret, err := call(ctx, node, from, to, nameSelector, tsk); err != nil {
+		return nil, false, err
+	} else if name, found := DecodeString(ret); found {
+		token.Name = name
+		ok = true
+	}
+	if ret, err := call(ctx, node, from, to, symbolSelector, tsk); err != nil {
+		return nil, false, err
+	} else if symbol, found := DecodeString(ret); found {
+		token.Symbol = symbol
+		ok = true
+	}
+	if ret, err := call(ctx, node, from, to, decimalsSelector, tsk); err != nil {
+		return nil, false, err
+	} else if decimals, found := DecodeDecimals(ret); found {
+		token.Decimals = &decimals
+		ok = true
+	}
+	return token, ok, nil
+}
+
+// call invokes the contract at to with the calldata input and returns the data it returned. A nil result is returned
+// if the call reverted or failed to execute, err is only set when the call could not be made.
+func call(ctx context.Context, node tasks.DataSource, from, to address.Address, input []byte, tsk types.TipSetKey) ([]byte, error) {
+	var params bytes.Buffer
+	if err := cbg.WriteByteArray(&params, input); err != nil {
+		return nil, fmt.Errorf("encoding call params: %w", err)
+	}
+
+	res, err := node.StateCall(ctx, &types.Message{
+		From:       from,
+		To:         to,
+		Value:      types.NewInt(0),
+		GasFeeCap:  types.NewInt(0),
+		GasPremium: types.NewInt(0),
+		Method:     builtin.MethodsEVM.InvokeContract,
+		Params:     params.Bytes(),
+	}, tsk)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", to, err)
+	}
+	if res.MsgRct == nil || !res.MsgRct.ExitCode.IsSuccess() {
+		return nil, nil
+	}
+
+	ret := res.MsgRct.Return
+	out, err := cbg.ReadByteArray(bytes.NewReader(ret), uint64(len(ret)))
+	if err != nil {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// DecodeString decodes the value returned by a name or symbol method. Some early tokens return a bytes32 instead
+// of a string, these are padded with zero bytes which are trimmed.
+func DecodeString(ret []byte) (string, bool) {
+	if len(ret) == 32 {
+		s := string(bytes.TrimRight(ret, "\x00"))
+		return s, utf8.ValidString(s)
+	}
+	if len(ret) < 64 || len(ret)%32 != 0 {
+		return "", false
+	}
+
+	offset, ok := wordInt(ret[:32], len(ret))
+	if !ok || offset+32 > len(ret) {
+		return "", false
+	}
+	length, ok := wordInt(ret[offset:offset+32], len(ret))
+	if !ok || offset+32+length > len(ret) {
+		return "", false
+	}
+	s := string(ret[offset+32 : offset+32+length])
+	return s, utf8.ValidString(s)
+}
+
+// DecodeDecimals decodes the value returned by a decimals method.
+func DecodeDecimals(ret []byte) (uint8, bool) {
+	if len(ret) != 32 {
+		return 0, false
+	}
+	v, ok := wordInt(ret, 255)
+	if !ok {
+		return 0, false
+	}
+	return uint8(v), true
+}
+
+// wordInt returns the integer held in a 32 byte word, or false if it is greater than limit.
+func wordInt(word []byte, limit int) (int, bool) {
+	v := new(big.Int).SetBytes(word)
+	if !v.IsInt64() || v.Int64() > int64(limit) {
+		return 0, false
+	}
+	return int(v.Int64()), true
+}
@@ -0,0 +1,154 @@
+package fevmtoken
+
+import (
+	"context"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/fevm"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var log = logging.Logger("lily/tasks/fevmtoken")
+
+// probedCacheSize is the number of contracts the token task remembers having probed.
+const probedCacheSize = 16384
+
+// TransferTask writes the Transfer and Approval logs of ERC-20 and ERC-721 token contracts.
+type TransferTask struct {
+	logs *Logs
+}
+
+// NewTransferTask returns a TransferTask writing the token logs loaded by logs, which may be shared with a TokenTask.
+func NewTransferTask(logs *Logs) *TransferTask {
+	return &TransferTask{
+		logs: logs,
+	}
+}
+
+func (p *TransferTask) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "fevm_token_transfers"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	out, errs := p.logs.load(ctx, current)
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return model.PersistableList{out}, report, nil
+}
+
+// TokenTask writes the contracts seen emitting Transfer or Approval logs that implement at least one of the name,
+// symbol and decimals methods. A contract is written the first time the task sees it, the contracts probed are
+// remembered by the task so a contract may be written again by another task, for example after a restart.
+type TokenTask struct {
+	node tasks.DataSource
+	logs *Logs
+	// probed holds whether the contracts probed by the task are tokens, keyed by ETH address.
+	probed *lru.Cache
+}
+
+// NewTokenTask returns a TokenTask probing the contracts of the token logs loaded by logs, which may be shared with a
+// TransferTask.
+func NewTokenTask(node tasks.DataSource, logs *Logs) *TokenTask {
+	probed, err := lru.New(probedCacheSize)
+	if err != nil {
+		// only returned for a non-positive size.
+		panic(err)
+	}
+	return &TokenTask{
+		node:   node,
+		logs:   logs,
+		probed: probed,
+	}
+}
+
+func (p *TokenTask) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "fevm_tokens"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	transfers, errs := p.logs.load(ctx, current)
+
+	out := make(fevm.FEVMTokenList, 0)
+	seen := make(map[string]bool)
+	for _, transfer := range transfers {
+		if seen[transfer.TokenAddress] || p.probed.Contains(transfer.TokenAddress) {
+			continue
+		}
+		seen[transfer.TokenAddress] = true
+
+		addr, err := ethtypes.ParseEthAddress(transfer.TokenAddress)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		to, err := addr.ToFilecoinAddress()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		token, ok, err := probeToken(ctx, p.node, addr, to, current.Key())
+		if err != nil {
+			log.Errorf("Error at probing token: [address: %v] err: %v", transfer.TokenAddress, err)
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			// the contract may have been created by the messages of current, in which case it does not exist in
+			// the state it was probed against and is probed again the next time it is seen.
+			if _, err := p.node.Actor(ctx, to, current.Key()); err != nil {
+				continue
+			}
+			p.probed.Add(transfer.TokenAddress, false)
+			continue
+		}
+		p.probed.Add(transfer.TokenAddress, true)
+
+		token.Height = int64(current.Height())
+		token.TokenStandard = transfer.TokenStandard
+		out = append(out, token)
+	}
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return model.PersistableList{out}, report, nil
+}
@@ -0,0 +1,210 @@
+package fevmtoken
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/model/fevm"
+	"github.com/filecoin-project/lily/tasks"
+	"github.com/filecoin-project/lily/testutil"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+const (
+	tokenAddr = "0x60e1773636cf5e4a227d9ac24f20feca034ee25a"
+	fromAddr  = "0x1111111111111111111111111111111111111111"
+	toAddr    = "0x2222222222222222222222222222222222222222"
+)
+
+func word(v uint64) []byte {
+	return new(big.Int).SetUint64(v).FillBytes(make([]byte, 32))
+}
+
+func addressTopic(t *testing.T, s string) ethtypes.EthHash {
+	ea, err := ethtypes.ParseEthAddress(s)
+	require.NoError(t, err)
+	var h ethtypes.EthHash
+	copy(h[12:], ea[:])
+	return h
+}
+
+func TestTopics(t *testing.T) {
+	require.Equal(t, "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", TransferTopic.String())
+	require.Equal(t, "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925", ApprovalTopic.String())
+}
+
+func TestDecodeTokenLog(t *testing.T) {
+	token, err := ethtypes.ParseEthAddress(tokenAddr)
+	require.NoError(t, err)
+
+	t.Run("erc20 transfer", func(t *testing.T) {
+		out, ok := DecodeTokenLog(ethtypes.EthLog{
+			Address:  token,
+			Topics:   []ethtypes.EthHash{TransferTopic, addressTopic(t, fromAddr), addressTopic(t, toAddr)},
+			Data:     word(1_000_000),
+			LogIndex: 3,
+		})
+		require.True(t, ok)
+		require.Equal(t, &fevm.FEVMTokenTransfer{
+			LogIndex:      3,
+			TokenAddress:  tokenAddr,
+			TokenStandard: fevm.TokenStandardERC20,
+			EventType:     fevm.TokenEventTransfer,
+			From:          fromAddr,
+			To:            toAddr,
+			Amount:        "1000000",
+		}, out)
+	})
+
+	t.Run("erc721 approval", func(t *testing.T) {
+		var id ethtypes.EthHash
+		copy(id[:], word(42))
+		out, ok := DecodeTokenLog(ethtypes.EthLog{
+			Address: token,
+			Topics:  []ethtypes.EthHash{ApprovalTopic, addressTopic(t, fromAddr), addressTopic(t, toAddr), id},
+		})
+		require.True(t, ok)
+		require.Equal(t, fevm.TokenStandardERC721, out.TokenStandard)
+		require.Equal(t, fevm.TokenEventApproval, out.EventType)
+		require.Equal(t, "42", out.TokenID)
+		require.Empty(t, out.Amount)
+	})
+
+	t.Run("other logs", func(t *testing.T) {
+		other := ethtypes.EthHashFromTxBytes([]byte("ApprovalForAll(address,address,bool)"))
+		_, ok := DecodeTokenLog(ethtypes.EthLog{
+			Address: token,
+			Topics:  []ethtypes.EthHash{other, addressTopic(t, fromAddr), addressTopic(t, toAddr)},
+			Data:    word(1),
+		})
+		require.False(t, ok)
+
+		// a transfer with an amount that is not a single word.
+		_, ok = DecodeTokenLog(ethtypes.EthLog{
+			Address: token,
+			Topics:  []ethtypes.EthHash{TransferTopic, addressTopic(t, fromAddr), addressTopic(t, toAddr)},
+		})
+		require.False(t, ok)
+
+		// a transfer with a topic that is not an address.
+		_, ok = DecodeTokenLog(ethtypes.EthLog{
+			Address: token,
+			Topics:  []ethtypes.EthHash{TransferTopic, TransferTopic, addressTopic(t, toAddr)},
+			Data:    word(1),
+		})
+		require.False(t, ok)
+	})
+}
+
+func TestDecodeString(t *testing.T) {
+	ret := append(word(32), word(8)...)
+	ret = append(ret, []byte("Wrapped FIL")[:8]...)
+	ret = append(ret, make([]byte, 24)...)
+	s, ok := DecodeString(ret)
+	require.True(t, ok)
+	require.Equal(t, "Wrapped ", s)
+
+	bytes32 := make([]byte, 32)
+	copy(bytes32, "MKR")
+	s, ok = DecodeString(bytes32)
+	require.True(t, ok)
+	require.Equal(t, "MKR", s)
+
+	// the length runs past the end of the data.
+	_, ok = DecodeString(append(word(32), word(64)...))
+	require.False(t, ok)
+
+	_, ok = DecodeString(nil)
+	require.False(t, ok)
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	d, ok := DecodeDecimals(word(18))
+	require.True(t, ok)
+	require.Equal(t, uint8(18), d)
+
+	_, ok = DecodeDecimals(word(256))
+	require.False(t, ok)
+
+	_, ok = DecodeDecimals(nil)
+	require.False(t, ok)
+}
+
+// logSource serves a tipset holding a single EVM message whose receipt holds logs, and counts the calls made to fetch
+// the messages of the tipset.
+type logSource struct {
+	tasks.DataSource
+	message *types.Message
+	logs    []ethtypes.EthLog
+	fail    bool
+	calls   int
+}
+
+func (s *logSource) ChainGetMessagesInTipset(_ context.Context, _ types.TipSetKey) ([]api.Message, error) {
+	s.calls++
+	return []api.Message{{Cid: s.message.Cid(), Message: s.message}}, nil
+}
+
+func (s *logSource) Actor(_ context.Context, _ address.Address, _ types.TipSetKey) (*types.Actor, error) {
+	return nil, errors.New("actor not found")
+}
+
+func (s *logSource) EthGetTransactionReceipt(_ context.Context, hash ethtypes.EthHash) (*api.EthTxReceipt, error) {
+	if s.fail {
+		return nil, errors.New("receipt not found")
+	}
+	return &api.EthTxReceipt{TransactionHash: hash, Logs: s.logs}, nil
+}
+
+func TestLogsLoadedOnce(t *testing.T) {
+	token, err := ethtypes.ParseEthAddress(tokenAddr)
+	require.NoError(t, err)
+	from, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	src := &logSource{
+		message: &types.Message{
+			From:       from,
+			To:         builtin.EthereumAddressManagerActorAddr,
+			Value:      abi.NewTokenAmount(0),
+			GasFeeCap:  abi.NewTokenAmount(0),
+			GasPremium: abi.NewTokenAmount(0),
+		},
+		logs: []ethtypes.EthLog{{
+			Address: token,
+			Topics:  []ethtypes.EthHash{TransferTopic, addressTopic(t, fromAddr), addressTopic(t, toAddr)},
+			Data:    word(5),
+		}},
+		fail: true,
+	}
+	logs := NewLogs(src)
+	ts := testutil.MustFakeTipSet(t, 10)
+
+	// the logs of a tipset loaded with errors are loaded again
+	_, errs := logs.load(context.Background(), ts)
+	require.Len(t, errs, 1)
+	_, errs = logs.load(context.Background(), ts)
+	require.Len(t, errs, 1)
+	require.Equal(t, 2, src.calls)
+
+	// the logs of a tipset are loaded once for the transfer and token tasks
+	src.fail = false
+	for i := 0; i < 2; i++ {
+		transfers, errs := logs.load(context.Background(), ts)
+		require.Empty(t, errs)
+		require.Len(t, transfers, 1)
+		require.Equal(t, "5", transfers[0].Amount)
+		require.Equal(t, int64(10), transfers[0].Height)
+	}
+	require.Equal(t, 3, src.calls)
+}
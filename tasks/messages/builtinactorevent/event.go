@@ -0,0 +1,120 @@
+package builtinactorevent
+
+import (
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/lens/util"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// cborNull is the CBOR encoding of null, used for optional entries such as the unsealed CID of a sector without data.
+const cborNull = 0xf6
+
+// Event is a built-in actor event with its entries decoded.
+type Event struct {
+	Height     int64
+	MessageCid string
+	EventIdx   int64
+	Emitter    string
+	Type       string
+	// Entries holds the decoded values of the entries of the event by key, in the order they were emitted. Keys may be
+	// repeated, a sector-activated event has a piece-cid and piece-size entry for each piece in the sector.
+	Entries map[string][]interface{}
+}
+
+// DecodeEvent decodes the entries of event, the eventIdx-th event emitted in the tipset at height.
+func DecodeEvent(height int64, eventIdx int, event *types.ActorEvent) *Event {
+	evt := &Event{
+		Height:     height,
+		MessageCid: event.MsgCid.String(),
+		EventIdx:   int64(eventIdx),
+		Emitter:    event.Emitter.String(),
+		Entries:    map[string][]interface{}{},
+	}
+	for _, e := range event.Entries {
+		if e.Codec != 0x51 { // 81
+			continue
+		}
+		var v interface{}
+		if len(e.Value) != 1 || e.Value[0] != cborNull {
+			v = util.CborValueDecode(e.Key, e.Value)
+		}
+		if e.Key == "$type" {
+			evt.Type, _ = v.(string)
+			continue
+		}
+		evt.Entries[e.Key] = append(evt.Entries[e.Key], v)
+	}
+	return evt
+}
+
+// Uint returns the first value of the integer entry key, or zero if there is none.
+func (e *Event) Uint(key string) uint64 {
+	return uint64(e.Int(key))
+}
+
+// Int returns the first value of the integer entry key, or zero if there is none.
+func (e *Event) Int(key string) int64 {
+	for _, v := range e.Entries[key] {
+		if i, ok := v.(int); ok {
+			return int64(i)
+		}
+	}
+	return 0
+}
+
+// Uints returns the values of the integer entry key.
+func (e *Event) Uints(key string) []uint64 {
+	var out []uint64
+	for _, v := range e.Entries[key] {
+		if i, ok := v.(int); ok {
+			out = append(out, uint64(i))
+		}
+	}
+	return out
+}
+
+// ID returns the address of the actor id held by the entry key, or an empty string if there is none.
+func (e *Event) ID(key string) string {
+	for _, v := range e.Entries[key] {
+		if i, ok := v.(int); ok {
+			if addr, err := address.NewIDAddress(uint64(i)); err == nil {
+				return addr.String()
+			}
+		}
+	}
+	return ""
+}
+
+// BigInt returns the first value of the big integer entry key, or zero if there is none.
+func (e *Event) BigInt(key string) string {
+	for _, v := range e.Entries[key] {
+		if i, ok := v.(types.BigInt); ok {
+			return i.String()
+		}
+	}
+	return "0"
+}
+
+// Cid returns the first value of the CID entry key, or an empty string if there is none.
+func (e *Event) Cid(key string) string {
+	for _, v := range e.Entries[key] {
+		if c, ok := v.(cid.Cid); ok {
+			return c.String()
+		}
+	}
+	return ""
+}
+
+// Cids returns the values of the CID entry key.
+func (e *Event) Cids(key string) []string {
+	var out []string
+	for _, v := range e.Entries[key] {
+		if c, ok := v.(cid.Cid); ok {
+			out = append(out, c.String())
+		}
+	}
+	return out
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 
+	lru "github.com/hashicorp/golang-lru"
 	logging "github.com/ipfs/go-log/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/filecoin-project/lily/lens/util"
 	"github.com/filecoin-project/lily/model"
@@ -21,38 +23,136 @@ import (
 var log = logging.Logger("lily/tasks/builtinactorevent")
 
 type Task struct {
-	node tasks.DataSource
+	events *Events
 }
 
-func NewTask(node tasks.DataSource) *Task {
+// NewTask returns a task storing the built-in actor events of the target types of events in builtin_actor_events.
+func NewTask(events *Events) *Task {
 	return &Task{
-		node: node,
+		events: events,
 	}
 }
 
-var (
-	fields map[string][]types.ActorEventBlock
-)
+// DefaultTargetEvents are the event types emitted by the built-in actors, see
+// https://fips.filecoin.io/FIPS/fip-0083.html
+var DefaultTargetEvents = []string{
+	"verifier-balance",
+	"allocation",
+	"allocation-removed",
+	"claim",
+	"claim-updated",
+	"claim-removed",
+	"deal-published",
+	"deal-activated",
+	"deal-terminated",
+	"deal-completed",
+	"sector-precommitted",
+	"sector-activated",
+	"sector-updated",
+	"sector-terminated",
+}
 
-func init() {
-	targetEvents := []string{
-		"verifier-balance",
-		"allocation",
-		"allocation-removed",
-		"claim",
-		"claim-updated",
-		"claim-removed",
-		"deal-published",
-		"deal-activated",
-		"deal-terminated",
-		"deal-completed",
-		"sector-precommitted",
-		"sector-activated",
-		"sector-updated",
-		"sector-terminated",
+// TargetEvents returns the set of event types in events, or DefaultTargetEvents if events is empty. It returns an
+// error if events holds a type not emitted by the built-in actors.
+func TargetEvents(events []string) (map[string]bool, error) {
+	known := map[string]bool{}
+	for _, evt := range DefaultTargetEvents {
+		known[evt] = true
+	}
+	if len(events) == 0 {
+		return known, nil
+	}
+	out := map[string]bool{}
+	for _, evt := range events {
+		if !known[evt] {
+			return nil, fmt.Errorf("unknown built-in actor event type: %q", evt)
+		}
+		out[evt] = true
 	}
+	return out, nil
+}
+
+// eventsCacheSize is the number of tipsets whose events are kept by Events.
+const eventsCacheSize = 4
+
+// Events loads the built-in actor events emitted by a tipset once for all the tasks sharing it, and decodes those of
+// its target types.
+type Events struct {
+	node    tasks.DataSource
+	targets map[string]bool
+
+	cache *lru.Cache
+	group singleflight.Group
+}
 
-	fields = util.GenFilterFields(targetEvents)
+// tipsetEvents are the built-in actor events emitted by a tipset.
+type tipsetEvents struct {
+	// raw holds every event emitted by the tipset.
+	raw []*types.ActorEvent
+	// decoded holds the events of the target types, decoded.
+	decoded []*Event
+}
+
+// NewEvents returns an Events loading the built-in actor events of the types in targets, or of DefaultTargetEvents if
+// targets is empty.
+func NewEvents(node tasks.DataSource, targets []string) (*Events, error) {
+	t, err := TargetEvents(targets)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := lru.New(eventsCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Events{
+		node:    node,
+		targets: t,
+		cache:   cache,
+	}, nil
+}
+
+// load returns the built-in actor events emitted executing the messages of executed.
+func (e *Events) load(ctx context.Context, current, executed *types.TipSet) (*tipsetEvents, error) {
+	key := executed.Key().String()
+	if value, found := e.cache.Get(key); found {
+		return value.(*tipsetEvents), nil
+	}
+
+	// the load is shared by every task waiting for the events of executed, so it must not be canceled with the context
+	// of the task that started it.
+	loadCtx := context.WithoutCancel(ctx)
+	ch := e.group.DoChan(key, func() (interface{}, error) {
+		// events are only available once the messages of executed have been executed.
+		if _, err := e.node.MessageExecutions(loadCtx, current, executed); err != nil {
+			return nil, fmt.Errorf("getting messages executions for tipset: %w", err)
+		}
+
+		tsKey := executed.Key()
+		raw, err := e.node.GetActorEventsRaw(loadCtx, &types.ActorEventFilter{TipSetKey: &tsKey})
+		if err != nil {
+			return nil, fmt.Errorf("getting actor events for tipset: %w", err)
+		}
+
+		out := &tipsetEvents{raw: raw}
+		for evtIdx, event := range raw {
+			evt := DecodeEvent(int64(executed.Height()), evtIdx, event)
+			if e.targets[evt.Type] {
+				out.decoded = append(out.decoded, evt)
+			}
+		}
+		e.cache.Add(key, out)
+		return out, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*tipsetEvents), nil
+	}
 }
 
 func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
@@ -63,39 +163,26 @@ func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, execut
 			attribute.Int64("current_height", int64(current.Height())),
 			attribute.String("executed", executed.String()),
 			attribute.Int64("executed_height", int64(executed.Height())),
-			attribute.String("processor", "fevm_trace"),
+			attribute.String("processor", "builtin_actor_event"),
 		)
 	}
 	defer span.End()
-	errs := []error{}
-
-	tsKey := executed.Key()
-	filter := &types.ActorEventFilter{
-		TipSetKey: &tsKey,
-	}
-
 	report := &visormodel.ProcessingReport{
 		Height:    int64(current.Height()),
 		StateRoot: current.ParentState().String(),
 	}
 
-	_, err := t.node.MessageExecutions(ctx, current, executed)
+	tsEvents, err := t.events.load(ctx, current, executed)
 	if err != nil {
-		report.ErrorsDetected = fmt.Errorf("getting messages executions for tipset: %w", err)
+		report.ErrorsDetected = err
 		return nil, report, nil
 	}
 
-	events, err := t.node.GetActorEventsRaw(ctx, filter)
-	if err != nil {
-		log.Errorf("GetActorEventsRaw[pTs: %v, pHeight: %v, cTs: %v, cHeight: %v] err: %v", executed.Key().String(), executed.Height(), current.Key().String(), current.Height(), err)
-		errs = append(errs, err)
-	}
-
 	var (
 		builtInActorResult = make(builtinactor.BuiltInActorEvents, 0)
 	)
 
-	for evtIdx, event := range events {
+	for evtIdx, event := range tsEvents.raw {
 		eventType, actorEvent, eventsSlice := util.HandleEventEntries(event)
 
 		obj := builtinactor.BuiltInActorEvent{
@@ -115,14 +202,10 @@ func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, execut
 		if jsonErr == nil {
 			obj.EventPayload = string(payload)
 		}
-		if obj.EventType != "" {
+		if obj.EventType != "" && t.events.targets[obj.EventType] {
 			builtInActorResult = append(builtInActorResult, &obj)
 		}
 	}
 
-	if len(errs) > 0 {
-		report.ErrorsDetected = fmt.Errorf("%v", errs)
-	}
-
 	return builtInActorResult, report, nil
 }
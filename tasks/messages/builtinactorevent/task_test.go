@@ -0,0 +1,254 @@
+package builtinactorevent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/actors/builtinactor"
+	"github.com/filecoin-project/lily/tasks"
+	"github.com/filecoin-project/lily/testutil"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func entry(t *testing.T, key string, n datamodel.Node) types.EventEntry {
+	value, err := ipld.Encode(n, dagcbor.Encode)
+	require.NoError(t, err)
+	return types.EventEntry{Key: key, Codec: 0x51, Value: value}
+}
+
+func mustCid(t *testing.T, s string) cid.Cid {
+	c, err := cid.Decode(s)
+	require.NoError(t, err)
+	return c
+}
+
+func TestSectorLifecycle(t *testing.T) {
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	msg := mustCid(t, "bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+	unsealed := mustCid(t, "baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	piece1 := mustCid(t, "baga6ea4seaqhfvwbdypebhffobtxjyp4gunwgwy2ydanlvbe6uizm5hlccxqmeq")
+	piece2 := mustCid(t, "baga6ea4seaqgqzxo27ongakwwef5x3cihl6fgritvgeq5akvjqijqujmmmfjmia")
+
+	events := []*types.ActorEvent{
+		{
+			Emitter: miner,
+			MsgCid:  msg,
+			Entries: []types.EventEntry{
+				entry(t, "$type", basicnode.NewString("sector-activated")),
+				entry(t, "sector", basicnode.NewInt(7)),
+				entry(t, "unsealed-cid", basicnode.NewLink(cidlink.Link{Cid: unsealed})),
+				entry(t, "piece-cid", basicnode.NewLink(cidlink.Link{Cid: piece1})),
+				entry(t, "piece-size", basicnode.NewInt(2048)),
+				entry(t, "piece-cid", basicnode.NewLink(cidlink.Link{Cid: piece2})),
+				entry(t, "piece-size", basicnode.NewInt(4096)),
+			},
+		},
+		{
+			// a sector without data has no unsealed cid
+			Emitter: miner,
+			MsgCid:  msg,
+			Entries: []types.EventEntry{
+				entry(t, "$type", basicnode.NewString("sector-activated")),
+				entry(t, "sector", basicnode.NewInt(8)),
+				entry(t, "unsealed-cid", datamodel.Null),
+			},
+		},
+	}
+
+	var decoded []*Event
+	for idx, event := range events {
+		decoded = append(decoded, DecodeEvent(10, idx, event))
+	}
+	rows := SectorLifecycle(decoded)
+	require.Equal(t, builtinactor.EventSectorLifecycleList{
+		{
+			Height:       10,
+			MessageCid:   msg.String(),
+			EventIdx:     0,
+			MinerID:      "f01000",
+			EventType:    "sector-activated",
+			SectorNumber: 7,
+			UnsealedCid:  unsealed.String(),
+			PieceCids:    []string{piece1.String(), piece2.String()},
+			PieceSizes:   []uint64{2048, 4096},
+		},
+		{
+			Height:       10,
+			MessageCid:   msg.String(),
+			EventIdx:     1,
+			MinerID:      "f01000",
+			EventType:    "sector-activated",
+			SectorNumber: 8,
+		},
+	}, rows)
+}
+
+func TestDealLifecycle(t *testing.T) {
+	market, err := address.NewIDAddress(5)
+	require.NoError(t, err)
+	msg := mustCid(t, "bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+
+	evt := DecodeEvent(10, 3, &types.ActorEvent{
+		Emitter: market,
+		MsgCid:  msg,
+		Entries: []types.EventEntry{
+			entry(t, "$type", basicnode.NewString("deal-activated")),
+			entry(t, "id", basicnode.NewInt(42)),
+			entry(t, "client", basicnode.NewInt(2000)),
+			entry(t, "provider", basicnode.NewInt(1000)),
+		},
+	})
+	require.Equal(t, builtinactor.EventDealLifecycleList{{
+		Height:     10,
+		MessageCid: msg.String(),
+		EventIdx:   3,
+		Emitter:    "f05",
+		EventType:  "deal-activated",
+		DealID:     42,
+		Client:     "f02000",
+		Provider:   "f01000",
+	}}, DealLifecycle([]*Event{evt}))
+}
+
+func TestTargetEvents(t *testing.T) {
+	all, err := TargetEvents(nil)
+	require.NoError(t, err)
+	require.Len(t, all, len(DefaultTargetEvents))
+
+	some, err := TargetEvents([]string{"claim", "deal-activated"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"claim": true, "deal-activated": true}, some)
+
+	_, err = TargetEvents([]string{"deal-activated", "not-an-event"})
+	require.Error(t, err)
+}
+
+// eventSource serves the actor events of a tipset from memory and counts the calls made to fetch them. If block is set
+// fetching the events signals started and waits for block to be closed.
+type eventSource struct {
+	tasks.DataSource
+	events  []*types.ActorEvent
+	calls   int
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (e *eventSource) MessageExecutions(_ context.Context, _, _ *types.TipSet) ([]*lens.MessageExecution, error) {
+	return nil, nil
+}
+
+func (e *eventSource) GetActorEventsRaw(_ context.Context, _ *types.ActorEventFilter) ([]*types.ActorEvent, error) {
+	e.calls++
+	if e.block != nil {
+		close(e.started)
+		<-e.block
+	}
+	return e.events, nil
+}
+
+func TestTypedTasksShareEvents(t *testing.T) {
+	ctx := context.Background()
+	market, err := address.NewIDAddress(5)
+	require.NoError(t, err)
+	verifreg, err := address.NewIDAddress(6)
+	require.NoError(t, err)
+	msg := mustCid(t, "bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+
+	src := &eventSource{events: []*types.ActorEvent{
+		{
+			Emitter: market,
+			MsgCid:  msg,
+			Entries: []types.EventEntry{
+				entry(t, "$type", basicnode.NewString("deal-activated")),
+				entry(t, "id", basicnode.NewInt(42)),
+			},
+		},
+		{
+			Emitter: verifreg,
+			MsgCid:  msg,
+			Entries: []types.EventEntry{
+				entry(t, "$type", basicnode.NewString("claim")),
+				entry(t, "id", basicnode.NewInt(7)),
+			},
+		},
+		{
+			Emitter: market,
+			MsgCid:  msg,
+			Entries: []types.EventEntry{
+				entry(t, "$type", basicnode.NewString("deal-terminated")),
+				entry(t, "id", basicnode.NewInt(43)),
+			},
+		},
+	}}
+	// deal-terminated events are not indexed.
+	events, err := NewEvents(src, []string{"deal-activated", "claim"})
+	require.NoError(t, err)
+
+	executed := testutil.MustFakeTipSet(t, 10)
+	current := testutil.MustFakeTipSet(t, 11)
+
+	deals, report, err := NewDealLifecycleTask(events).ProcessTipSets(ctx, current, executed)
+	require.NoError(t, err)
+	require.Nil(t, report.ErrorsDetected)
+	require.Len(t, deals, 1)
+	require.Equal(t, uint64(42), deals.(builtinactor.EventDealLifecycleList)[0].DealID)
+
+	claims, report, err := NewVerifregClaimTask(events).ProcessTipSets(ctx, current, executed)
+	require.NoError(t, err)
+	require.Nil(t, report.ErrorsDetected)
+	require.Len(t, claims, 1)
+	require.Equal(t, int64(1), claims.(builtinactor.EventVerifregClaimList)[0].EventIdx)
+
+	all, report, err := NewTask(events).ProcessTipSets(ctx, current, executed)
+	require.NoError(t, err)
+	require.Nil(t, report.ErrorsDetected)
+	require.Len(t, all, 2)
+
+	require.Equal(t, 1, src.calls)
+}
+
+func TestEventsLoadCanceled(t *testing.T) {
+	src := &eventSource{
+		events:  []*types.ActorEvent{{Entries: []types.EventEntry{entry(t, "$type", basicnode.NewString("claim"))}}},
+		started: make(chan struct{}),
+		block:   make(chan struct{}),
+	}
+	events, err := NewEvents(src, nil)
+	require.NoError(t, err)
+
+	executed := testutil.MustFakeTipSet(t, 10)
+	current := testutil.MustFakeTipSet(t, 11)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := events.load(ctx, current, executed)
+		canceled <- err
+	}()
+	<-src.started
+
+	loaded := make(chan *tipsetEvents)
+	go func() {
+		evts, err := events.load(context.Background(), current, executed)
+		require.NoError(t, err)
+		loaded <- evts
+	}()
+
+	// the task that started the load is canceled, the load carries on for the other tasks
+	cancel()
+	require.ErrorIs(t, <-canceled, context.Canceled)
+	close(src.block)
+	require.Len(t, (<-loaded).decoded, 1)
+}
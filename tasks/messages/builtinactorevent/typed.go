@@ -0,0 +1,206 @@
+package builtinactorevent
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actors/builtinactor"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// A TypedTask stores the built-in actor events of a family in a table with a column for each of their entries.
+type TypedTask struct {
+	events *Events
+	name   string
+	types  map[string]bool
+	rows   func(events []*Event) model.Persistable
+}
+
+// NewDealLifecycleTask returns a task storing the deal events of the market actor in event_deal_lifecycle.
+func NewDealLifecycleTask(events *Events) *TypedTask {
+	return newTypedTask(events, "event_deal_lifecycle", []string{"deal-published", "deal-activated", "deal-terminated", "deal-completed"}, func(events []*Event) model.Persistable {
+		return DealLifecycle(events)
+	})
+}
+
+// NewVerifierBalanceTask returns a task storing the verifier-balance events of the verified registry actor in
+// event_verifier_balance.
+func NewVerifierBalanceTask(events *Events) *TypedTask {
+	return newTypedTask(events, "event_verifier_balance", []string{"verifier-balance"}, func(events []*Event) model.Persistable {
+		return VerifierBalance(events)
+	})
+}
+
+// NewVerifregAllocationTask returns a task storing the allocation events of the verified registry actor in
+// event_verifreg_allocation.
+func NewVerifregAllocationTask(events *Events) *TypedTask {
+	return newTypedTask(events, "event_verifreg_allocation", []string{"allocation", "allocation-removed"}, func(events []*Event) model.Persistable {
+		return VerifregAllocation(events)
+	})
+}
+
+// NewVerifregClaimTask returns a task storing the claim events of the verified registry actor in event_verifreg_claim.
+func NewVerifregClaimTask(events *Events) *TypedTask {
+	return newTypedTask(events, "event_verifreg_claim", []string{"claim", "claim-updated", "claim-removed"}, func(events []*Event) model.Persistable {
+		return VerifregClaim(events)
+	})
+}
+
+// NewSectorLifecycleTask returns a task storing the sector events of miner actors in event_sector_lifecycle.
+func NewSectorLifecycleTask(events *Events) *TypedTask {
+	return newTypedTask(events, "event_sector_lifecycle", []string{"sector-precommitted", "sector-activated", "sector-updated", "sector-terminated"}, func(events []*Event) model.Persistable {
+		return SectorLifecycle(events)
+	})
+}
+
+func newTypedTask(events *Events, name string, eventTypes []string, rows func(events []*Event) model.Persistable) *TypedTask {
+	t := &TypedTask{
+		events: events,
+		name:   name,
+		types:  map[string]bool{},
+		rows:   rows,
+	}
+	for _, typ := range eventTypes {
+		t.types[typ] = true
+	}
+	return t
+}
+
+func (t *TypedTask) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", t.name),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	tsEvents, err := t.events.load(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	var decoded []*Event
+	for _, evt := range tsEvents.decoded {
+		if t.types[evt.Type] {
+			decoded = append(decoded, evt)
+		}
+	}
+
+	return t.rows(decoded), report, nil
+}
+
+// DealLifecycle returns a row of event_deal_lifecycle for each of the deal events.
+func DealLifecycle(events []*Event) builtinactor.EventDealLifecycleList {
+	out := make(builtinactor.EventDealLifecycleList, 0, len(events))
+	for _, evt := range events {
+		out = append(out, &builtinactor.EventDealLifecycle{
+			Height:     evt.Height,
+			MessageCid: evt.MessageCid,
+			EventIdx:   evt.EventIdx,
+			Emitter:    evt.Emitter,
+			EventType:  evt.Type,
+			DealID:     evt.Uint("id"),
+			Client:     evt.ID("client"),
+			Provider:   evt.ID("provider"),
+		})
+	}
+	return out
+}
+
+// VerifierBalance returns a row of event_verifier_balance for each of the verifier-balance events.
+func VerifierBalance(events []*Event) builtinactor.EventVerifierBalanceList {
+	out := make(builtinactor.EventVerifierBalanceList, 0, len(events))
+	for _, evt := range events {
+		out = append(out, &builtinactor.EventVerifierBalance{
+			Height:     evt.Height,
+			MessageCid: evt.MessageCid,
+			EventIdx:   evt.EventIdx,
+			Emitter:    evt.Emitter,
+			Verifier:   evt.ID("verifier"),
+			Balance:    evt.BigInt("balance"),
+		})
+	}
+	return out
+}
+
+// VerifregAllocation returns a row of event_verifreg_allocation for each of the allocation events.
+func VerifregAllocation(events []*Event) builtinactor.EventVerifregAllocationList {
+	out := make(builtinactor.EventVerifregAllocationList, 0, len(events))
+	for _, evt := range events {
+		out = append(out, &builtinactor.EventVerifregAllocation{
+			Height:       evt.Height,
+			MessageCid:   evt.MessageCid,
+			EventIdx:     evt.EventIdx,
+			Emitter:      evt.Emitter,
+			EventType:    evt.Type,
+			AllocationID: evt.Uint("id"),
+			Client:       evt.ID("client"),
+			Provider:     evt.ID("provider"),
+			PieceCid:     evt.Cid("piece-cid"),
+			PieceSize:    evt.Uint("piece-size"),
+			TermMin:      evt.Int("term-min"),
+			TermMax:      evt.Int("term-max"),
+			Expiration:   evt.Int("expiration"),
+		})
+	}
+	return out
+}
+
+// VerifregClaim returns a row of event_verifreg_claim for each of the claim events.
+func VerifregClaim(events []*Event) builtinactor.EventVerifregClaimList {
+	out := make(builtinactor.EventVerifregClaimList, 0, len(events))
+	for _, evt := range events {
+		out = append(out, &builtinactor.EventVerifregClaim{
+			Height:     evt.Height,
+			MessageCid: evt.MessageCid,
+			EventIdx:   evt.EventIdx,
+			Emitter:    evt.Emitter,
+			EventType:  evt.Type,
+			ClaimID:    evt.Uint("id"),
+			Client:     evt.ID("client"),
+			Provider:   evt.ID("provider"),
+			PieceCid:   evt.Cid("piece-cid"),
+			PieceSize:  evt.Uint("piece-size"),
+			TermMin:    evt.Int("term-min"),
+			TermMax:    evt.Int("term-max"),
+			TermStart:  evt.Int("term-start"),
+			Sector:     evt.Uint("sector"),
+		})
+	}
+	return out
+}
+
+// SectorLifecycle returns a row of event_sector_lifecycle for each of the sector events.
+func SectorLifecycle(events []*Event) builtinactor.EventSectorLifecycleList {
+	out := make(builtinactor.EventSectorLifecycleList, 0, len(events))
+	for _, evt := range events {
+		out = append(out, &builtinactor.EventSectorLifecycle{
+			Height:       evt.Height,
+			MessageCid:   evt.MessageCid,
+			EventIdx:     evt.EventIdx,
+			MinerID:      evt.Emitter,
+			EventType:    evt.Type,
+			SectorNumber: evt.Uint("sector"),
+			UnsealedCid:  evt.Cid("unsealed-cid"),
+			PieceCids:    evt.Cids("piece-cid"),
+			PieceSizes:   evt.Uints("piece-size"),
+		})
+	}
+	return out
+}
@@ -0,0 +1,243 @@
+package methodgasstats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lily/chain/actors/builtin"
+	"github.com/filecoin-project/lily/chain/datasource"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
+	messagemodel "github.com/filecoin-project/lily/model/messages"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/methodgasstats")
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+// ProcessTipSets emits a row for each actor family and method called by the messages executed in executed,
+// summarizing the gas they used and burnt.
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "method_gas_stats"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	grp, grpCtx := errgroup.WithContext(ctx)
+
+	var getActorCodeFn func(ctx context.Context, address address.Address) (cid.Cid, bool)
+	grp.Go(func() error {
+		var err error
+		getActorCodeFn, err = util.MakeGetActorCodeFunc(grpCtx, t.node.Store(), current, executed)
+		if err != nil {
+			return fmt.Errorf("getting actor code lookup function: %w", err)
+		}
+		return nil
+	})
+
+	var blkMsgRec []*lens.BlockMessageReceipts
+	grp.Go(func() error {
+		var err error
+		blkMsgRec, err = t.node.TipSetMessageReceipts(grpCtx, current, executed)
+		if err != nil {
+			return fmt.Errorf("getting messages and receipts: %w", err)
+		}
+		return nil
+	})
+	var burnFn lens.ShouldBurnFn
+	grp.Go(func() error {
+		var err error
+		burnFn, err = t.node.ShouldBurnFn(grpCtx, executed)
+		if err != nil {
+			return fmt.Errorf("getting should burn function: %w", err)
+		}
+		return nil
+	})
+
+	if err := grp.Wait(); err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	var (
+		gas        []MessageGas
+		exeMsgSeen = make(map[cid.Cid]bool)
+		// method names are only parsed from the first message calling a method of an actor.
+		methodNames = make(map[methodKey]string)
+	)
+	for _, msgrec := range blkMsgRec {
+		// Stop processing if we have been told to cancel
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("context done: %w", ctx.Err())
+		default:
+		}
+
+		itr, err := msgrec.Iterator()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		blk := msgrec.Block
+		for itr.HasNext() {
+			m, _, r := itr.Next()
+			if exeMsgSeen[m.Cid()] {
+				continue
+			}
+			exeMsgSeen[m.Cid()] = true
+
+			toActorCode, found := getActorCodeFn(ctx, m.VMMessage().To)
+			if !found {
+				toActorCode = cid.Undef
+			}
+			gasOutputs, err := datasource.ComputeGasOutputs(ctx, blk, m.VMMessage(), r, burnFn)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			key := methodKey{code: toActorCode, method: m.VMMessage().Method}
+			methodName, ok := methodNames[key]
+			if !ok {
+				methodName, _, err = util.MethodAndParamsForMessage(m.VMMessage(), toActorCode)
+				if err != nil {
+					log.Debugw("failed to determine method name", "message", m.Cid(), "error", err)
+				}
+				methodNames[key] = methodName
+			}
+
+			gas = append(gas, MessageGas{
+				ActorFamily:        builtin.ActorFamily(builtin.ActorNameByCode(toActorCode)),
+				Method:             m.VMMessage().Method,
+				MethodName:         methodName,
+				GasUsed:            r.GasUsed,
+				BaseFeeBurn:        gasOutputs.BaseFeeBurn,
+				OverEstimationBurn: gasOutputs.OverEstimationBurn,
+			})
+		}
+	}
+
+	return SummarizeMethodGas(int64(executed.Height()), executed.ParentState().String(), gas), report, nil
+}
+
+type methodKey struct {
+	code   cid.Cid
+	method abi.MethodNum
+}
+
+// MessageGas is the gas used and burnt by an executed message.
+type MessageGas struct {
+	ActorFamily        string
+	Method             abi.MethodNum
+	MethodName         string
+	GasUsed            int64
+	BaseFeeBurn        abi.TokenAmount
+	OverEstimationBurn abi.TokenAmount
+}
+
+// SummarizeMethodGas returns a row for each actor family and method called by the messages, ordered by actor family
+// and method. Percentiles use the nearest-rank method.
+func SummarizeMethodGas(height int64, stateRoot string, gas []MessageGas) messagemodel.MethodGasStatsList {
+	type group struct {
+		stats *messagemodel.MethodGasStats
+		used  []int64
+		base  abi.TokenAmount
+		over  abi.TokenAmount
+	}
+	type groupKey struct {
+		family string
+		method abi.MethodNum
+	}
+
+	groups := map[groupKey]*group{}
+	for _, g := range gas {
+		key := groupKey{family: g.ActorFamily, method: g.Method}
+		grp, ok := groups[key]
+		if !ok {
+			grp = &group{
+				stats: &messagemodel.MethodGasStats{
+					Height:      height,
+					StateRoot:   stateRoot,
+					ActorFamily: g.ActorFamily,
+					Method:      uint64(g.Method),
+				},
+				base: big.Zero(),
+				over: big.Zero(),
+			}
+			groups[key] = grp
+		}
+		// actors of a family may be of different versions, keep the first name that could be determined.
+		if grp.stats.MethodName == "" {
+			grp.stats.MethodName = g.MethodName
+		}
+		grp.used = append(grp.used, g.GasUsed)
+		grp.base = big.Add(grp.base, g.BaseFeeBurn)
+		grp.over = big.Add(grp.over, g.OverEstimationBurn)
+	}
+
+	out := make(messagemodel.MethodGasStatsList, 0, len(groups))
+	for _, grp := range groups {
+		sort.Slice(grp.used, func(i, j int) bool { return grp.used[i] < grp.used[j] })
+		grp.stats.MessageCount = int64(len(grp.used))
+		grp.stats.GasUsedP50 = percentile(grp.used, 50)
+		grp.stats.GasUsedP90 = percentile(grp.used, 90)
+		grp.stats.GasUsedP99 = percentile(grp.used, 99)
+		grp.stats.BaseFeeBurn = grp.base.String()
+		grp.stats.OverEstimationBurn = grp.over.String()
+		out = append(out, grp.stats)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ActorFamily != out[j].ActorFamily {
+			return out[i].ActorFamily < out[j].ActorFamily
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// percentile returns the p-th percentile of sorted using the nearest-rank method.
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	// the smallest rank such that at least p percent of the values are less than or equal to the value at that rank.
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,46 @@
+package methodgasstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	require.Equal(t, int64(5), percentile(sorted, 50))
+	require.Equal(t, int64(9), percentile(sorted, 90))
+	require.Equal(t, int64(10), percentile(sorted, 99))
+	require.Equal(t, int64(7), percentile([]int64{7}, 50))
+	require.Equal(t, int64(0), percentile(nil, 50))
+}
+
+func TestSummarizeMethodGas(t *testing.T) {
+	gas := []MessageGas{
+		{ActorFamily: "storageminer", Method: 5, MethodName: "SubmitWindowedPoSt", GasUsed: 300, BaseFeeBurn: abi.NewTokenAmount(3), OverEstimationBurn: abi.NewTokenAmount(1)},
+		{ActorFamily: "account", Method: 0, MethodName: "Send", GasUsed: 10, BaseFeeBurn: abi.NewTokenAmount(1), OverEstimationBurn: abi.NewTokenAmount(0)},
+		{ActorFamily: "storageminer", Method: 5, MethodName: "", GasUsed: 100, BaseFeeBurn: abi.NewTokenAmount(1), OverEstimationBurn: abi.NewTokenAmount(2)},
+		{ActorFamily: "storageminer", Method: 5, MethodName: "SubmitWindowedPoSt", GasUsed: 200, BaseFeeBurn: abi.NewTokenAmount(2), OverEstimationBurn: abi.NewTokenAmount(0)},
+	}
+
+	stats := SummarizeMethodGas(10, "root", gas)
+	require.Len(t, stats, 2)
+
+	require.Equal(t, "account", stats[0].ActorFamily)
+	require.EqualValues(t, 1, stats[0].MessageCount)
+	require.EqualValues(t, 10, stats[0].GasUsedP99)
+
+	miner := stats[1]
+	require.Equal(t, int64(10), miner.Height)
+	require.Equal(t, "root", miner.StateRoot)
+	require.Equal(t, uint64(5), miner.Method)
+	require.Equal(t, "SubmitWindowedPoSt", miner.MethodName)
+	require.EqualValues(t, 3, miner.MessageCount)
+	require.EqualValues(t, 200, miner.GasUsedP50)
+	require.EqualValues(t, 300, miner.GasUsedP90)
+	require.EqualValues(t, 300, miner.GasUsedP99)
+	require.Equal(t, "6", miner.BaseFeeBurn)
+	require.Equal(t, "3", miner.OverEstimationBurn)
+}
@@ -0,0 +1,427 @@
+package minerpenalty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	miner16 "github.com/filecoin-project/go-state-types/builtin/v16/miner"
+	"github.com/filecoin-project/lily/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model"
+	minermodel "github.com/filecoin-project/lily/model/actors/miner"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/minerpenalty")
+
+// PenaltyProvingDeadline is the type of the amount burnt by a miner at the end of a proving deadline. The amount
+// combines expired precommit deposits, the daily fee of the deadline, fees for continued faults and the repayment of
+// earlier fee debt, and is split into those types using the state of the miner before it is persisted.
+const PenaltyProvingDeadline = "PROVING_DEADLINE"
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+// ProcessTipSets emits a row for each type of penalty paid by a miner in executed. Penalties are found in the
+// execution traces of the messages as value sent by a miner to the burnt funds actor, and are attributed using the
+// miner method that sent them. Penalties that a miner could not pay are found by comparing its fee debt in the parent
+// states of executed and current. Rows are keyed by the height and parent state root of executed.
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "miner_penalties"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	mex, err := t.node.MessageExecutions(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting messages executions for tipset: %w", err)
+		return nil, report, nil
+	}
+
+	miners := map[address.Address]bool{}
+	isMiner := func(addr address.Address) bool {
+		if is, ok := miners[addr]; ok {
+			return is
+		}
+		act, err := t.node.Actor(ctx, addr, executed.Key())
+		miners[addr] = err == nil && isMinerCode(act.Code)
+		return miners[addr]
+	}
+	penalties := MinerPenalties(mex, isMiner)
+
+	var errs []error
+	for addr, byType := range penalties {
+		if err := t.splitMinerPenalties(ctx, addr, byType, current, executed); err != nil {
+			errs = append(errs, fmt.Errorf("miner %s: %w", addr, err))
+		}
+	}
+
+	out := make(minermodel.MinerPenaltyList, 0, len(penalties))
+	for addr, byType := range penalties {
+		for typ, p := range byType {
+			if typ == PenaltyProvingDeadline || !p.Amount.GreaterThan(big.Zero()) {
+				continue
+			}
+			var msgCids []string
+			for _, c := range p.Messages {
+				msgCids = append(msgCids, c.String())
+			}
+			out = append(out, &minermodel.MinerPenalty{
+				Height:      int64(executed.Height()),
+				MinerID:     addr.String(),
+				StateRoot:   executed.ParentState().String(),
+				PenaltyType: typ,
+				Amount:      p.Amount.String(),
+				MessageCids: msgCids,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MinerID != out[j].MinerID {
+			return out[i].MinerID < out[j].MinerID
+		}
+		return out[i].PenaltyType < out[j].PenaltyType
+	})
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+	return out, report, nil
+}
+
+// splitMinerPenalties replaces the PenaltyProvingDeadline penalty of a miner with the penalties it is made of and adds
+// the fee debt incurred by the miner.
+func (t *Task) splitMinerPenalties(ctx context.Context, addr address.Address, penalties map[string]*Penalty, current, executed *types.TipSet) error {
+	prevActor, err := t.node.Actor(ctx, addr, executed.Key())
+	if err != nil {
+		return fmt.Errorf("loading previous miner actor: %w", err)
+	}
+	prev, err := t.node.MinerLoad(t.node.Store(), prevActor)
+	if err != nil {
+		return fmt.Errorf("loading previous miner state: %w", err)
+	}
+	curActor, err := t.node.Actor(ctx, addr, current.Key())
+	if err != nil {
+		return fmt.Errorf("loading current miner actor: %w", err)
+	}
+	cur, err := t.node.MinerLoad(t.node.Store(), curActor)
+	if err != nil {
+		return fmt.Errorf("loading current miner state: %w", err)
+	}
+
+	prevDebt, err := prev.FeeDebt()
+	if err != nil {
+		return fmt.Errorf("loading previous fee debt: %w", err)
+	}
+	curDebt, err := cur.FeeDebt()
+	if err != nil {
+		return fmt.Errorf("loading current fee debt: %w", err)
+	}
+
+	if deadline, ok := penalties[PenaltyProvingDeadline]; ok && deadline.Amount.GreaterThan(big.Zero()) {
+		// debt is repaid before the penalties of the deadline, less any repaid by earlier messages.
+		debt := prevDebt
+		if repaid, ok := penalties[minermodel.PenaltyFeeDebtRepayment]; ok {
+			debt = big.Max(big.Sub(debt, repaid.Amount), big.Zero())
+		}
+		expired, err := t.expiredPreCommitDeposits(ctx, addr, current, executed, prev, cur)
+		if err != nil {
+			return err
+		}
+		dailyFee, err := deadlineDailyFee(prev, executed.Height(), deadline.Cron)
+		if err != nil {
+			return err
+		}
+
+		remaining := deadline.Amount
+		for _, part := range []struct {
+			typ    string
+			amount abi.TokenAmount
+		}{
+			{typ: minermodel.PenaltyFeeDebtRepayment, amount: debt},
+			{typ: minermodel.PenaltyPreCommitExpiry, amount: expired},
+			{typ: minermodel.PenaltyDailyFee, amount: dailyFee},
+		} {
+			amount := big.Min(part.amount, remaining)
+			addPenalty(penalties, part.typ, amount, deadline.Messages...)
+			remaining = big.Sub(remaining, amount)
+		}
+		addPenalty(penalties, minermodel.PenaltyFaultFee, remaining, deadline.Messages...)
+	}
+
+	if incurred := big.Sub(curDebt, prevDebt); incurred.GreaterThan(big.Zero()) {
+		var msgs []cid.Cid
+		for _, p := range penalties {
+			msgs = tasks.MergeCids(msgs, p.Messages)
+		}
+		addPenalty(penalties, minermodel.PenaltyFeeDebtIncurred, incurred, msgs...)
+	}
+	delete(penalties, PenaltyProvingDeadline)
+	return nil
+}
+
+// expiredPreCommitDeposits returns the sum of the deposits of the precommits that were removed from the miner state
+// without their sector being proven.
+func (t *Task) expiredPreCommitDeposits(ctx context.Context, addr address.Address, current, executed *types.TipSet, prev, cur miner.State) (abi.TokenAmount, error) {
+	sectors, err := t.node.DiffSectors(ctx, addr, current, executed, prev, cur)
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("diffing sectors: %w", err)
+	}
+	proven := make(map[abi.SectorNumber]struct{}, len(sectors.Added))
+	for _, s := range sectors.Added {
+		proven[s.SectorNumber] = struct{}{}
+	}
+
+	deposits := big.Zero()
+	add := func(sector abi.SectorNumber, deposit abi.TokenAmount) {
+		if _, ok := proven[sector]; !ok {
+			deposits = big.Add(deposits, deposit)
+		}
+	}
+	if cur.ActorVersion() > actorstypes.Version8 {
+		changes, err := t.node.DiffPreCommits(ctx, addr, current, executed, prev, cur)
+		if err != nil {
+			return abi.TokenAmount{}, fmt.Errorf("diffing precommits: %w", err)
+		}
+		for _, rm := range changes.Removed {
+			add(rm.Info.SectorNumber, rm.PreCommitDeposit)
+		}
+		return deposits, nil
+	}
+	changes, err := t.node.DiffPreCommitsV8(ctx, addr, current, executed, prev, cur)
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("diffing precommits: %w", err)
+	}
+	for _, rm := range changes.Removed {
+		add(rm.Info.SectorNumber, rm.PreCommitDeposit)
+	}
+	return deposits, nil
+}
+
+// deadlineDailyFee returns the daily fee charged for the deadline ending at epoch, capped at the share of the
+// expected reward of the deadline's power set by the miner actor. It is zero before actors v16.
+func deadlineDailyFee(st miner.State, epoch abi.ChainEpoch, cron *miner16.DeferredCronEventParams) (abi.TokenAmount, error) {
+	info, err := st.DeadlineInfo(epoch)
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("getting deadline info: %w", err)
+	}
+	deadline, err := st.LoadDeadline(info.Index)
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("loading deadline: %w", err)
+	}
+	fee, err := deadline.DailyFee()
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("getting daily fee: %w", err)
+	}
+	if fee.IsZero() || cron == nil {
+		return fee, nil
+	}
+	livePower, err := deadline.LivePowerQA()
+	if err != nil {
+		return abi.TokenAmount{}, fmt.Errorf("getting live power: %w", err)
+	}
+	reward := miner16.ExpectedRewardForPower(cron.RewardSmoothed, cron.QualityAdjPowerSmoothed, livePower, builtin.EpochsInDay)
+	return big.Min(fee, big.Div(reward, big.NewInt(miner16.DailyFeeBlockRewardCapDenom))), nil
+}
+
+// A Penalty is an amount burnt by a miner.
+type Penalty struct {
+	Amount abi.TokenAmount
+	// Messages are the CIDs of the messages, including implicit messages, in whose execution the amount was burnt.
+	Messages []cid.Cid
+	// Cron holds the parameters of the cron event of a PenaltyProvingDeadline, if they could be decoded.
+	Cron *miner16.DeferredCronEventParams
+}
+
+// Penalties holds the penalties paid by each miner, by type of penalty.
+type Penalties map[address.Address]map[string]*Penalty
+
+// MinerPenalties returns the amounts burnt by miners in the execution of the messages, by type of penalty. A burn is a
+// transfer of value to the burnt funds actor by an actor for which isMiner returns true. Its type is determined by
+// the method of the miner that made it. Amounts burnt at the end of a proving deadline are returned as
+// PenaltyProvingDeadline, and every miner that reached the end of a proving deadline has a PenaltyProvingDeadline
+// penalty, which may be zero. Calls that failed are ignored, since their transfers were reverted.
+func MinerPenalties(mex []*lens.MessageExecution, isMiner func(address.Address) bool) Penalties {
+	out := Penalties{}
+	for _, m := range mex {
+		if m.Ret == nil {
+			continue
+		}
+		walkTrace(m.Ret.ExecutionTrace, m.Cid, isMiner, out)
+	}
+	return out
+}
+
+func walkTrace(trace types.ExecutionTrace, msg cid.Cid, isMiner func(address.Address) bool, out Penalties) {
+	if trace.MsgRct.ExitCode.IsError() {
+		return
+	}
+
+	receiver := trace.Msg.To
+	if trace.InvokedActor != nil {
+		if id, err := address.NewIDAddress(uint64(trace.InvokedActor.Id)); err == nil {
+			receiver = id
+		}
+	}
+
+	// only the power actor schedules cron events for miners.
+	var cron *miner16.DeferredCronEventParams
+	eventType := miner16.CronEventType(-1)
+	if trace.Msg.From == builtin.StoragePowerActorAddr && trace.Msg.Method == builtin.MethodsMiner.OnDeferredCronEvent {
+		eventType, cron = decodeCronEvent(trace.Msg.Params)
+	}
+
+	burnt := false
+	for _, sub := range trace.Subcalls {
+		if isBurn(sub) {
+			burnt = true
+			break
+		}
+	}
+
+	if eventType == miner16.CronEventProvingDeadline || (burnt && isMiner(receiver)) {
+		if eventType == miner16.CronEventProvingDeadline {
+			addPenalty(out.miner(receiver), PenaltyProvingDeadline, big.Zero(), msg)
+			out[receiver][PenaltyProvingDeadline].Cron = cron
+		}
+		first := true
+		for _, sub := range trace.Subcalls {
+			if !isBurn(sub) {
+				continue
+			}
+			addPenalty(out.miner(receiver), penaltyType(trace.Msg.Method, eventType, first), sub.Msg.Value, msg)
+			first = false
+		}
+	}
+
+	for _, sub := range trace.Subcalls {
+		walkTrace(sub, msg, isMiner, out)
+	}
+}
+
+func (p Penalties) miner(addr address.Address) map[string]*Penalty {
+	if _, ok := p[addr]; !ok {
+		p[addr] = map[string]*Penalty{}
+	}
+	return p[addr]
+}
+
+// addPenalty adds amount to the penalty of type typ, attributing it to msgs.
+func addPenalty(penalties map[string]*Penalty, typ string, amount abi.TokenAmount, msgs ...cid.Cid) {
+	p, ok := penalties[typ]
+	if !ok {
+		p = &Penalty{Amount: big.Zero()}
+		penalties[typ] = p
+	}
+	p.Amount = big.Add(p.Amount, amount)
+	if amount.GreaterThan(big.Zero()) || typ == PenaltyProvingDeadline {
+		p.Messages = tasks.MergeCids(p.Messages, msgs)
+	}
+}
+
+// isBurn returns true if the call transferred value to the burnt funds actor.
+func isBurn(trace types.ExecutionTrace) bool {
+	if trace.MsgRct.ExitCode.IsError() || trace.Msg.Value.Nil() || !trace.Msg.Value.GreaterThan(big.Zero()) {
+		return false
+	}
+	if trace.InvokedActor != nil {
+		id, err := address.NewIDAddress(uint64(trace.InvokedActor.Id))
+		return err == nil && id == builtin.BurntFundsActorAddr
+	}
+	return trace.Msg.To == builtin.BurntFundsActorAddr
+}
+
+// penaltyType returns the type of a penalty burnt by a miner in the given method. eventType is the type of the cron
+// event handled by OnDeferredCronEvent, and first is true for the first burn made by the call.
+func penaltyType(method abi.MethodNum, eventType miner16.CronEventType, first bool) string {
+	switch method {
+	case builtin.MethodsMiner.ReportConsensusFault:
+		return minermodel.PenaltyConsensusFault
+	case builtin.MethodsMiner.DisputeWindowedPoSt:
+		return minermodel.PenaltyPoStDispute
+	case builtin.MethodsMiner.TerminateSectors:
+		return minermodel.PenaltyTermination
+	case builtin.MethodsMiner.OnDeferredCronEvent:
+		switch eventType {
+		case miner16.CronEventProvingDeadline:
+			// early terminations may be processed after the penalties of the deadline are burnt.
+			if first {
+				return PenaltyProvingDeadline
+			}
+			return minermodel.PenaltyTermination
+		case miner16.CronEventProcessEarlyTerminations:
+			return minermodel.PenaltyTermination
+		}
+	case builtin.MethodsMiner.RepayDebt, builtin.MethodsMiner.RepayDebtExported,
+		builtin.MethodsMiner.WithdrawBalance, builtin.MethodsMiner.WithdrawBalanceExported,
+		builtin.MethodsMiner.DeclareFaultsRecovered, builtin.MethodsMiner.ApplyRewards:
+		return minermodel.PenaltyFeeDebtRepayment
+	case builtin.MethodsMiner.PreCommitSectorBatch, builtin.MethodsMiner.PreCommitSectorBatch2,
+		builtin.MethodsMiner.ProveCommitAggregate, builtin.MethodsMiner.ProveCommitSectors3,
+		builtin.MethodsMiner.ProveReplicaUpdates3, builtin.MethodsMiner.ProveCommitSectorsNI:
+		return minermodel.PenaltyNetworkFee
+	}
+	return minermodel.PenaltyOther
+}
+
+// decodeCronEvent returns the type of the cron event handled by a call to OnDeferredCronEvent, and its parameters if
+// they could be decoded. Actors v0 pass the event payload as the parameters.
+func decodeCronEvent(params []byte) (miner16.CronEventType, *miner16.DeferredCronEventParams) {
+	var p miner16.DeferredCronEventParams
+	payload := params
+	if err := p.UnmarshalCBOR(bytes.NewReader(params)); err == nil {
+		payload = p.EventPayload
+	}
+	var event miner16.CronEventPayload
+	if err := event.UnmarshalCBOR(bytes.NewReader(payload)); err != nil {
+		log.Debugw("failed to decode cron event", "error", err)
+		return -1, nil
+	}
+	if p.EventPayload == nil {
+		return event.EventType, nil
+	}
+	return event.EventType, &p
+}
+
+func isMinerCode(code cid.Cid) bool {
+	for _, c := range miner.AllCodes() {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
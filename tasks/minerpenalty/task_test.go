@@ -0,0 +1,171 @@
+package minerpenalty
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	miner16 "github.com/filecoin-project/go-state-types/builtin/v16/miner"
+	"github.com/filecoin-project/go-state-types/builtin/v16/util/smoothing"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/lily/lens"
+	minermodel "github.com/filecoin-project/lily/model/actors/miner"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+func mustAddr(t *testing.T, s string) address.Address {
+	addr, err := address.NewFromString(s)
+	require.NoError(t, err)
+	return addr
+}
+
+func mustCid(t *testing.T, s string) cid.Cid {
+	c, err := cid.Decode(s)
+	require.NoError(t, err)
+	return c
+}
+
+func cronParams(t *testing.T, eventType miner16.CronEventType) []byte {
+	var payload bytes.Buffer
+	require.NoError(t, (&miner16.CronEventPayload{EventType: eventType}).MarshalCBOR(&payload))
+	var params bytes.Buffer
+	require.NoError(t, (&miner16.DeferredCronEventParams{
+		EventPayload:            payload.Bytes(),
+		RewardSmoothed:          smoothing.NewEstimate(big.NewInt(10), big.Zero()),
+		QualityAdjPowerSmoothed: smoothing.NewEstimate(big.NewInt(20), big.Zero()),
+	}).MarshalCBOR(&params))
+	return params.Bytes()
+}
+
+func burn(from address.Address, amount int64) types.ExecutionTrace {
+	return types.ExecutionTrace{
+		Msg:          types.MessageTrace{From: from, To: builtin.BurntFundsActorAddr, Value: abi.NewTokenAmount(amount)},
+		InvokedActor: &types.ActorTrace{Id: 99},
+	}
+}
+
+func TestMinerPenalties(t *testing.T) {
+	faulty := mustAddr(t, "f01000")
+	deadline := mustAddr(t, "f01001")
+	idle := mustAddr(t, "f01002")
+	notMiner := mustAddr(t, "f01003")
+	reporter := mustAddr(t, "f01004")
+
+	report := mustCid(t, "bafy2bzacedgxvrqlydlawaufbg6vqqb47mfnjhomrq2vjucvdi6ew3wabwsy4")
+	failed := mustCid(t, "bafy2bzacebq7g5yokwvvnmx4hh2jmijxaxjbx6rlhkuzjvjssdxkqzgkuyb6u")
+	cron := mustCid(t, "bafy2bzacecxfmolh2ojedyqtbnpnkoy7fp3rtrrszv4myk3usngwcrroqu2ki")
+
+	mex := []*lens.MessageExecution{
+		{
+			// a consensus fault reported against a miner
+			Cid: report,
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Msg:          types.MessageTrace{From: reporter, To: faulty, Method: builtin.MethodsMiner.ReportConsensusFault, Value: big.Zero()},
+				InvokedActor: &types.ActorTrace{Id: 1000},
+				Subcalls:     []types.ExecutionTrace{burn(faulty, 7)},
+			}},
+		},
+		{
+			// a failed termination, its burn was reverted
+			Cid: failed,
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Msg:          types.MessageTrace{From: reporter, To: faulty, Method: builtin.MethodsMiner.TerminateSectors, Value: big.Zero()},
+				MsgRct:       types.ReturnTrace{ExitCode: exitcode.ErrIllegalArgument},
+				InvokedActor: &types.ActorTrace{Id: 1000},
+				Subcalls:     []types.ExecutionTrace{burn(faulty, 11)},
+			}},
+		},
+		{
+			// cron ending the proving deadlines of two miners, one of which processes early terminations
+			Cid:      cron,
+			Implicit: true,
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Msg:          types.MessageTrace{From: builtin.SystemActorAddr, To: builtin.CronActorAddr, Method: 2, Value: big.Zero()},
+				InvokedActor: &types.ActorTrace{Id: 3},
+				Subcalls: []types.ExecutionTrace{
+					{
+						Msg:          types.MessageTrace{From: builtin.CronActorAddr, To: builtin.StoragePowerActorAddr, Method: 5, Value: big.Zero()},
+						InvokedActor: &types.ActorTrace{Id: 4},
+						Subcalls: []types.ExecutionTrace{
+							{
+								Msg: types.MessageTrace{
+									From: builtin.StoragePowerActorAddr, To: deadline, Method: builtin.MethodsMiner.OnDeferredCronEvent,
+									Value: big.Zero(), Params: cronParams(t, miner16.CronEventProvingDeadline),
+								},
+								InvokedActor: &types.ActorTrace{Id: 1001},
+								Subcalls:     []types.ExecutionTrace{burn(deadline, 3), burn(deadline, 4)},
+							},
+							{
+								Msg: types.MessageTrace{
+									From: builtin.StoragePowerActorAddr, To: idle, Method: builtin.MethodsMiner.OnDeferredCronEvent,
+									Value: big.Zero(), Params: cronParams(t, miner16.CronEventProvingDeadline),
+								},
+								InvokedActor: &types.ActorTrace{Id: 1002},
+							},
+							{
+								// not a miner, despite the method number
+								Msg:          types.MessageTrace{From: reporter, To: notMiner, Method: builtin.MethodsMiner.TerminateSectors, Value: big.Zero()},
+								InvokedActor: &types.ActorTrace{Id: 1003},
+								Subcalls:     []types.ExecutionTrace{burn(notMiner, 5)},
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	isMiner := func(addr address.Address) bool { return addr != notMiner }
+	penalties := MinerPenalties(mex, isMiner)
+	require.Len(t, penalties, 3)
+
+	require.Len(t, penalties[faulty], 1)
+	require.Equal(t, abi.NewTokenAmount(7), penalties[faulty][minermodel.PenaltyConsensusFault].Amount)
+	require.Equal(t, []cid.Cid{report}, penalties[faulty][minermodel.PenaltyConsensusFault].Messages)
+
+	require.Len(t, penalties[deadline], 2)
+	require.Equal(t, abi.NewTokenAmount(3), penalties[deadline][PenaltyProvingDeadline].Amount)
+	require.Equal(t, []cid.Cid{cron}, penalties[deadline][PenaltyProvingDeadline].Messages)
+	require.NotNil(t, penalties[deadline][PenaltyProvingDeadline].Cron)
+	require.Equal(t, smoothing.NewEstimate(big.NewInt(10), big.Zero()), penalties[deadline][PenaltyProvingDeadline].Cron.RewardSmoothed)
+	require.Equal(t, abi.NewTokenAmount(4), penalties[deadline][minermodel.PenaltyTermination].Amount)
+
+	// miners that reached the end of a proving deadline are returned even if they burnt nothing, so their fee debt
+	// can be checked.
+	require.Len(t, penalties[idle], 1)
+	require.True(t, penalties[idle][PenaltyProvingDeadline].Amount.IsZero())
+}
+
+func TestPenaltyType(t *testing.T) {
+	testCases := []struct {
+		name      string
+		method    abi.MethodNum
+		eventType miner16.CronEventType
+		first     bool
+		expected  string
+	}{
+		{name: "dispute", method: builtin.MethodsMiner.DisputeWindowedPoSt, first: true, expected: minermodel.PenaltyPoStDispute},
+		{name: "terminate", method: builtin.MethodsMiner.TerminateSectors, first: true, expected: minermodel.PenaltyTermination},
+		{name: "deadline", method: builtin.MethodsMiner.OnDeferredCronEvent, eventType: miner16.CronEventProvingDeadline, first: true, expected: PenaltyProvingDeadline},
+		{name: "deadline termination", method: builtin.MethodsMiner.OnDeferredCronEvent, eventType: miner16.CronEventProvingDeadline, expected: minermodel.PenaltyTermination},
+		{name: "early termination", method: builtin.MethodsMiner.OnDeferredCronEvent, eventType: miner16.CronEventProcessEarlyTerminations, first: true, expected: minermodel.PenaltyTermination},
+		{name: "unknown cron event", method: builtin.MethodsMiner.OnDeferredCronEvent, eventType: -1, first: true, expected: minermodel.PenaltyOther},
+		{name: "repay debt", method: builtin.MethodsMiner.RepayDebtExported, first: true, expected: minermodel.PenaltyFeeDebtRepayment},
+		{name: "withdraw", method: builtin.MethodsMiner.WithdrawBalance, first: true, expected: minermodel.PenaltyFeeDebtRepayment},
+		{name: "aggregate", method: builtin.MethodsMiner.ProveCommitAggregate, first: true, expected: minermodel.PenaltyNetworkFee},
+		{name: "other", method: builtin.MethodsMiner.ChangeWorkerAddress, first: true, expected: minermodel.PenaltyOther},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, penaltyType(tc.method, tc.eventType, tc.first))
+		})
+	}
+}